@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.27.1
 // 	protoc        v3.19.1
 // source: greeter.proto
 
@@ -11,6 +11,8 @@ import (
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -22,6 +24,272 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 只有单个状态、按id倒序且没有其他过滤条件的查询使用缓存，其他组合直接查询数据库
+type GreeterSortField int32
+
+const (
+	GreeterSortField_GREETER_SORT_FIELD_ID          GreeterSortField = 0
+	GreeterSortField_GREETER_SORT_FIELD_VIEW_NUM    GreeterSortField = 1
+	GreeterSortField_GREETER_SORT_FIELD_CREATE_TIME GreeterSortField = 2
+)
+
+// Enum value maps for GreeterSortField.
+var (
+	GreeterSortField_name = map[int32]string{
+		0: "GREETER_SORT_FIELD_ID",
+		1: "GREETER_SORT_FIELD_VIEW_NUM",
+		2: "GREETER_SORT_FIELD_CREATE_TIME",
+	}
+	GreeterSortField_value = map[string]int32{
+		"GREETER_SORT_FIELD_ID":          0,
+		"GREETER_SORT_FIELD_VIEW_NUM":    1,
+		"GREETER_SORT_FIELD_CREATE_TIME": 2,
+	}
+)
+
+func (x GreeterSortField) Enum() *GreeterSortField {
+	p := new(GreeterSortField)
+	*p = x
+	return p
+}
+
+func (x GreeterSortField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GreeterSortField) Descriptor() protoreflect.EnumDescriptor {
+	return file_greeter_proto_enumTypes[0].Descriptor()
+}
+
+func (GreeterSortField) Type() protoreflect.EnumType {
+	return &file_greeter_proto_enumTypes[0]
+}
+
+func (x GreeterSortField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GreeterSortField.Descriptor instead.
+func (GreeterSortField) EnumDescriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{0}
+}
+
+type SortOrder int32
+
+const (
+	SortOrder_SORT_ORDER_DESC SortOrder = 0
+	SortOrder_SORT_ORDER_ASC  SortOrder = 1
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_DESC",
+		1: "SORT_ORDER_ASC",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_DESC": 0,
+		"SORT_ORDER_ASC":  1,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_greeter_proto_enumTypes[1].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_greeter_proto_enumTypes[1]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{1}
+}
+
+// GreeterAction Greeter的变更类型
+type GreeterAction int32
+
+const (
+	GreeterAction_GREETER_ACTION_UNSPECIFIED GreeterAction = 0
+	GreeterAction_GREETER_ACTION_CREATE      GreeterAction = 1
+	GreeterAction_GREETER_ACTION_UPDATE      GreeterAction = 2
+	GreeterAction_GREETER_ACTION_STATUS      GreeterAction = 3
+	GreeterAction_GREETER_ACTION_COUNT       GreeterAction = 4
+	GreeterAction_GREETER_ACTION_DELETE      GreeterAction = 5
+	GreeterAction_GREETER_ACTION_RESTORE     GreeterAction = 6
+)
+
+// Enum value maps for GreeterAction.
+var (
+	GreeterAction_name = map[int32]string{
+		0: "GREETER_ACTION_UNSPECIFIED",
+		1: "GREETER_ACTION_CREATE",
+		2: "GREETER_ACTION_UPDATE",
+		3: "GREETER_ACTION_STATUS",
+		4: "GREETER_ACTION_COUNT",
+		5: "GREETER_ACTION_DELETE",
+		6: "GREETER_ACTION_RESTORE",
+	}
+	GreeterAction_value = map[string]int32{
+		"GREETER_ACTION_UNSPECIFIED": 0,
+		"GREETER_ACTION_CREATE":      1,
+		"GREETER_ACTION_UPDATE":      2,
+		"GREETER_ACTION_STATUS":      3,
+		"GREETER_ACTION_COUNT":       4,
+		"GREETER_ACTION_DELETE":      5,
+		"GREETER_ACTION_RESTORE":     6,
+	}
+)
+
+func (x GreeterAction) Enum() *GreeterAction {
+	p := new(GreeterAction)
+	*p = x
+	return p
+}
+
+func (x GreeterAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GreeterAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_greeter_proto_enumTypes[2].Descriptor()
+}
+
+func (GreeterAction) Type() protoreflect.EnumType {
+	return &file_greeter_proto_enumTypes[2]
+}
+
+func (x GreeterAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GreeterAction.Descriptor instead.
+func (GreeterAction) EnumDescriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{2}
+}
+
+// GreeterStatus Greeter的状态，状态之间的变更由领域层的状态机约束
+type GreeterStatus int32
+
+const (
+	// 待审核
+	GreeterStatus_GREETER_STATUS_PENDING GreeterStatus = 0
+	// 正常
+	GreeterStatus_GREETER_STATUS_ACTIVE GreeterStatus = 1
+	// 已禁用
+	GreeterStatus_GREETER_STATUS_DISABLED GreeterStatus = 2
+	// 已删除，只能重新进入待审核
+	GreeterStatus_GREETER_STATUS_DELETED GreeterStatus = 3
+)
+
+// Enum value maps for GreeterStatus.
+var (
+	GreeterStatus_name = map[int32]string{
+		0: "GREETER_STATUS_PENDING",
+		1: "GREETER_STATUS_ACTIVE",
+		2: "GREETER_STATUS_DISABLED",
+		3: "GREETER_STATUS_DELETED",
+	}
+	GreeterStatus_value = map[string]int32{
+		"GREETER_STATUS_PENDING":  0,
+		"GREETER_STATUS_ACTIVE":   1,
+		"GREETER_STATUS_DISABLED": 2,
+		"GREETER_STATUS_DELETED":  3,
+	}
+)
+
+func (x GreeterStatus) Enum() *GreeterStatus {
+	p := new(GreeterStatus)
+	*p = x
+	return p
+}
+
+func (x GreeterStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GreeterStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_greeter_proto_enumTypes[3].Descriptor()
+}
+
+func (GreeterStatus) Type() protoreflect.EnumType {
+	return &file_greeter_proto_enumTypes[3]
+}
+
+func (x GreeterStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GreeterStatus.Descriptor instead.
+func (GreeterStatus) EnumDescriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{3}
+}
+
+type GreeterFailureReason int32
+
+const (
+	GreeterFailureReason_GREETER_FAILURE_REASON_UNSPECIFIED GreeterFailureReason = 0
+	// 请求超时前未读取完成
+	GreeterFailureReason_GREETER_FAILURE_REASON_TIMEOUT GreeterFailureReason = 1
+	// 读取缓存或数据库失败
+	GreeterFailureReason_GREETER_FAILURE_REASON_ERROR GreeterFailureReason = 2
+)
+
+// Enum value maps for GreeterFailureReason.
+var (
+	GreeterFailureReason_name = map[int32]string{
+		0: "GREETER_FAILURE_REASON_UNSPECIFIED",
+		1: "GREETER_FAILURE_REASON_TIMEOUT",
+		2: "GREETER_FAILURE_REASON_ERROR",
+	}
+	GreeterFailureReason_value = map[string]int32{
+		"GREETER_FAILURE_REASON_UNSPECIFIED": 0,
+		"GREETER_FAILURE_REASON_TIMEOUT":     1,
+		"GREETER_FAILURE_REASON_ERROR":       2,
+	}
+)
+
+func (x GreeterFailureReason) Enum() *GreeterFailureReason {
+	p := new(GreeterFailureReason)
+	*p = x
+	return p
+}
+
+func (x GreeterFailureReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GreeterFailureReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_greeter_proto_enumTypes[4].Descriptor()
+}
+
+func (GreeterFailureReason) Type() protoreflect.EnumType {
+	return &file_greeter_proto_enumTypes[4]
+}
+
+func (x GreeterFailureReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GreeterFailureReason.Descriptor instead.
+func (GreeterFailureReason) EnumDescriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{4}
+}
+
 type CreateGreeterRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -263,23 +531,159 @@ func (x *GetGreeterByIdResponse) GetData() *Greeter {
 	return nil
 }
 
+type BatchGetGreetersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// @inject_tag: validate:"required,max=100,dive,gt=0"
+	Ids []int32 `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids" validate:"required,max=100,dive,gt=0"`
+}
+
+func (x *BatchGetGreetersRequest) Reset() {
+	*x = BatchGetGreetersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetGreetersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetGreetersRequest) ProtoMessage() {}
+
+func (x *BatchGetGreetersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetGreetersRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetGreetersRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchGetGreetersRequest) GetIds() []int32 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type BatchGetGreetersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 按请求ids的顺序返回，不存在的id不占位
+	Data     []*Greeter `protobuf:"bytes,1,rep,name=data,proto3" json:"data"`
+	NotFound []int32    `protobuf:"varint,2,rep,packed,name=not_found,json=notFound,proto3" json:"not_found"`
+}
+
+func (x *BatchGetGreetersResponse) Reset() {
+	*x = BatchGetGreetersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchGetGreetersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetGreetersResponse) ProtoMessage() {}
+
+func (x *BatchGetGreetersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetGreetersResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetGreetersResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchGetGreetersResponse) GetData() []*Greeter {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *BatchGetGreetersResponse) GetNotFound() []int32 {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
 type GetGreeterListRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	// @inject_tag: validate:"gte=0,lte=3"
-	Status int32 `protobuf:"varint,1,opt,name=status,proto3" json:"status" validate:"gte=0,lte=3"`
+	Status GreeterStatus `protobuf:"varint,1,opt,name=status,proto3,enum=greeter.GreeterStatus" json:"status" validate:"gte=0,lte=3"`
+	// 已废弃，使用page_token，page_token不为空时忽略
+	//
+	// Deprecated: Do not use.
 	Lastid int32 `protobuf:"varint,2,opt,name=lastid,proto3" json:"lastid"`
 	// @inject_tag: validate:"gte=5,lte=20"
 	Pagesize int32 `protobuf:"varint,3,opt,name=pagesize,proto3" json:"pagesize" validate:"gte=5,lte=20"`
-	Page     int32 `protobuf:"varint,4,opt,name=page,proto3" json:"page"`
+	// 已废弃，使用page_token，page_token不为空时忽略
+	//
+	// Deprecated: Do not use.
+	Page int32 `protobuf:"varint,4,opt,name=page,proto3" json:"page"`
+	// 管理员选项，为true时包含已软删除的Greeter，直接查询数据库
+	IncludeDeleted bool `protobuf:"varint,5,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted"`
+	// 上一页返回的next_page_token，其中记录了查询条件，全部的过滤和排序条件需要与之一致
+	PageToken string `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token"`
+	// 按多个状态过滤，不为空时忽略status
+	// @inject_tag: validate:"max=4,dive,gte=0,lte=3"
+	Statuses []GreeterStatus `protobuf:"varint,7,rep,packed,name=statuses,proto3,enum=greeter.GreeterStatus" json:"statuses" validate:"max=4,dive,gte=0,lte=3"`
+	// 名称前缀
+	// @inject_tag: validate:"max=50"
+	NamePrefix string `protobuf:"bytes,8,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix" validate:"max=50"`
+	// 名称包含的字符串
+	// @inject_tag: validate:"max=50"
+	NameContains string `protobuf:"bytes,9,opt,name=name_contains,json=nameContains,proto3" json:"name_contains" validate:"max=50"`
+	// 创建时间（毫秒）范围[create_time_from, create_time_to)，为0时不限制
+	// @inject_tag: validate:"gte=0"
+	CreateTimeFrom int64 `protobuf:"varint,10,opt,name=create_time_from,json=createTimeFrom,proto3" json:"create_time_from" validate:"gte=0"`
+	// @inject_tag: validate:"omitempty,gtfield=CreateTimeFrom"
+	CreateTimeTo int64 `protobuf:"varint,11,opt,name=create_time_to,json=createTimeTo,proto3" json:"create_time_to" validate:"omitempty,gtfield=CreateTimeFrom"`
+	// 最小浏览数
+	// @inject_tag: validate:"gte=0"
+	MinViewNum int32 `protobuf:"varint,12,opt,name=min_view_num,json=minViewNum,proto3" json:"min_view_num" validate:"gte=0"`
+	// 排序字段，相同时按id排序，lastid只在按id排序时有效
+	// @inject_tag: validate:"gte=0,lte=2"
+	SortBy GreeterSortField `protobuf:"varint,13,opt,name=sort_by,json=sortBy,proto3,enum=greeter.GreeterSortField" json:"sort_by" validate:"gte=0,lte=2"`
+	// @inject_tag: validate:"gte=0,lte=1"
+	Order SortOrder `protobuf:"varint,14,opt,name=order,proto3,enum=greeter.SortOrder" json:"order" validate:"gte=0,lte=1"`
 }
 
 func (x *GetGreeterListRequest) Reset() {
 	*x = GetGreeterListRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[4]
+		mi := &file_greeter_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -292,7 +696,7 @@ func (x *GetGreeterListRequest) String() string {
 func (*GetGreeterListRequest) ProtoMessage() {}
 
 func (x *GetGreeterListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[4]
+	mi := &file_greeter_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -305,16 +709,17 @@ func (x *GetGreeterListRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGreeterListRequest.ProtoReflect.Descriptor instead.
 func (*GetGreeterListRequest) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{4}
+	return file_greeter_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetGreeterListRequest) GetStatus() int32 {
+func (x *GetGreeterListRequest) GetStatus() GreeterStatus {
 	if x != nil {
 		return x.Status
 	}
-	return 0
+	return GreeterStatus_GREETER_STATUS_PENDING
 }
 
+// Deprecated: Do not use.
 func (x *GetGreeterListRequest) GetLastid() int32 {
 	if x != nil {
 		return x.Lastid
@@ -329,6 +734,7 @@ func (x *GetGreeterListRequest) GetPagesize() int32 {
 	return 0
 }
 
+// Deprecated: Do not use.
 func (x *GetGreeterListRequest) GetPage() int32 {
 	if x != nil {
 		return x.Page
@@ -336,6 +742,76 @@ func (x *GetGreeterListRequest) GetPage() int32 {
 	return 0
 }
 
+func (x *GetGreeterListRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+func (x *GetGreeterListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetGreeterListRequest) GetStatuses() []GreeterStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *GetGreeterListRequest) GetNamePrefix() string {
+	if x != nil {
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *GetGreeterListRequest) GetNameContains() string {
+	if x != nil {
+		return x.NameContains
+	}
+	return ""
+}
+
+func (x *GetGreeterListRequest) GetCreateTimeFrom() int64 {
+	if x != nil {
+		return x.CreateTimeFrom
+	}
+	return 0
+}
+
+func (x *GetGreeterListRequest) GetCreateTimeTo() int64 {
+	if x != nil {
+		return x.CreateTimeTo
+	}
+	return 0
+}
+
+func (x *GetGreeterListRequest) GetMinViewNum() int32 {
+	if x != nil {
+		return x.MinViewNum
+	}
+	return 0
+}
+
+func (x *GetGreeterListRequest) GetSortBy() GreeterSortField {
+	if x != nil {
+		return x.SortBy
+	}
+	return GreeterSortField_GREETER_SORT_FIELD_ID
+}
+
+func (x *GetGreeterListRequest) GetOrder() SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return SortOrder_SORT_ORDER_DESC
+}
+
 // @inject_response GetGreeterListResponse *GreeterList data
 type GetGreeterListResponse struct {
 	state         protoimpl.MessageState
@@ -366,7 +842,7 @@ func (x *GetGreeterListResponse) SetBody(code status.Code, data *GreeterList) {
 func (x *GetGreeterListResponse) Reset() {
 	*x = GetGreeterListResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[5]
+		mi := &file_greeter_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -379,7 +855,7 @@ func (x *GetGreeterListResponse) String() string {
 func (*GetGreeterListResponse) ProtoMessage() {}
 
 func (x *GetGreeterListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[5]
+	mi := &file_greeter_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -392,7 +868,7 @@ func (x *GetGreeterListResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetGreeterListResponse.ProtoReflect.Descriptor instead.
 func (*GetGreeterListResponse) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{5}
+	return file_greeter_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GetGreeterListResponse) GetCode() int32 {
@@ -416,32 +892,161 @@ func (x *GetGreeterListResponse) GetData() *GreeterList {
 	return nil
 }
 
-type UpdateGreeterStatusRequest struct {
+type UpdateGreeterRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id     int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
-	Status int32 `protobuf:"varint,2,opt,name=status,proto3" json:"status"`
+	// @inject_tag: validate:"gt=0"
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	// @inject_tag: validate:"required"
+	Greeter *Greeter `protobuf:"bytes,2,opt,name=greeter,proto3" json:"greeter" validate:"required"`
+	// 需要更新的Greeter字段，只允许name和status，view_num通过UpdateGreeterCount修改；通过gateway调用时未指定则取body中出现的字段
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=update_mask,json=updateMask,proto3" json:"update_mask"`
+	// 期望的版本号，与当前版本不一致时返回ABORTED，为0时不校验
+	// @inject_tag: validate:"gte=0"
+	Version int32 `protobuf:"varint,4,opt,name=version,proto3" json:"version" validate:"gte=0"`
 }
 
-func (x *UpdateGreeterStatusRequest) Reset() {
-	*x = UpdateGreeterStatusRequest{}
+func (x *UpdateGreeterRequest) Reset() {
+	*x = UpdateGreeterRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[6]
+		mi := &file_greeter_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *UpdateGreeterStatusRequest) String() string {
+func (x *UpdateGreeterRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateGreeterStatusRequest) ProtoMessage() {}
+func (*UpdateGreeterRequest) ProtoMessage() {}
 
-func (x *UpdateGreeterStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[6]
+func (x *UpdateGreeterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateGreeterRequest.ProtoReflect.Descriptor instead.
+func (*UpdateGreeterRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateGreeterRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateGreeterRequest) GetGreeter() *Greeter {
+	if x != nil {
+		return x.Greeter
+	}
+	return nil
+}
+
+func (x *UpdateGreeterRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateGreeterRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type UpdateGreeterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data *Greeter `protobuf:"bytes,1,opt,name=data,proto3" json:"data"`
+}
+
+func (x *UpdateGreeterResponse) Reset() {
+	*x = UpdateGreeterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateGreeterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateGreeterResponse) ProtoMessage() {}
+
+func (x *UpdateGreeterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateGreeterResponse.ProtoReflect.Descriptor instead.
+func (*UpdateGreeterResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateGreeterResponse) GetData() *Greeter {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type UpdateGreeterStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// @inject_tag: validate:"gt=0"
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	// 目标状态，只允许状态机中定义的状态变更
+	// @inject_tag: validate:"gte=0,lte=3"
+	Status GreeterStatus `protobuf:"varint,2,opt,name=status,proto3,enum=greeter.GreeterStatus" json:"status" validate:"gte=0,lte=3"`
+	// 期望的版本号，与当前版本不一致时返回ABORTED，为0时不校验
+	// @inject_tag: validate:"gte=0"
+	Version int32 `protobuf:"varint,3,opt,name=version,proto3" json:"version" validate:"gte=0"`
+}
+
+func (x *UpdateGreeterStatusRequest) Reset() {
+	*x = UpdateGreeterStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateGreeterStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateGreeterStatusRequest) ProtoMessage() {}
+
+func (x *UpdateGreeterStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -454,7 +1059,7 @@ func (x *UpdateGreeterStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateGreeterStatusRequest.ProtoReflect.Descriptor instead.
 func (*UpdateGreeterStatusRequest) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{6}
+	return file_greeter_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *UpdateGreeterStatusRequest) GetId() int32 {
@@ -464,10 +1069,17 @@ func (x *UpdateGreeterStatusRequest) GetId() int32 {
 	return 0
 }
 
-func (x *UpdateGreeterStatusRequest) GetStatus() int32 {
+func (x *UpdateGreeterStatusRequest) GetStatus() GreeterStatus {
 	if x != nil {
 		return x.Status
 	}
+	return GreeterStatus_GREETER_STATUS_PENDING
+}
+
+func (x *UpdateGreeterStatusRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
 	return 0
 }
 
@@ -494,7 +1106,7 @@ func (x *UpdateGreeterStatusResponse) SetCode(code status.Code, message string)
 func (x *UpdateGreeterStatusResponse) Reset() {
 	*x = UpdateGreeterStatusResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[7]
+		mi := &file_greeter_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -507,7 +1119,7 @@ func (x *UpdateGreeterStatusResponse) String() string {
 func (*UpdateGreeterStatusResponse) ProtoMessage() {}
 
 func (x *UpdateGreeterStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[7]
+	mi := &file_greeter_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -520,7 +1132,7 @@ func (x *UpdateGreeterStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateGreeterStatusResponse.ProtoReflect.Descriptor instead.
 func (*UpdateGreeterStatusResponse) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{7}
+	return file_greeter_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *UpdateGreeterStatusResponse) GetCode() int32 {
@@ -542,15 +1154,20 @@ type UpdateGreeterCountRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id     int32  `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
-	Num    int32  `protobuf:"varint,2,opt,name=num,proto3" json:"num"`
+	// @inject_tag: validate:"gt=0"
+	Id  int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	Num int32 `protobuf:"varint,2,opt,name=num,proto3" json:"num"`
+	// 计数字段，目前只允许view_num，其他字段返回INVALID_ARGUMENT
 	Column string `protobuf:"bytes,3,opt,name=column,proto3" json:"column"`
+	// 期望的版本号，与当前版本不一致时返回ABORTED；为0时不校验，增量先累加在Redis中，定期批量写入数据库
+	// @inject_tag: validate:"gte=0"
+	Version int32 `protobuf:"varint,4,opt,name=version,proto3" json:"version" validate:"gte=0"`
 }
 
 func (x *UpdateGreeterCountRequest) Reset() {
 	*x = UpdateGreeterCountRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[8]
+		mi := &file_greeter_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -563,7 +1180,7 @@ func (x *UpdateGreeterCountRequest) String() string {
 func (*UpdateGreeterCountRequest) ProtoMessage() {}
 
 func (x *UpdateGreeterCountRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[8]
+	mi := &file_greeter_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -576,7 +1193,7 @@ func (x *UpdateGreeterCountRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateGreeterCountRequest.ProtoReflect.Descriptor instead.
 func (*UpdateGreeterCountRequest) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{8}
+	return file_greeter_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *UpdateGreeterCountRequest) GetId() int32 {
@@ -600,6 +1217,13 @@ func (x *UpdateGreeterCountRequest) GetColumn() string {
 	return ""
 }
 
+func (x *UpdateGreeterCountRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 // @inject_response UpdateGreeterCountResponse
 type UpdateGreeterCountResponse struct {
 	state         protoimpl.MessageState
@@ -623,7 +1247,7 @@ func (x *UpdateGreeterCountResponse) SetCode(code status.Code, message string) {
 func (x *UpdateGreeterCountResponse) Reset() {
 	*x = UpdateGreeterCountResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[9]
+		mi := &file_greeter_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -636,7 +1260,7 @@ func (x *UpdateGreeterCountResponse) String() string {
 func (*UpdateGreeterCountResponse) ProtoMessage() {}
 
 func (x *UpdateGreeterCountResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[9]
+	mi := &file_greeter_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -649,7 +1273,7 @@ func (x *UpdateGreeterCountResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateGreeterCountResponse.ProtoReflect.Descriptor instead.
 func (*UpdateGreeterCountResponse) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{9}
+	return file_greeter_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *UpdateGreeterCountResponse) GetCode() int32 {
@@ -671,13 +1295,17 @@ type DeleteGreeterByIdRequest struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
+	// @inject_tag: validate:"gt=0"
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	// 期望的版本号，与当前版本不一致时返回ABORTED，为0时不校验
+	// @inject_tag: validate:"gte=0"
+	Version int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version" validate:"gte=0"`
 }
 
 func (x *DeleteGreeterByIdRequest) Reset() {
 	*x = DeleteGreeterByIdRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[10]
+		mi := &file_greeter_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -690,7 +1318,7 @@ func (x *DeleteGreeterByIdRequest) String() string {
 func (*DeleteGreeterByIdRequest) ProtoMessage() {}
 
 func (x *DeleteGreeterByIdRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[10]
+	mi := &file_greeter_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -703,7 +1331,7 @@ func (x *DeleteGreeterByIdRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteGreeterByIdRequest.ProtoReflect.Descriptor instead.
 func (*DeleteGreeterByIdRequest) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{10}
+	return file_greeter_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *DeleteGreeterByIdRequest) GetId() int32 {
@@ -713,6 +1341,13 @@ func (x *DeleteGreeterByIdRequest) GetId() int32 {
 	return 0
 }
 
+func (x *DeleteGreeterByIdRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
 // @inject_response DeleteGreeterByIdResponse
 type DeleteGreeterByIdResponse struct {
 	state         protoimpl.MessageState
@@ -736,7 +1371,7 @@ func (x *DeleteGreeterByIdResponse) SetCode(code status.Code, message string) {
 func (x *DeleteGreeterByIdResponse) Reset() {
 	*x = DeleteGreeterByIdResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[11]
+		mi := &file_greeter_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -749,7 +1384,7 @@ func (x *DeleteGreeterByIdResponse) String() string {
 func (*DeleteGreeterByIdResponse) ProtoMessage() {}
 
 func (x *DeleteGreeterByIdResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[11]
+	mi := &file_greeter_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -762,7 +1397,7 @@ func (x *DeleteGreeterByIdResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeleteGreeterByIdResponse.ProtoReflect.Descriptor instead.
 func (*DeleteGreeterByIdResponse) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{11}
+	return file_greeter_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *DeleteGreeterByIdResponse) GetCode() int32 {
@@ -779,39 +1414,35 @@ func (x *DeleteGreeterByIdResponse) GetMessage() string {
 	return ""
 }
 
-type Greeter struct {
+type RestoreGreeterRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
-	// @inject_tag: validate:"required,email"
-	Name    string `protobuf:"bytes,2,opt,name=name,proto3" json:"name" validate:"required,email"`
-	ViewNum int32  `protobuf:"varint,3,opt,name=view_num,json=viewNum,proto3" json:"view_num"`
-	// @inject_tag: validate:"gte=0,lte=3"
-	Status         int32  `protobuf:"varint,4,opt,name=status,proto3" json:"status" validate:"gte=0,lte=3"`
-	CreateTime     int64  `protobuf:"varint,5,opt,name=create_time,json=createTime,proto3" json:"create_time"`
-	CreateDatetime string `protobuf:"bytes,6,opt,name=create_datetime,json=createDatetime,proto3" json:"create_datetime"`
-	UpdateDatetime string `protobuf:"bytes,7,opt,name=update_datetime,json=updateDatetime,proto3" json:"update_datetime"`
+	// @inject_tag: validate:"gt=0"
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	// 期望的版本号，与当前版本不一致时返回ABORTED，为0时不校验
+	// @inject_tag: validate:"gte=0"
+	Version int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version" validate:"gte=0"`
 }
 
-func (x *Greeter) Reset() {
-	*x = Greeter{}
+func (x *RestoreGreeterRequest) Reset() {
+	*x = RestoreGreeterRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[12]
+		mi := &file_greeter_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Greeter) String() string {
+func (x *RestoreGreeterRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Greeter) ProtoMessage() {}
+func (*RestoreGreeterRequest) ProtoMessage() {}
 
-func (x *Greeter) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[12]
+func (x *RestoreGreeterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -822,88 +1453,99 @@ func (x *Greeter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Greeter.ProtoReflect.Descriptor instead.
-func (*Greeter) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use RestoreGreeterRequest.ProtoReflect.Descriptor instead.
+func (*RestoreGreeterRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *Greeter) GetId() int32 {
+func (x *RestoreGreeterRequest) GetId() int32 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-func (x *Greeter) GetName() string {
+func (x *RestoreGreeterRequest) GetVersion() int32 {
 	if x != nil {
-		return x.Name
+		return x.Version
 	}
-	return ""
+	return 0
 }
 
-func (x *Greeter) GetViewNum() int32 {
-	if x != nil {
-		return x.ViewNum
-	}
-	return 0
+type RestoreGreeterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data *Greeter `protobuf:"bytes,1,opt,name=data,proto3" json:"data"`
 }
 
-func (x *Greeter) GetStatus() int32 {
-	if x != nil {
-		return x.Status
+func (x *RestoreGreeterResponse) Reset() {
+	*x = RestoreGreeterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return 0
 }
 
-func (x *Greeter) GetCreateTime() int64 {
-	if x != nil {
-		return x.CreateTime
-	}
-	return 0
+func (x *RestoreGreeterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Greeter) GetCreateDatetime() string {
-	if x != nil {
-		return x.CreateDatetime
+func (*RestoreGreeterResponse) ProtoMessage() {}
+
+func (x *RestoreGreeterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-func (x *Greeter) GetUpdateDatetime() string {
+// Deprecated: Use RestoreGreeterResponse.ProtoReflect.Descriptor instead.
+func (*RestoreGreeterResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RestoreGreeterResponse) GetData() *Greeter {
 	if x != nil {
-		return x.UpdateDatetime
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type GreeterList struct {
+type PurgeDeletedGreetersRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Total     int32      `protobuf:"varint,1,opt,name=total,proto3" json:"total"`
-	TotalPage int32      `protobuf:"varint,2,opt,name=total_page,json=totalPage,proto3" json:"total_page"`
-	CurPage   int32      `protobuf:"varint,3,opt,name=cur_page,json=curPage,proto3" json:"cur_page"`
-	Datalist  []*Greeter `protobuf:"bytes,4,rep,name=datalist,proto3" json:"datalist"`
+	// 物理删除软删除时间早于当前时间减去older_than的Greeter
+	// @inject_tag: validate:"required"
+	OlderThan *durationpb.Duration `protobuf:"bytes,1,opt,name=older_than,json=olderThan,proto3" json:"older_than" validate:"required"`
 }
 
-func (x *GreeterList) Reset() {
-	*x = GreeterList{}
+func (x *PurgeDeletedGreetersRequest) Reset() {
+	*x = PurgeDeletedGreetersRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[13]
+		mi := &file_greeter_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GreeterList) String() string {
+func (x *PurgeDeletedGreetersRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GreeterList) ProtoMessage() {}
+func (*PurgeDeletedGreetersRequest) ProtoMessage() {}
 
-func (x *GreeterList) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[13]
+func (x *PurgeDeletedGreetersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -914,65 +1556,43 @@ func (x *GreeterList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GreeterList.ProtoReflect.Descriptor instead.
-func (*GreeterList) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{13}
-}
-
-func (x *GreeterList) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-func (x *GreeterList) GetTotalPage() int32 {
-	if x != nil {
-		return x.TotalPage
-	}
-	return 0
-}
-
-func (x *GreeterList) GetCurPage() int32 {
-	if x != nil {
-		return x.CurPage
-	}
-	return 0
+// Deprecated: Use PurgeDeletedGreetersRequest.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedGreetersRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *GreeterList) GetDatalist() []*Greeter {
+func (x *PurgeDeletedGreetersRequest) GetOlderThan() *durationpb.Duration {
 	if x != nil {
-		return x.Datalist
+		return x.OlderThan
 	}
 	return nil
 }
 
-type GetGreeterListByStreamRequest struct {
+type PurgeDeletedGreetersResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index"`
-	Id    int32 `protobuf:"varint,2,opt,name=id,proto3" json:"id"`
+	Purged int64 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged"`
 }
 
-func (x *GetGreeterListByStreamRequest) Reset() {
-	*x = GetGreeterListByStreamRequest{}
+func (x *PurgeDeletedGreetersResponse) Reset() {
+	*x = PurgeDeletedGreetersResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[14]
+		mi := &file_greeter_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetGreeterListByStreamRequest) String() string {
+func (x *PurgeDeletedGreetersResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetGreeterListByStreamRequest) ProtoMessage() {}
+func (*PurgeDeletedGreetersResponse) ProtoMessage() {}
 
-func (x *GetGreeterListByStreamRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[14]
+func (x *PurgeDeletedGreetersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -983,51 +1603,948 @@ func (x *GetGreeterListByStreamRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetGreeterListByStreamRequest.ProtoReflect.Descriptor instead.
-func (*GetGreeterListByStreamRequest) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use PurgeDeletedGreetersResponse.ProtoReflect.Descriptor instead.
+func (*PurgeDeletedGreetersResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PurgeDeletedGreetersResponse) GetPurged() int64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type ListGreeterHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// @inject_tag: validate:"gt=0"
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id" validate:"gt=0"`
+	// 上一页返回的next_page_token，为空时从最新的记录开始
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token"`
+	// 为0时默认20
+	// @inject_tag: validate:"gte=0,lte=100"
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size" validate:"gte=0,lte=100"`
+}
+
+func (x *ListGreeterHistoryRequest) Reset() {
+	*x = ListGreeterHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGreeterHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGreeterHistoryRequest) ProtoMessage() {}
+
+func (x *ListGreeterHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGreeterHistoryRequest.ProtoReflect.Descriptor instead.
+func (*ListGreeterHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListGreeterHistoryRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ListGreeterHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListGreeterHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListGreeterHistoryResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 按时间倒序排列
+	Data []*GreeterHistory `protobuf:"bytes,1,rep,name=data,proto3" json:"data"`
+	// 为空时表示没有更多记录
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token"`
+}
+
+func (x *ListGreeterHistoryResponse) Reset() {
+	*x = ListGreeterHistoryResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGreeterHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGreeterHistoryResponse) ProtoMessage() {}
+
+func (x *ListGreeterHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGreeterHistoryResponse.ProtoReflect.Descriptor instead.
+func (*ListGreeterHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListGreeterHistoryResponse) GetData() []*GreeterHistory {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ListGreeterHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type SearchGreetersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 名称中包含的字符串，不区分大小写
+	// @inject_tag: validate:"required,max=50"
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query" validate:"required,max=50"`
+	// 上一页返回的next_page_token，其中记录了query，需要与之一致
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token"`
+	// 为0时默认20
+	// @inject_tag: validate:"gte=0,lte=100"
+	PageSize int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size" validate:"gte=0,lte=100"`
+}
+
+func (x *SearchGreetersRequest) Reset() {
+	*x = SearchGreetersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchGreetersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchGreetersRequest) ProtoMessage() {}
+
+func (x *SearchGreetersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchGreetersRequest.ProtoReflect.Descriptor instead.
+func (*SearchGreetersRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SearchGreetersRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchGreetersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchGreetersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type SearchGreetersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 按相关度倒序排列，相关度相同时按id倒序
+	Data  []*GreeterSearchResult `protobuf:"bytes,1,rep,name=data,proto3" json:"data"`
+	Total int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total"`
+	// 为空时表示没有更多结果
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token"`
+}
+
+func (x *SearchGreetersResponse) Reset() {
+	*x = SearchGreetersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchGreetersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchGreetersResponse) ProtoMessage() {}
+
+func (x *SearchGreetersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchGreetersResponse.ProtoReflect.Descriptor instead.
+func (*SearchGreetersResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SearchGreetersResponse) GetData() []*GreeterSearchResult {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SearchGreetersResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *SearchGreetersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// GreeterSearchResult 搜索命中的Greeter
+type GreeterSearchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Greeter *Greeter `protobuf:"bytes,1,opt,name=greeter,proto3" json:"greeter"`
+	// 名称与query完全相同为3，以query开头为2，包含query为1
+	Score float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score"`
+	// 名称中与query匹配的部分用<em></em>标记，其余部分已做HTML转义
+	Highlight string `protobuf:"bytes,3,opt,name=highlight,proto3" json:"highlight"`
+}
+
+func (x *GreeterSearchResult) Reset() {
+	*x = GreeterSearchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterSearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterSearchResult) ProtoMessage() {}
+
+func (x *GreeterSearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterSearchResult.ProtoReflect.Descriptor instead.
+func (*GreeterSearchResult) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GreeterSearchResult) GetGreeter() *Greeter {
+	if x != nil {
+		return x.Greeter
+	}
+	return nil
+}
+
+func (x *GreeterSearchResult) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *GreeterSearchResult) GetHighlight() string {
+	if x != nil {
+		return x.Highlight
+	}
+	return ""
+}
+
+// GreeterHistory Greeter的一次变更记录
+type GreeterHistory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id        int64         `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
+	GreeterId int32         `protobuf:"varint,2,opt,name=greeter_id,json=greeterId,proto3" json:"greeter_id"`
+	Action    GreeterAction `protobuf:"varint,3,opt,name=action,proto3,enum=greeter.GreeterAction" json:"action"`
+	// 操作人，取自gRPC metadata中的x-actor
+	Actor string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor"`
+	// 请求id，取自gRPC metadata中的x-request-id
+	RequestId string `protobuf:"bytes,5,opt,name=request_id,json=requestId,proto3" json:"request_id"`
+	// 变更前后的Greeter，创建时before为空，删除时after为空
+	Before         *Greeter `protobuf:"bytes,6,opt,name=before,proto3" json:"before"`
+	After          *Greeter `protobuf:"bytes,7,opt,name=after,proto3" json:"after"`
+	CreateTime     int64    `protobuf:"varint,8,opt,name=create_time,json=createTime,proto3" json:"create_time"`
+	CreateDatetime string   `protobuf:"bytes,9,opt,name=create_datetime,json=createDatetime,proto3" json:"create_datetime"`
+}
+
+func (x *GreeterHistory) Reset() {
+	*x = GreeterHistory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterHistory) ProtoMessage() {}
+
+func (x *GreeterHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterHistory.ProtoReflect.Descriptor instead.
+func (*GreeterHistory) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GreeterHistory) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GreeterHistory) GetGreeterId() int32 {
+	if x != nil {
+		return x.GreeterId
+	}
+	return 0
+}
+
+func (x *GreeterHistory) GetAction() GreeterAction {
+	if x != nil {
+		return x.Action
+	}
+	return GreeterAction_GREETER_ACTION_UNSPECIFIED
+}
+
+func (x *GreeterHistory) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *GreeterHistory) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *GreeterHistory) GetBefore() *Greeter {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *GreeterHistory) GetAfter() *Greeter {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *GreeterHistory) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *GreeterHistory) GetCreateDatetime() string {
+	if x != nil {
+		return x.CreateDatetime
+	}
+	return ""
+}
+
+type Greeter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int32 `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
+	// @inject_tag: validate:"required,email"
+	Name    string `protobuf:"bytes,2,opt,name=name,proto3" json:"name" validate:"required,email"`
+	ViewNum int32  `protobuf:"varint,3,opt,name=view_num,json=viewNum,proto3" json:"view_num"`
+	// @inject_tag: validate:"gte=0,lte=3"
+	Status         GreeterStatus `protobuf:"varint,4,opt,name=status,proto3,enum=greeter.GreeterStatus" json:"status" validate:"gte=0,lte=3"`
+	CreateTime     int64         `protobuf:"varint,5,opt,name=create_time,json=createTime,proto3" json:"create_time"`
+	CreateDatetime string        `protobuf:"bytes,6,opt,name=create_datetime,json=createDatetime,proto3" json:"create_datetime"`
+	UpdateDatetime string        `protobuf:"bytes,7,opt,name=update_datetime,json=updateDatetime,proto3" json:"update_datetime"`
+	// 每次写入加1，用于乐观锁
+	Version int32 `protobuf:"varint,8,opt,name=version,proto3" json:"version"`
+	// 软删除时间，未删除时为空
+	DeleteDatetime string `protobuf:"bytes,9,opt,name=delete_datetime,json=deleteDatetime,proto3" json:"delete_datetime"`
+	// 最近一次状态变更的操作人和时间
+	StatusOperator string `protobuf:"bytes,10,opt,name=status_operator,json=statusOperator,proto3" json:"status_operator"`
+	StatusDatetime string `protobuf:"bytes,11,opt,name=status_datetime,json=statusDatetime,proto3" json:"status_datetime"`
+}
+
+func (x *Greeter) Reset() {
+	*x = Greeter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Greeter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Greeter) ProtoMessage() {}
+
+func (x *Greeter) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Greeter.ProtoReflect.Descriptor instead.
+func (*Greeter) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Greeter) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Greeter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Greeter) GetViewNum() int32 {
+	if x != nil {
+		return x.ViewNum
+	}
+	return 0
+}
+
+func (x *Greeter) GetStatus() GreeterStatus {
+	if x != nil {
+		return x.Status
+	}
+	return GreeterStatus_GREETER_STATUS_PENDING
+}
+
+func (x *Greeter) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *Greeter) GetCreateDatetime() string {
+	if x != nil {
+		return x.CreateDatetime
+	}
+	return ""
+}
+
+func (x *Greeter) GetUpdateDatetime() string {
+	if x != nil {
+		return x.UpdateDatetime
+	}
+	return ""
+}
+
+func (x *Greeter) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Greeter) GetDeleteDatetime() string {
+	if x != nil {
+		return x.DeleteDatetime
+	}
+	return ""
+}
+
+func (x *Greeter) GetStatusOperator() string {
+	if x != nil {
+		return x.StatusOperator
+	}
+	return ""
+}
+
+func (x *Greeter) GetStatusDatetime() string {
+	if x != nil {
+		return x.StatusDatetime
+	}
+	return ""
+}
+
+type GreeterList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Total     int32      `protobuf:"varint,1,opt,name=total,proto3" json:"total"`
+	TotalPage int32      `protobuf:"varint,2,opt,name=total_page,json=totalPage,proto3" json:"total_page"`
+	CurPage   int32      `protobuf:"varint,3,opt,name=cur_page,json=curPage,proto3" json:"cur_page"`
+	Datalist  []*Greeter `protobuf:"bytes,4,rep,name=datalist,proto3" json:"datalist"`
+	// 获取下一页时作为page_token传入，为空时表示没有更多数据
+	NextPageToken string `protobuf:"bytes,5,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token"`
+	// 部分Greeter读取失败时为true，datalist中缺少failed中的Greeter，可以稍后通过id重新获取
+	Incomplete bool              `protobuf:"varint,6,opt,name=incomplete,proto3" json:"incomplete"`
+	Failed     []*GreeterFailure `protobuf:"bytes,7,rep,name=failed,proto3" json:"failed"`
+}
+
+func (x *GreeterList) Reset() {
+	*x = GreeterList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterList) ProtoMessage() {}
+
+func (x *GreeterList) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterList.ProtoReflect.Descriptor instead.
+func (*GreeterList) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GreeterList) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *GreeterList) GetTotalPage() int32 {
+	if x != nil {
+		return x.TotalPage
+	}
+	return 0
+}
+
+func (x *GreeterList) GetCurPage() int32 {
+	if x != nil {
+		return x.CurPage
+	}
+	return 0
+}
+
+func (x *GreeterList) GetDatalist() []*Greeter {
+	if x != nil {
+		return x.Datalist
+	}
+	return nil
+}
+
+func (x *GreeterList) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *GreeterList) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+func (x *GreeterList) GetFailed() []*GreeterFailure {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+// GreeterFailure 列表中读取失败的Greeter
+type GreeterFailure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     int32                `protobuf:"varint,1,opt,name=id,proto3" json:"id"`
+	Reason GreeterFailureReason `protobuf:"varint,2,opt,name=reason,proto3,enum=greeter.GreeterFailureReason" json:"reason"`
+}
+
+func (x *GreeterFailure) Reset() {
+	*x = GreeterFailure{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterFailure) ProtoMessage() {}
+
+func (x *GreeterFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterFailure.ProtoReflect.Descriptor instead.
+func (*GreeterFailure) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GreeterFailure) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GreeterFailure) GetReason() GreeterFailureReason {
+	if x != nil {
+		return x.Reason
+	}
+	return GreeterFailureReason_GREETER_FAILURE_REASON_UNSPECIFIED
+}
+
+type GetGreeterListByStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index"`
+	Id    int32 `protobuf:"varint,2,opt,name=id,proto3" json:"id"`
+}
+
+func (x *GetGreeterListByStreamRequest) Reset() {
+	*x = GetGreeterListByStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetGreeterListByStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGreeterListByStreamRequest) ProtoMessage() {}
+
+func (x *GetGreeterListByStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGreeterListByStreamRequest.ProtoReflect.Descriptor instead.
+func (*GetGreeterListByStreamRequest) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetGreeterListByStreamRequest) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetGreeterListByStreamRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetGreeterListByStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index  int32    `protobuf:"varint,1,opt,name=index,proto3" json:"index"`
+	Result *Greeter `protobuf:"bytes,2,opt,name=result,proto3" json:"result"`
+}
+
+func (x *GetGreeterListByStreamResponse) Reset() {
+	*x = GetGreeterListByStreamResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetGreeterListByStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGreeterListByStreamResponse) ProtoMessage() {}
+
+func (x *GetGreeterListByStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGreeterListByStreamResponse.ProtoReflect.Descriptor instead.
+func (*GetGreeterListByStreamResponse) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetGreeterListByStreamResponse) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *GetGreeterListByStreamResponse) GetResult() *Greeter {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// 参数校验失败的字段，作为google.rpc.Status的details返回
+type FieldViolation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field   string `protobuf:"bytes,1,opt,name=field,proto3" json:"field"`
+	Rule    string `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule"`
+	Param   string `protobuf:"bytes,3,opt,name=param,proto3" json:"param"`
+	Message string `protobuf:"bytes,4,opt,name=message,proto3" json:"message"`
+}
+
+func (x *FieldViolation) Reset() {
+	*x = FieldViolation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *GetGreeterListByStreamRequest) GetIndex() int32 {
+func (x *FieldViolation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldViolation) ProtoMessage() {}
+
+func (x *FieldViolation) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldViolation.ProtoReflect.Descriptor instead.
+func (*FieldViolation) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *FieldViolation) GetField() string {
 	if x != nil {
-		return x.Index
+		return x.Field
 	}
-	return 0
+	return ""
 }
 
-func (x *GetGreeterListByStreamRequest) GetId() int32 {
+func (x *FieldViolation) GetRule() string {
 	if x != nil {
-		return x.Id
+		return x.Rule
 	}
-	return 0
+	return ""
 }
 
-type GetGreeterListByStreamResponse struct {
+func (x *FieldViolation) GetParam() string {
+	if x != nil {
+		return x.Param
+	}
+	return ""
+}
+
+func (x *FieldViolation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type FieldViolations struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Index  int32    `protobuf:"varint,1,opt,name=index,proto3" json:"index"`
-	Result *Greeter `protobuf:"bytes,2,opt,name=result,proto3" json:"result"`
+	Violations []*FieldViolation `protobuf:"bytes,1,rep,name=violations,proto3" json:"violations"`
 }
 
-func (x *GetGreeterListByStreamResponse) Reset() {
-	*x = GetGreeterListByStreamResponse{}
+func (x *FieldViolations) Reset() {
+	*x = FieldViolations{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_greeter_proto_msgTypes[15]
+		mi := &file_greeter_proto_msgTypes[32]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetGreeterListByStreamResponse) String() string {
+func (x *FieldViolations) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetGreeterListByStreamResponse) ProtoMessage() {}
+func (*FieldViolations) ProtoMessage() {}
 
-func (x *GetGreeterListByStreamResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_greeter_proto_msgTypes[15]
+func (x *FieldViolations) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_proto_msgTypes[32]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,21 +2555,14 @@ func (x *GetGreeterListByStreamResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetGreeterListByStreamResponse.ProtoReflect.Descriptor instead.
-func (*GetGreeterListByStreamResponse) Descriptor() ([]byte, []int) {
-	return file_greeter_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *GetGreeterListByStreamResponse) GetIndex() int32 {
-	if x != nil {
-		return x.Index
-	}
-	return 0
+// Deprecated: Use FieldViolations.ProtoReflect.Descriptor instead.
+func (*FieldViolations) Descriptor() ([]byte, []int) {
+	return file_greeter_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *GetGreeterListByStreamResponse) GetResult() *Greeter {
+func (x *FieldViolations) GetViolations() []*FieldViolation {
 	if x != nil {
-		return x.Result
+		return x.Violations
 	}
 	return nil
 }
@@ -1063,159 +2573,414 @@ var file_greeter_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
 	0x07, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
 	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3c, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x24,
-	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3c, 0x0a, 0x14, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x24, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x45, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x27, 0x0a,
+	0x15, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x6c, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x24,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67,
 	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x22, 0x45, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a,
+	0x64, 0x61, 0x74, 0x61, 0x22, 0x2b, 0x0a, 0x17, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x05, 0x52, 0x03, 0x69, 0x64,
+	0x73, 0x22, 0x5d, 0x0a, 0x18, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64,
+	0x22, 0xa9, 0x04, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x06, 0x6c, 0x61,
+	0x73, 0x74, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06,
+	0x6c, 0x61, 0x73, 0x74, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69,
+	0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x16, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x42, 0x02, 0x18, 0x01, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x69, 0x6e,
+	0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x32, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61, 0x6d,
+	0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x23, 0x0a, 0x0d, 0x6e, 0x61, 0x6d, 0x65, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x6e, 0x61, 0x6d, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x28, 0x0a, 0x10,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x66, 0x72, 0x6f, 0x6d,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x24, 0x0a, 0x0e, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x74, 0x6f, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x54, 0x6f, 0x12, 0x20, 0x0a, 0x0c,
+	0x6d, 0x69, 0x6e, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0a, 0x6d, 0x69, 0x6e, 0x56, 0x69, 0x65, 0x77, 0x4e, 0x75, 0x6d, 0x12, 0x32,
+	0x0a, 0x07, 0x73, 0x6f, 0x72, 0x74, 0x5f, 0x62, 0x79, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x19, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x53, 0x6f, 0x72, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x06, 0x73, 0x6f, 0x72, 0x74,
+	0x42, 0x79, 0x12, 0x28, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x53, 0x6f, 0x72, 0x74,
+	0x4f, 0x72, 0x64, 0x65, 0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x22, 0x70, 0x0a, 0x16,
+	0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xa9,
+	0x01, 0x0a, 0x14, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2a, 0x0a, 0x07, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x07, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4d, 0x61, 0x73, 0x6b, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x3d, 0x0a, 0x15, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x76, 0x0a, 0x1a, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x4b, 0x0a, 0x1b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x6f,
+	0x0a, 0x19, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6e,
+	0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6e, 0x75, 0x6d, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x4a, 0x0a, 0x1a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a,
 	0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64,
 	0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x27, 0x0a, 0x15, 0x47,
-	0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x02, 0x69, 0x64, 0x22, 0x6c, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x44, 0x0a, 0x18, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x22, 0x49, 0x0a, 0x19, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74,
 	0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12,
 	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f,
 	0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x24, 0x0a, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x04, 0x64, 0x61,
-	0x74, 0x61, 0x22, 0x77, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
-	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x69, 0x64, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x06, 0x6c, 0x61, 0x73, 0x74, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70,
-	0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
-	0x61, 0x67, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x22, 0x70, 0x0a, 0x16, 0x47,
-	0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x14, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65,
-	0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x44, 0x0a,
-	0x1a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69,
-	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x22, 0x4b, 0x0a, 0x1b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
-	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x22, 0x55, 0x0a, 0x19, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a,
-	0x03, 0x6e, 0x75, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x03, 0x6e, 0x75, 0x6d, 0x12,
-	0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x22, 0x4a, 0x0a, 0x1a, 0x55, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x22, 0x2a, 0x0a, 0x18, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22,
-	0x49, 0x0a, 0x19, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
-	0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04,
-	0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65,
-	0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xd3, 0x01, 0x0a, 0x07, 0x47,
-	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x76, 0x69,
-	0x65, 0x77, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x69,
-	0x65, 0x77, 0x4e, 0x75, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a,
-	0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x27,
-	0x0a, 0x0f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d,
-	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
-	0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x75, 0x70, 0x64, 0x61, 0x74,
-	0x65, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0e, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65,
-	0x22, 0x8b, 0x01, 0x0a, 0x0b, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f,
-	0x70, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61,
-	0x6c, 0x50, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x5f, 0x70, 0x61, 0x67,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x75, 0x72, 0x50, 0x61, 0x67, 0x65,
-	0x12, 0x2c, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x04, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x45,
-	0x0a, 0x1d, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74,
-	0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
-	0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x60, 0x0a, 0x1e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65,
-	0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x28, 0x0a,
-	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
-	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52,
-	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x32, 0xcb, 0x06, 0x0a, 0x0e, 0x47, 0x72, 0x65, 0x65,
-	0x74, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6d, 0x0a, 0x0d, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x2e, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65,
-	0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74,
-	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x17, 0x22, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f,
-	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x6f, 0x0a, 0x0e, 0x47, 0x65, 0x74,
-	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x12, 0x1e, 0x2e, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
-	0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
-	0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3,
-	0xe4, 0x93, 0x02, 0x16, 0x12, 0x14, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x2f, 0x6f, 0x6e, 0x65, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x74, 0x0a, 0x0e, 0x47, 0x65,
-	0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1e, 0x2e, 0x67,
-	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67,
-	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x21, 0x82,
-	0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x19, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74,
-	0x65, 0x72, 0x2f, 0x6c, 0x69, 0x73, 0x74, 0x2f, 0x7b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x7d,
-	0x12, 0x7f, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x23, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x67,
-	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65,
-	0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17, 0x22, 0x12, 0x2f, 0x76, 0x31, 0x2f,
-	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x3a, 0x01,
-	0x2a, 0x12, 0x7b, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74,
-	0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
-	0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43,
-	0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65,
-	0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x3a, 0x01, 0x2a, 0x12, 0x76,
-	0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42,
-	0x79, 0x49, 0x64, 0x12, 0x21, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x44, 0x65,
-	0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
-	0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79,
-	0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1a, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x14, 0x22, 0x0f, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f,
-	0x64, 0x65, 0x6c, 0x3a, 0x01, 0x2a, 0x12, 0x6d, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x41, 0x0a, 0x15,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x3e, 0x0a, 0x16, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22,
+	0x57, 0x0a, 0x1b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x38,
+	0x0a, 0x0a, 0x6f, 0x6c, 0x64, 0x65, 0x72, 0x5f, 0x74, 0x68, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x6f,
+	0x6c, 0x64, 0x65, 0x72, 0x54, 0x68, 0x61, 0x6e, 0x22, 0x36, 0x0a, 0x1c, 0x50, 0x75, 0x72, 0x67,
+	0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x75, 0x72, 0x67,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x75, 0x72, 0x67, 0x65, 0x64,
+	0x22, 0x67, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a,
+	0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09,
+	0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x71, 0x0a, 0x1a, 0x4c, 0x69, 0x73,
+	0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67,
+	0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e,
+	0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x69, 0x0a, 0x15,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61,
+	0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70,
+	0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x88, 0x01, 0x0a, 0x16, 0x53, 0x65, 0x61, 0x72,
+	0x63, 0x68, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65,
+	0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x22, 0x75, 0x0a, 0x13, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x2a, 0x0a, 0x07, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x07, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x68,
+	0x69, 0x67, 0x68, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x68, 0x69, 0x67, 0x68, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x22, 0xc0, 0x02, 0x0a, 0x0e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x09, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x2e, 0x0a, 0x06, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x61,
+	0x63, 0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x74, 0x6f,
+	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64,
+	0x12, 0x28, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x26, 0x0a, 0x05, 0x61, 0x66,
+	0x74, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x05, 0x61, 0x66, 0x74,
+	0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x54,
+	0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x61,
+	0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x80, 0x03, 0x0a,
+	0x07, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x19, 0x0a, 0x08,
+	0x76, 0x69, 0x65, 0x77, 0x5f, 0x6e, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x76, 0x69, 0x65, 0x77, 0x4e, 0x75, 0x6d, 0x12, 0x2e, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x27, 0x0a, 0x0f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x64, 0x61, 0x74, 0x65,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x64,
+	0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x64,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x27, 0x0a,
+	0x0f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x5f, 0x64, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0e, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x44, 0x61, 0x74, 0x65, 0x74, 0x69, 0x6d, 0x65, 0x22,
+	0x84, 0x02, 0x0a, 0x0b, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x70,
+	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x50, 0x61, 0x67, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x75, 0x72, 0x5f, 0x70, 0x61, 0x67, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x63, 0x75, 0x72, 0x50, 0x61, 0x67, 0x65, 0x12,
+	0x2c, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x26, 0x0a,
+	0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x6e, 0x63, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x2f, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18,
+	0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x52, 0x06,
+	0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x22, 0x57, 0x0a, 0x0e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x12, 0x35, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72,
+	0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22,
+	0x45, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73,
+	0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x69, 0x64, 0x22, 0x60, 0x0a, 0x1e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
 	0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
-	0x12, 0x26, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74,
-	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73,
-	0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x64, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6d, 0x69, 0x6e, 0x64, 0x2d, 0x6c, 0x61, 0x62, 0x2f, 0x67, 0x72,
-	0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b,
-	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0xca, 0x02, 0x0d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c,
-	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0xe2, 0x02, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c,
-	0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x28,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x6a, 0x0a, 0x0e, 0x46, 0x69, 0x65, 0x6c,
+	0x64, 0x56, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69,
+	0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x72, 0x75, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0x4a, 0x0a, 0x0f, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x69, 0x6f,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x37, 0x0a, 0x0a, 0x76, 0x69, 0x6f, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x56, 0x69, 0x6f, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x76, 0x69, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2a, 0x72, 0x0a, 0x10, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x6f, 0x72, 0x74, 0x46,
+	0x69, 0x65, 0x6c, 0x64, 0x12, 0x19, 0x0a, 0x15, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f,
+	0x53, 0x4f, 0x52, 0x54, 0x5f, 0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x49, 0x44, 0x10, 0x00, 0x12,
+	0x1f, 0x0a, 0x1b, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x4f, 0x52, 0x54, 0x5f,
+	0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x56, 0x49, 0x45, 0x57, 0x5f, 0x4e, 0x55, 0x4d, 0x10, 0x01,
+	0x12, 0x22, 0x0a, 0x1e, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x4f, 0x52, 0x54,
+	0x5f, 0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x54, 0x49,
+	0x4d, 0x45, 0x10, 0x02, 0x2a, 0x34, 0x0a, 0x09, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x4f, 0x52, 0x44, 0x45, 0x52, 0x5f,
+	0x44, 0x45, 0x53, 0x43, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x4f,
+	0x52, 0x44, 0x45, 0x52, 0x5f, 0x41, 0x53, 0x43, 0x10, 0x01, 0x2a, 0xd1, 0x01, 0x0a, 0x0d, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x1a,
+	0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15,
+	0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43,
+	0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x47, 0x52, 0x45, 0x45, 0x54,
+	0x45, 0x52, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45,
+	0x10, 0x02, 0x12, 0x19, 0x0a, 0x15, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x41, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x10, 0x03, 0x12, 0x18, 0x0a,
+	0x14, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x43, 0x4f, 0x55, 0x4e, 0x54, 0x10, 0x04, 0x12, 0x19, 0x0a, 0x15, 0x47, 0x52, 0x45, 0x45, 0x54,
+	0x45, 0x52, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45,
+	0x10, 0x05, 0x12, 0x1a, 0x0a, 0x16, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x41, 0x43,
+	0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x45, 0x53, 0x54, 0x4f, 0x52, 0x45, 0x10, 0x06, 0x2a, 0x7f,
+	0x0a, 0x0d, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x1a, 0x0a, 0x16, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x47,
+	0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x41, 0x43,
+	0x54, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45,
+	0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x49, 0x53, 0x41, 0x42, 0x4c, 0x45,
+	0x44, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x44, 0x10, 0x03, 0x2a,
+	0x84, 0x01, 0x0a, 0x14, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x46, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x26, 0x0a, 0x22, 0x47, 0x52, 0x45, 0x45,
+	0x54, 0x45, 0x52, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x55, 0x52, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x53,
+	0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x22, 0x0a, 0x1e, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f, 0x46, 0x41, 0x49, 0x4c,
+	0x55, 0x52, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f,
+	0x55, 0x54, 0x10, 0x01, 0x12, 0x20, 0x0a, 0x1c, 0x47, 0x52, 0x45, 0x45, 0x54, 0x45, 0x52, 0x5f,
+	0x46, 0x41, 0x49, 0x4c, 0x55, 0x52, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x45,
+	0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x32, 0x99, 0x0c, 0x0a, 0x0e, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x6d, 0x0a, 0x0d, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x17, 0x22, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x3a, 0x01, 0x2a, 0x12, 0x6f, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x12, 0x1e, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42,
+	0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x42,
+	0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4,
+	0x93, 0x02, 0x16, 0x12, 0x14, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x2f, 0x6f, 0x6e, 0x65, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x12, 0x72, 0x0a, 0x10, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x20, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47, 0x65, 0x74,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x21, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x47,
+	0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x19, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x13, 0x12, 0x11, 0x2f, 0x76, 0x31, 0x2f,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x62, 0x61, 0x74, 0x63, 0x68, 0x12, 0x74, 0x0a,
+	0x0e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x1e, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1f, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x12, 0x19, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x6c, 0x69, 0x73, 0x74, 0x2f, 0x7b, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x7d, 0x12, 0x71, 0x0a, 0x0d, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x21, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b, 0x32, 0x10, 0x2f, 0x76, 0x31,
+	0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x3a, 0x07, 0x67,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x7f, 0x0a, 0x13, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x23, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x24, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1d, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x17,
+	0x22, 0x12, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x3a, 0x01, 0x2a, 0x12, 0x7b, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x22, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x23, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11,
+	0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x3a, 0x01, 0x2a, 0x12, 0x76, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x12, 0x21, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x67,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x42, 0x79, 0x49, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x1a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x14, 0x22, 0x0f, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x64, 0x65, 0x6c, 0x3a, 0x01, 0x2a, 0x12, 0x71, 0x0a, 0x0e,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x12, 0x1e,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x22, 0x13, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2f, 0x72, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x3a, 0x01, 0x2a, 0x12,
+	0x81, 0x01, 0x0a, 0x14, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64,
+	0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x24, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f,
+	0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x70, 0x75, 0x72, 0x67, 0x65,
+	0x3a, 0x01, 0x2a, 0x12, 0x7f, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x22, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x20, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1a, 0x12, 0x18, 0x2f, 0x76, 0x31, 0x2f,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x7b, 0x69, 0x64, 0x7d, 0x2f, 0x68, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x12, 0x6d, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x1e, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1a, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x14, 0x12,
+	0x12, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x73, 0x65, 0x61,
+	0x72, 0x63, 0x68, 0x12, 0x6d, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x26, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e,
+	0x47, 0x65, 0x74, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x42, 0x79,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01,
+	0x30, 0x01, 0x42, 0x64, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x69, 0x6d, 0x69, 0x6e, 0x64, 0x2d, 0x6c, 0x61, 0x62, 0x2f, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x2f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x67,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0xca, 0x02, 0x0d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0xe2, 0x02, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c, 0x47, 0x50, 0x42,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1230,50 +2995,108 @@ func file_greeter_proto_rawDescGZIP() []byte {
 	return file_greeter_proto_rawDescData
 }
 
-var file_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_greeter_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_greeter_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
 var file_greeter_proto_goTypes = []interface{}{
-	(*CreateGreeterRequest)(nil),           // 0: greeter.CreateGreeterRequest
-	(*CreateGreeterResponse)(nil),          // 1: greeter.CreateGreeterResponse
-	(*GetGreeterByIdRequest)(nil),          // 2: greeter.GetGreeterByIdRequest
-	(*GetGreeterByIdResponse)(nil),         // 3: greeter.GetGreeterByIdResponse
-	(*GetGreeterListRequest)(nil),          // 4: greeter.GetGreeterListRequest
-	(*GetGreeterListResponse)(nil),         // 5: greeter.GetGreeterListResponse
-	(*UpdateGreeterStatusRequest)(nil),     // 6: greeter.UpdateGreeterStatusRequest
-	(*UpdateGreeterStatusResponse)(nil),    // 7: greeter.UpdateGreeterStatusResponse
-	(*UpdateGreeterCountRequest)(nil),      // 8: greeter.UpdateGreeterCountRequest
-	(*UpdateGreeterCountResponse)(nil),     // 9: greeter.UpdateGreeterCountResponse
-	(*DeleteGreeterByIdRequest)(nil),       // 10: greeter.DeleteGreeterByIdRequest
-	(*DeleteGreeterByIdResponse)(nil),      // 11: greeter.DeleteGreeterByIdResponse
-	(*Greeter)(nil),                        // 12: greeter.Greeter
-	(*GreeterList)(nil),                    // 13: greeter.GreeterList
-	(*GetGreeterListByStreamRequest)(nil),  // 14: greeter.GetGreeterListByStreamRequest
-	(*GetGreeterListByStreamResponse)(nil), // 15: greeter.GetGreeterListByStreamResponse
+	(GreeterSortField)(0),                  // 0: greeter.GreeterSortField
+	(SortOrder)(0),                         // 1: greeter.SortOrder
+	(GreeterAction)(0),                     // 2: greeter.GreeterAction
+	(GreeterStatus)(0),                     // 3: greeter.GreeterStatus
+	(GreeterFailureReason)(0),              // 4: greeter.GreeterFailureReason
+	(*CreateGreeterRequest)(nil),           // 5: greeter.CreateGreeterRequest
+	(*CreateGreeterResponse)(nil),          // 6: greeter.CreateGreeterResponse
+	(*GetGreeterByIdRequest)(nil),          // 7: greeter.GetGreeterByIdRequest
+	(*GetGreeterByIdResponse)(nil),         // 8: greeter.GetGreeterByIdResponse
+	(*BatchGetGreetersRequest)(nil),        // 9: greeter.BatchGetGreetersRequest
+	(*BatchGetGreetersResponse)(nil),       // 10: greeter.BatchGetGreetersResponse
+	(*GetGreeterListRequest)(nil),          // 11: greeter.GetGreeterListRequest
+	(*GetGreeterListResponse)(nil),         // 12: greeter.GetGreeterListResponse
+	(*UpdateGreeterRequest)(nil),           // 13: greeter.UpdateGreeterRequest
+	(*UpdateGreeterResponse)(nil),          // 14: greeter.UpdateGreeterResponse
+	(*UpdateGreeterStatusRequest)(nil),     // 15: greeter.UpdateGreeterStatusRequest
+	(*UpdateGreeterStatusResponse)(nil),    // 16: greeter.UpdateGreeterStatusResponse
+	(*UpdateGreeterCountRequest)(nil),      // 17: greeter.UpdateGreeterCountRequest
+	(*UpdateGreeterCountResponse)(nil),     // 18: greeter.UpdateGreeterCountResponse
+	(*DeleteGreeterByIdRequest)(nil),       // 19: greeter.DeleteGreeterByIdRequest
+	(*DeleteGreeterByIdResponse)(nil),      // 20: greeter.DeleteGreeterByIdResponse
+	(*RestoreGreeterRequest)(nil),          // 21: greeter.RestoreGreeterRequest
+	(*RestoreGreeterResponse)(nil),         // 22: greeter.RestoreGreeterResponse
+	(*PurgeDeletedGreetersRequest)(nil),    // 23: greeter.PurgeDeletedGreetersRequest
+	(*PurgeDeletedGreetersResponse)(nil),   // 24: greeter.PurgeDeletedGreetersResponse
+	(*ListGreeterHistoryRequest)(nil),      // 25: greeter.ListGreeterHistoryRequest
+	(*ListGreeterHistoryResponse)(nil),     // 26: greeter.ListGreeterHistoryResponse
+	(*SearchGreetersRequest)(nil),          // 27: greeter.SearchGreetersRequest
+	(*SearchGreetersResponse)(nil),         // 28: greeter.SearchGreetersResponse
+	(*GreeterSearchResult)(nil),            // 29: greeter.GreeterSearchResult
+	(*GreeterHistory)(nil),                 // 30: greeter.GreeterHistory
+	(*Greeter)(nil),                        // 31: greeter.Greeter
+	(*GreeterList)(nil),                    // 32: greeter.GreeterList
+	(*GreeterFailure)(nil),                 // 33: greeter.GreeterFailure
+	(*GetGreeterListByStreamRequest)(nil),  // 34: greeter.GetGreeterListByStreamRequest
+	(*GetGreeterListByStreamResponse)(nil), // 35: greeter.GetGreeterListByStreamResponse
+	(*FieldViolation)(nil),                 // 36: greeter.FieldViolation
+	(*FieldViolations)(nil),                // 37: greeter.FieldViolations
+	(*fieldmaskpb.FieldMask)(nil),          // 38: google.protobuf.FieldMask
+	(*durationpb.Duration)(nil),            // 39: google.protobuf.Duration
 }
 var file_greeter_proto_depIdxs = []int32{
-	12, // 0: greeter.CreateGreeterRequest.data:type_name -> greeter.Greeter
-	12, // 1: greeter.GetGreeterByIdResponse.data:type_name -> greeter.Greeter
-	13, // 2: greeter.GetGreeterListResponse.data:type_name -> greeter.GreeterList
-	12, // 3: greeter.GreeterList.datalist:type_name -> greeter.Greeter
-	12, // 4: greeter.GetGreeterListByStreamResponse.result:type_name -> greeter.Greeter
-	0,  // 5: greeter.GreeterService.CreateGreeter:input_type -> greeter.CreateGreeterRequest
-	2,  // 6: greeter.GreeterService.GetGreeterById:input_type -> greeter.GetGreeterByIdRequest
-	4,  // 7: greeter.GreeterService.GetGreeterList:input_type -> greeter.GetGreeterListRequest
-	6,  // 8: greeter.GreeterService.UpdateGreeterStatus:input_type -> greeter.UpdateGreeterStatusRequest
-	8,  // 9: greeter.GreeterService.UpdateGreeterCount:input_type -> greeter.UpdateGreeterCountRequest
-	10, // 10: greeter.GreeterService.DeleteGreeterById:input_type -> greeter.DeleteGreeterByIdRequest
-	14, // 11: greeter.GreeterService.GetGreeterListByStream:input_type -> greeter.GetGreeterListByStreamRequest
-	1,  // 12: greeter.GreeterService.CreateGreeter:output_type -> greeter.CreateGreeterResponse
-	3,  // 13: greeter.GreeterService.GetGreeterById:output_type -> greeter.GetGreeterByIdResponse
-	5,  // 14: greeter.GreeterService.GetGreeterList:output_type -> greeter.GetGreeterListResponse
-	7,  // 15: greeter.GreeterService.UpdateGreeterStatus:output_type -> greeter.UpdateGreeterStatusResponse
-	9,  // 16: greeter.GreeterService.UpdateGreeterCount:output_type -> greeter.UpdateGreeterCountResponse
-	11, // 17: greeter.GreeterService.DeleteGreeterById:output_type -> greeter.DeleteGreeterByIdResponse
-	15, // 18: greeter.GreeterService.GetGreeterListByStream:output_type -> greeter.GetGreeterListByStreamResponse
-	12, // [12:19] is the sub-list for method output_type
-	5,  // [5:12] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+	31, // 0: greeter.CreateGreeterRequest.data:type_name -> greeter.Greeter
+	31, // 1: greeter.GetGreeterByIdResponse.data:type_name -> greeter.Greeter
+	31, // 2: greeter.BatchGetGreetersResponse.data:type_name -> greeter.Greeter
+	3,  // 3: greeter.GetGreeterListRequest.status:type_name -> greeter.GreeterStatus
+	3,  // 4: greeter.GetGreeterListRequest.statuses:type_name -> greeter.GreeterStatus
+	0,  // 5: greeter.GetGreeterListRequest.sort_by:type_name -> greeter.GreeterSortField
+	1,  // 6: greeter.GetGreeterListRequest.order:type_name -> greeter.SortOrder
+	32, // 7: greeter.GetGreeterListResponse.data:type_name -> greeter.GreeterList
+	31, // 8: greeter.UpdateGreeterRequest.greeter:type_name -> greeter.Greeter
+	38, // 9: greeter.UpdateGreeterRequest.update_mask:type_name -> google.protobuf.FieldMask
+	31, // 10: greeter.UpdateGreeterResponse.data:type_name -> greeter.Greeter
+	3,  // 11: greeter.UpdateGreeterStatusRequest.status:type_name -> greeter.GreeterStatus
+	31, // 12: greeter.RestoreGreeterResponse.data:type_name -> greeter.Greeter
+	39, // 13: greeter.PurgeDeletedGreetersRequest.older_than:type_name -> google.protobuf.Duration
+	30, // 14: greeter.ListGreeterHistoryResponse.data:type_name -> greeter.GreeterHistory
+	29, // 15: greeter.SearchGreetersResponse.data:type_name -> greeter.GreeterSearchResult
+	31, // 16: greeter.GreeterSearchResult.greeter:type_name -> greeter.Greeter
+	2,  // 17: greeter.GreeterHistory.action:type_name -> greeter.GreeterAction
+	31, // 18: greeter.GreeterHistory.before:type_name -> greeter.Greeter
+	31, // 19: greeter.GreeterHistory.after:type_name -> greeter.Greeter
+	3,  // 20: greeter.Greeter.status:type_name -> greeter.GreeterStatus
+	31, // 21: greeter.GreeterList.datalist:type_name -> greeter.Greeter
+	33, // 22: greeter.GreeterList.failed:type_name -> greeter.GreeterFailure
+	4,  // 23: greeter.GreeterFailure.reason:type_name -> greeter.GreeterFailureReason
+	31, // 24: greeter.GetGreeterListByStreamResponse.result:type_name -> greeter.Greeter
+	36, // 25: greeter.FieldViolations.violations:type_name -> greeter.FieldViolation
+	5,  // 26: greeter.GreeterService.CreateGreeter:input_type -> greeter.CreateGreeterRequest
+	7,  // 27: greeter.GreeterService.GetGreeterById:input_type -> greeter.GetGreeterByIdRequest
+	9,  // 28: greeter.GreeterService.BatchGetGreeters:input_type -> greeter.BatchGetGreetersRequest
+	11, // 29: greeter.GreeterService.GetGreeterList:input_type -> greeter.GetGreeterListRequest
+	13, // 30: greeter.GreeterService.UpdateGreeter:input_type -> greeter.UpdateGreeterRequest
+	15, // 31: greeter.GreeterService.UpdateGreeterStatus:input_type -> greeter.UpdateGreeterStatusRequest
+	17, // 32: greeter.GreeterService.UpdateGreeterCount:input_type -> greeter.UpdateGreeterCountRequest
+	19, // 33: greeter.GreeterService.DeleteGreeterById:input_type -> greeter.DeleteGreeterByIdRequest
+	21, // 34: greeter.GreeterService.RestoreGreeter:input_type -> greeter.RestoreGreeterRequest
+	23, // 35: greeter.GreeterService.PurgeDeletedGreeters:input_type -> greeter.PurgeDeletedGreetersRequest
+	25, // 36: greeter.GreeterService.ListGreeterHistory:input_type -> greeter.ListGreeterHistoryRequest
+	27, // 37: greeter.GreeterService.SearchGreeters:input_type -> greeter.SearchGreetersRequest
+	34, // 38: greeter.GreeterService.GetGreeterListByStream:input_type -> greeter.GetGreeterListByStreamRequest
+	6,  // 39: greeter.GreeterService.CreateGreeter:output_type -> greeter.CreateGreeterResponse
+	8,  // 40: greeter.GreeterService.GetGreeterById:output_type -> greeter.GetGreeterByIdResponse
+	10, // 41: greeter.GreeterService.BatchGetGreeters:output_type -> greeter.BatchGetGreetersResponse
+	12, // 42: greeter.GreeterService.GetGreeterList:output_type -> greeter.GetGreeterListResponse
+	14, // 43: greeter.GreeterService.UpdateGreeter:output_type -> greeter.UpdateGreeterResponse
+	16, // 44: greeter.GreeterService.UpdateGreeterStatus:output_type -> greeter.UpdateGreeterStatusResponse
+	18, // 45: greeter.GreeterService.UpdateGreeterCount:output_type -> greeter.UpdateGreeterCountResponse
+	20, // 46: greeter.GreeterService.DeleteGreeterById:output_type -> greeter.DeleteGreeterByIdResponse
+	22, // 47: greeter.GreeterService.RestoreGreeter:output_type -> greeter.RestoreGreeterResponse
+	24, // 48: greeter.GreeterService.PurgeDeletedGreeters:output_type -> greeter.PurgeDeletedGreetersResponse
+	26, // 49: greeter.GreeterService.ListGreeterHistory:output_type -> greeter.ListGreeterHistoryResponse
+	28, // 50: greeter.GreeterService.SearchGreeters:output_type -> greeter.SearchGreetersResponse
+	35, // 51: greeter.GreeterService.GetGreeterListByStream:output_type -> greeter.GetGreeterListByStreamResponse
+	39, // [39:52] is the sub-list for method output_type
+	26, // [26:39] is the sub-list for method input_type
+	26, // [26:26] is the sub-list for extension type_name
+	26, // [26:26] is the sub-list for extension extendee
+	0,  // [0:26] is the sub-list for field type_name
 }
 
 func init() { file_greeter_proto_init() }
@@ -1331,7 +3154,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetGreeterListRequest); i {
+			switch v := v.(*BatchGetGreetersRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1343,7 +3166,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetGreeterListResponse); i {
+			switch v := v.(*BatchGetGreetersResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1355,7 +3178,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateGreeterStatusRequest); i {
+			switch v := v.(*GetGreeterListRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1367,7 +3190,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateGreeterStatusResponse); i {
+			switch v := v.(*GetGreeterListResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1379,7 +3202,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateGreeterCountRequest); i {
+			switch v := v.(*UpdateGreeterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1391,7 +3214,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*UpdateGreeterCountResponse); i {
+			switch v := v.(*UpdateGreeterResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1403,7 +3226,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteGreeterByIdRequest); i {
+			switch v := v.(*UpdateGreeterStatusRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1415,7 +3238,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteGreeterByIdResponse); i {
+			switch v := v.(*UpdateGreeterStatusResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1427,7 +3250,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Greeter); i {
+			switch v := v.(*UpdateGreeterCountRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1439,7 +3262,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GreeterList); i {
+			switch v := v.(*UpdateGreeterCountResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1451,7 +3274,7 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetGreeterListByStreamRequest); i {
+			switch v := v.(*DeleteGreeterByIdRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1463,6 +3286,186 @@ func file_greeter_proto_init() {
 			}
 		}
 		file_greeter_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteGreeterByIdResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreGreeterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreGreeterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeDeletedGreetersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeDeletedGreetersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGreeterHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGreeterHistoryResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchGreetersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchGreetersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterSearchResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterHistory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Greeter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterFailure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetGreeterListByStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetGreeterListByStreamResponse); i {
 			case 0:
 				return &v.state
@@ -1474,19 +3477,44 @@ func file_greeter_proto_init() {
 				return nil
 			}
 		}
+		file_greeter_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldViolation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldViolations); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_greeter_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   16,
+			NumEnums:      5,
+			NumMessages:   33,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_greeter_proto_goTypes,
 		DependencyIndexes: file_greeter_proto_depIdxs,
+		EnumInfos:         file_greeter_proto_enumTypes,
 		MessageInfos:      file_greeter_proto_msgTypes,
 	}.Build()
 	File_greeter_proto = out.File
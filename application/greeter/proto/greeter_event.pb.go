@@ -0,0 +1,752 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.1
+// source: greeter_event.proto
+
+package greeter
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// GreeterEvent Greeter领域事件的信封，与Greeter的变更在同一个事务中写入发件箱，再由中继发布到kafka，
+// 消息的key为greeter_id，同一Greeter的事件按发生的顺序发布；发布失败重试时同一事件可能发布多次，消费方按event_id去重。
+// 每种事件发布到各自的主题，主题在kafka.business.topic中配置：
+//
+//	GreeterCreated         greeterCreate   默认greeter_create
+//	GreeterUpdated         greeterUpdate   默认greeter_update
+//	GreeterStatusChanged   greeterStatus   默认greeter_status
+//	GreeterCounterChanged  greeterCounter  默认greeter_counter
+//	GreeterDeleted         greeterDelete   默认greeter_delete
+type GreeterEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 事件的唯一id
+	EventId string `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id"`
+	// 事件结构的版本，字段有不兼容的变更时加1，消费方遇到不支持的版本时应拒绝处理
+	SchemaVersion int32                  `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at"`
+	// 操作人，取自gRPC metadata中的x-actor
+	Actor string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor"`
+	// 链路追踪的trace id，请求未开启链路追踪时为空
+	TraceId string `protobuf:"bytes,5,opt,name=trace_id,json=traceId,proto3" json:"trace_id"`
+	// 请求id，取自gRPC metadata中的x-request-id
+	RequestId string `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id"`
+	GreeterId int32  `protobuf:"varint,7,opt,name=greeter_id,json=greeterId,proto3" json:"greeter_id"`
+	// Types that are assignable to Payload:
+	//	*GreeterEvent_Created
+	//	*GreeterEvent_Updated
+	//	*GreeterEvent_StatusChanged
+	//	*GreeterEvent_CounterChanged
+	//	*GreeterEvent_Deleted
+	Payload isGreeterEvent_Payload `protobuf_oneof:"payload"`
+}
+
+func (x *GreeterEvent) Reset() {
+	*x = GreeterEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterEvent) ProtoMessage() {}
+
+func (x *GreeterEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterEvent.ProtoReflect.Descriptor instead.
+func (*GreeterEvent) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GreeterEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *GreeterEvent) GetSchemaVersion() int32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *GreeterEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *GreeterEvent) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *GreeterEvent) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *GreeterEvent) GetGreeterId() int32 {
+	if x != nil {
+		return x.GreeterId
+	}
+	return 0
+}
+
+func (m *GreeterEvent) GetPayload() isGreeterEvent_Payload {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetCreated() *GreeterCreated {
+	if x, ok := x.GetPayload().(*GreeterEvent_Created); ok {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetUpdated() *GreeterUpdated {
+	if x, ok := x.GetPayload().(*GreeterEvent_Updated); ok {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetStatusChanged() *GreeterStatusChanged {
+	if x, ok := x.GetPayload().(*GreeterEvent_StatusChanged); ok {
+		return x.StatusChanged
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetCounterChanged() *GreeterCounterChanged {
+	if x, ok := x.GetPayload().(*GreeterEvent_CounterChanged); ok {
+		return x.CounterChanged
+	}
+	return nil
+}
+
+func (x *GreeterEvent) GetDeleted() *GreeterDeleted {
+	if x, ok := x.GetPayload().(*GreeterEvent_Deleted); ok {
+		return x.Deleted
+	}
+	return nil
+}
+
+type isGreeterEvent_Payload interface {
+	isGreeterEvent_Payload()
+}
+
+type GreeterEvent_Created struct {
+	Created *GreeterCreated `protobuf:"bytes,10,opt,name=created,proto3,oneof"`
+}
+
+type GreeterEvent_Updated struct {
+	Updated *GreeterUpdated `protobuf:"bytes,11,opt,name=updated,proto3,oneof"`
+}
+
+type GreeterEvent_StatusChanged struct {
+	StatusChanged *GreeterStatusChanged `protobuf:"bytes,12,opt,name=status_changed,json=statusChanged,proto3,oneof"`
+}
+
+type GreeterEvent_CounterChanged struct {
+	CounterChanged *GreeterCounterChanged `protobuf:"bytes,13,opt,name=counter_changed,json=counterChanged,proto3,oneof"`
+}
+
+type GreeterEvent_Deleted struct {
+	Deleted *GreeterDeleted `protobuf:"bytes,14,opt,name=deleted,proto3,oneof"`
+}
+
+func (*GreeterEvent_Created) isGreeterEvent_Payload() {}
+
+func (*GreeterEvent_Updated) isGreeterEvent_Payload() {}
+
+func (*GreeterEvent_StatusChanged) isGreeterEvent_Payload() {}
+
+func (*GreeterEvent_CounterChanged) isGreeterEvent_Payload() {}
+
+func (*GreeterEvent_Deleted) isGreeterEvent_Payload() {}
+
+// GreeterCreated 创建Greeter
+type GreeterCreated struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Greeter *Greeter `protobuf:"bytes,1,opt,name=greeter,proto3" json:"greeter"`
+}
+
+func (x *GreeterCreated) Reset() {
+	*x = GreeterCreated{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterCreated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterCreated) ProtoMessage() {}
+
+func (x *GreeterCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterCreated.ProtoReflect.Descriptor instead.
+func (*GreeterCreated) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GreeterCreated) GetGreeter() *Greeter {
+	if x != nil {
+		return x.Greeter
+	}
+	return nil
+}
+
+// GreeterUpdated 通过UpdateGreeter修改字段，或者恢复已软删除的Greeter
+type GreeterUpdated struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 恢复时before为空
+	Before *Greeter `protobuf:"bytes,1,opt,name=before,proto3" json:"before"`
+	After  *Greeter `protobuf:"bytes,2,opt,name=after,proto3" json:"after"`
+	// 修改的字段（FieldMask路径），恢复时为delete_datetime
+	Paths []string `protobuf:"bytes,3,rep,name=paths,proto3" json:"paths"`
+}
+
+func (x *GreeterUpdated) Reset() {
+	*x = GreeterUpdated{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterUpdated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterUpdated) ProtoMessage() {}
+
+func (x *GreeterUpdated) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterUpdated.ProtoReflect.Descriptor instead.
+func (*GreeterUpdated) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GreeterUpdated) GetBefore() *Greeter {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *GreeterUpdated) GetAfter() *Greeter {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *GreeterUpdated) GetPaths() []string {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+// GreeterStatusChanged 变更状态，通过UpdateGreeter修改status时在GreeterUpdated之后发布
+type GreeterStatusChanged struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From GreeterStatus `protobuf:"varint,1,opt,name=from,proto3,enum=greeter.GreeterStatus" json:"from"`
+	To   GreeterStatus `protobuf:"varint,2,opt,name=to,proto3,enum=greeter.GreeterStatus" json:"to"`
+	// 变更后的版本号
+	Version int32 `protobuf:"varint,3,opt,name=version,proto3" json:"version"`
+}
+
+func (x *GreeterStatusChanged) Reset() {
+	*x = GreeterStatusChanged{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterStatusChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterStatusChanged) ProtoMessage() {}
+
+func (x *GreeterStatusChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterStatusChanged.ProtoReflect.Descriptor instead.
+func (*GreeterStatusChanged) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GreeterStatusChanged) GetFrom() GreeterStatus {
+	if x != nil {
+		return x.From
+	}
+	return GreeterStatus_GREETER_STATUS_PENDING
+}
+
+func (x *GreeterStatusChanged) GetTo() GreeterStatus {
+	if x != nil {
+		return x.To
+	}
+	return GreeterStatus_GREETER_STATUS_PENDING
+}
+
+func (x *GreeterStatusChanged) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// GreeterCounterChanged 累加计数字段
+type GreeterCounterChanged struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column string `protobuf:"bytes,1,opt,name=column,proto3" json:"column"`
+	Delta  int32  `protobuf:"varint,2,opt,name=delta,proto3" json:"delta"`
+	// 为true时增量暂存在计数服务中，稍后批量写入数据库，写入时不再发布事件，此时version为0
+	Deferred bool `protobuf:"varint,3,opt,name=deferred,proto3" json:"deferred"`
+	// 变更后的版本号
+	Version int32 `protobuf:"varint,4,opt,name=version,proto3" json:"version"`
+}
+
+func (x *GreeterCounterChanged) Reset() {
+	*x = GreeterCounterChanged{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterCounterChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterCounterChanged) ProtoMessage() {}
+
+func (x *GreeterCounterChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterCounterChanged.ProtoReflect.Descriptor instead.
+func (*GreeterCounterChanged) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GreeterCounterChanged) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+func (x *GreeterCounterChanged) GetDelta() int32 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *GreeterCounterChanged) GetDeferred() bool {
+	if x != nil {
+		return x.Deferred
+	}
+	return false
+}
+
+func (x *GreeterCounterChanged) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// GreeterDeleted 软删除Greeter，超过保留时间后物理删除时不再发布事件
+type GreeterDeleted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 删除前的Greeter
+	Greeter *Greeter `protobuf:"bytes,1,opt,name=greeter,proto3" json:"greeter"`
+}
+
+func (x *GreeterDeleted) Reset() {
+	*x = GreeterDeleted{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_greeter_event_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GreeterDeleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GreeterDeleted) ProtoMessage() {}
+
+func (x *GreeterDeleted) ProtoReflect() protoreflect.Message {
+	mi := &file_greeter_event_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GreeterDeleted.ProtoReflect.Descriptor instead.
+func (*GreeterDeleted) Descriptor() ([]byte, []int) {
+	return file_greeter_event_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GreeterDeleted) GetGreeter() *Greeter {
+	if x != nil {
+		return x.Greeter
+	}
+	return nil
+}
+
+var File_greeter_event_proto protoreflect.FileDescriptor
+
+var file_greeter_event_proto_rawDesc = []byte{
+	0x0a, 0x13, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x5f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x07, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x1a, 0x1f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
+	0x0d, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb9,
+	0x04, 0x0a, 0x0c, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x19, 0x0a, 0x08, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63,
+	0x68, 0x65, 0x6d, 0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0d, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x3b, 0x0a, 0x0b, 0x6f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65, 0x64, 0x5f, 0x61, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x0a, 0x6f, 0x63, 0x63, 0x75, 0x72, 0x72, 0x65, 0x64, 0x41, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61,
+	0x63, 0x74, 0x6f, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x65, 0x49, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x49, 0x64, 0x12, 0x33, 0x0a,
+	0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x07, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x07,
+	0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x46, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x48, 0x00,
+	0x52, 0x0d, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12,
+	0x49, 0x0a, 0x0f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74,
+	0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65,
+	0x72, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0e, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x33, 0x0a, 0x07, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x07, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x42,
+	0x09, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x3c, 0x0a, 0x0e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x2a, 0x0a, 0x07,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e,
+	0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52,
+	0x07, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x22, 0x78, 0x0a, 0x0e, 0x47, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x06, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x06, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x12, 0x26, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x70, 0x61, 0x74, 0x68, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x70, 0x61, 0x74,
+	0x68, 0x73, 0x22, 0x84, 0x01, 0x0a, 0x14, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x12, 0x2a, 0x0a, 0x04, 0x66,
+	0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x26, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2e, 0x47, 0x72,
+	0x65, 0x65, 0x74, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x02, 0x74, 0x6f, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x7b, 0x0a, 0x15, 0x47, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x72, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x64, 0x65,
+	0x6c, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x64, 0x65, 0x6c, 0x74, 0x61,
+	0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x08, 0x64, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x3c, 0x0a, 0x0e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65,
+	0x72, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x2a, 0x0a, 0x07, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x67, 0x72, 0x65, 0x65,
+	0x74, 0x65, 0x72, 0x2e, 0x47, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x52, 0x07, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x42, 0x64, 0x5a, 0x3e, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x69, 0x6d, 0x69, 0x6e, 0x64, 0x2d, 0x6c, 0x61, 0x62, 0x2f, 0x67, 0x72, 0x65,
+	0x65, 0x74, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x2f, 0x67, 0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x67,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0xca, 0x02, 0x0d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c, 0x47,
+	0x72, 0x65, 0x65, 0x74, 0x65, 0x72, 0xe2, 0x02, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x5c, 0x47,
+	0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_greeter_event_proto_rawDescOnce sync.Once
+	file_greeter_event_proto_rawDescData = file_greeter_event_proto_rawDesc
+)
+
+func file_greeter_event_proto_rawDescGZIP() []byte {
+	file_greeter_event_proto_rawDescOnce.Do(func() {
+		file_greeter_event_proto_rawDescData = protoimpl.X.CompressGZIP(file_greeter_event_proto_rawDescData)
+	})
+	return file_greeter_event_proto_rawDescData
+}
+
+var file_greeter_event_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_greeter_event_proto_goTypes = []interface{}{
+	(*GreeterEvent)(nil),          // 0: greeter.GreeterEvent
+	(*GreeterCreated)(nil),        // 1: greeter.GreeterCreated
+	(*GreeterUpdated)(nil),        // 2: greeter.GreeterUpdated
+	(*GreeterStatusChanged)(nil),  // 3: greeter.GreeterStatusChanged
+	(*GreeterCounterChanged)(nil), // 4: greeter.GreeterCounterChanged
+	(*GreeterDeleted)(nil),        // 5: greeter.GreeterDeleted
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+	(*Greeter)(nil),               // 7: greeter.Greeter
+	(GreeterStatus)(0),            // 8: greeter.GreeterStatus
+}
+var file_greeter_event_proto_depIdxs = []int32{
+	6,  // 0: greeter.GreeterEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	1,  // 1: greeter.GreeterEvent.created:type_name -> greeter.GreeterCreated
+	2,  // 2: greeter.GreeterEvent.updated:type_name -> greeter.GreeterUpdated
+	3,  // 3: greeter.GreeterEvent.status_changed:type_name -> greeter.GreeterStatusChanged
+	4,  // 4: greeter.GreeterEvent.counter_changed:type_name -> greeter.GreeterCounterChanged
+	5,  // 5: greeter.GreeterEvent.deleted:type_name -> greeter.GreeterDeleted
+	7,  // 6: greeter.GreeterCreated.greeter:type_name -> greeter.Greeter
+	7,  // 7: greeter.GreeterUpdated.before:type_name -> greeter.Greeter
+	7,  // 8: greeter.GreeterUpdated.after:type_name -> greeter.Greeter
+	8,  // 9: greeter.GreeterStatusChanged.from:type_name -> greeter.GreeterStatus
+	8,  // 10: greeter.GreeterStatusChanged.to:type_name -> greeter.GreeterStatus
+	7,  // 11: greeter.GreeterDeleted.greeter:type_name -> greeter.Greeter
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_greeter_event_proto_init() }
+func file_greeter_event_proto_init() {
+	if File_greeter_event_proto != nil {
+		return
+	}
+	file_greeter_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_greeter_event_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_event_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterCreated); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_event_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterUpdated); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_event_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterStatusChanged); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_event_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterCounterChanged); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_greeter_event_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GreeterDeleted); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_greeter_event_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*GreeterEvent_Created)(nil),
+		(*GreeterEvent_Updated)(nil),
+		(*GreeterEvent_StatusChanged)(nil),
+		(*GreeterEvent_CounterChanged)(nil),
+		(*GreeterEvent_Deleted)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_greeter_event_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_greeter_event_proto_goTypes,
+		DependencyIndexes: file_greeter_event_proto_depIdxs,
+		MessageInfos:      file_greeter_event_proto_msgTypes,
+	}.Build()
+	File_greeter_event_proto = out.File
+	file_greeter_event_proto_rawDesc = nil
+	file_greeter_event_proto_goTypes = nil
+	file_greeter_event_proto_depIdxs = nil
+}
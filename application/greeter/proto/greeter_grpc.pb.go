@@ -20,10 +20,16 @@ const _ = grpc.SupportPackageIsVersion7
 type GreeterServiceClient interface {
 	CreateGreeter(ctx context.Context, in *CreateGreeterRequest, opts ...grpc.CallOption) (*CreateGreeterResponse, error)
 	GetGreeterById(ctx context.Context, in *GetGreeterByIdRequest, opts ...grpc.CallOption) (*GetGreeterByIdResponse, error)
+	BatchGetGreeters(ctx context.Context, in *BatchGetGreetersRequest, opts ...grpc.CallOption) (*BatchGetGreetersResponse, error)
 	GetGreeterList(ctx context.Context, in *GetGreeterListRequest, opts ...grpc.CallOption) (*GetGreeterListResponse, error)
+	UpdateGreeter(ctx context.Context, in *UpdateGreeterRequest, opts ...grpc.CallOption) (*UpdateGreeterResponse, error)
 	UpdateGreeterStatus(ctx context.Context, in *UpdateGreeterStatusRequest, opts ...grpc.CallOption) (*UpdateGreeterStatusResponse, error)
 	UpdateGreeterCount(ctx context.Context, in *UpdateGreeterCountRequest, opts ...grpc.CallOption) (*UpdateGreeterCountResponse, error)
 	DeleteGreeterById(ctx context.Context, in *DeleteGreeterByIdRequest, opts ...grpc.CallOption) (*DeleteGreeterByIdResponse, error)
+	RestoreGreeter(ctx context.Context, in *RestoreGreeterRequest, opts ...grpc.CallOption) (*RestoreGreeterResponse, error)
+	PurgeDeletedGreeters(ctx context.Context, in *PurgeDeletedGreetersRequest, opts ...grpc.CallOption) (*PurgeDeletedGreetersResponse, error)
+	ListGreeterHistory(ctx context.Context, in *ListGreeterHistoryRequest, opts ...grpc.CallOption) (*ListGreeterHistoryResponse, error)
+	SearchGreeters(ctx context.Context, in *SearchGreetersRequest, opts ...grpc.CallOption) (*SearchGreetersResponse, error)
 	GetGreeterListByStream(ctx context.Context, opts ...grpc.CallOption) (GreeterService_GetGreeterListByStreamClient, error)
 }
 
@@ -53,6 +59,15 @@ func (c *greeterServiceClient) GetGreeterById(ctx context.Context, in *GetGreete
 	return out, nil
 }
 
+func (c *greeterServiceClient) BatchGetGreeters(ctx context.Context, in *BatchGetGreetersRequest, opts ...grpc.CallOption) (*BatchGetGreetersResponse, error) {
+	out := new(BatchGetGreetersResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/BatchGetGreeters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterServiceClient) GetGreeterList(ctx context.Context, in *GetGreeterListRequest, opts ...grpc.CallOption) (*GetGreeterListResponse, error) {
 	out := new(GetGreeterListResponse)
 	err := c.cc.Invoke(ctx, "/greeter.GreeterService/GetGreeterList", in, out, opts...)
@@ -62,6 +77,15 @@ func (c *greeterServiceClient) GetGreeterList(ctx context.Context, in *GetGreete
 	return out, nil
 }
 
+func (c *greeterServiceClient) UpdateGreeter(ctx context.Context, in *UpdateGreeterRequest, opts ...grpc.CallOption) (*UpdateGreeterResponse, error) {
+	out := new(UpdateGreeterResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/UpdateGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterServiceClient) UpdateGreeterStatus(ctx context.Context, in *UpdateGreeterStatusRequest, opts ...grpc.CallOption) (*UpdateGreeterStatusResponse, error) {
 	out := new(UpdateGreeterStatusResponse)
 	err := c.cc.Invoke(ctx, "/greeter.GreeterService/UpdateGreeterStatus", in, out, opts...)
@@ -89,6 +113,42 @@ func (c *greeterServiceClient) DeleteGreeterById(ctx context.Context, in *Delete
 	return out, nil
 }
 
+func (c *greeterServiceClient) RestoreGreeter(ctx context.Context, in *RestoreGreeterRequest, opts ...grpc.CallOption) (*RestoreGreeterResponse, error) {
+	out := new(RestoreGreeterResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/RestoreGreeter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterServiceClient) PurgeDeletedGreeters(ctx context.Context, in *PurgeDeletedGreetersRequest, opts ...grpc.CallOption) (*PurgeDeletedGreetersResponse, error) {
+	out := new(PurgeDeletedGreetersResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/PurgeDeletedGreeters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterServiceClient) ListGreeterHistory(ctx context.Context, in *ListGreeterHistoryRequest, opts ...grpc.CallOption) (*ListGreeterHistoryResponse, error) {
+	out := new(ListGreeterHistoryResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/ListGreeterHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *greeterServiceClient) SearchGreeters(ctx context.Context, in *SearchGreetersRequest, opts ...grpc.CallOption) (*SearchGreetersResponse, error) {
+	out := new(SearchGreetersResponse)
+	err := c.cc.Invoke(ctx, "/greeter.GreeterService/SearchGreeters", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *greeterServiceClient) GetGreeterListByStream(ctx context.Context, opts ...grpc.CallOption) (GreeterService_GetGreeterListByStreamClient, error) {
 	stream, err := c.cc.NewStream(ctx, &GreeterService_ServiceDesc.Streams[0], "/greeter.GreeterService/GetGreeterListByStream", opts...)
 	if err != nil {
@@ -126,10 +186,16 @@ func (x *greeterServiceGetGreeterListByStreamClient) Recv() (*GetGreeterListBySt
 type GreeterServiceServer interface {
 	CreateGreeter(context.Context, *CreateGreeterRequest) (*CreateGreeterResponse, error)
 	GetGreeterById(context.Context, *GetGreeterByIdRequest) (*GetGreeterByIdResponse, error)
+	BatchGetGreeters(context.Context, *BatchGetGreetersRequest) (*BatchGetGreetersResponse, error)
 	GetGreeterList(context.Context, *GetGreeterListRequest) (*GetGreeterListResponse, error)
+	UpdateGreeter(context.Context, *UpdateGreeterRequest) (*UpdateGreeterResponse, error)
 	UpdateGreeterStatus(context.Context, *UpdateGreeterStatusRequest) (*UpdateGreeterStatusResponse, error)
 	UpdateGreeterCount(context.Context, *UpdateGreeterCountRequest) (*UpdateGreeterCountResponse, error)
 	DeleteGreeterById(context.Context, *DeleteGreeterByIdRequest) (*DeleteGreeterByIdResponse, error)
+	RestoreGreeter(context.Context, *RestoreGreeterRequest) (*RestoreGreeterResponse, error)
+	PurgeDeletedGreeters(context.Context, *PurgeDeletedGreetersRequest) (*PurgeDeletedGreetersResponse, error)
+	ListGreeterHistory(context.Context, *ListGreeterHistoryRequest) (*ListGreeterHistoryResponse, error)
+	SearchGreeters(context.Context, *SearchGreetersRequest) (*SearchGreetersResponse, error)
 	GetGreeterListByStream(GreeterService_GetGreeterListByStreamServer) error
 	mustEmbedUnimplementedGreeterServiceServer()
 }
@@ -144,9 +210,15 @@ func (UnimplementedGreeterServiceServer) CreateGreeter(context.Context, *CreateG
 func (UnimplementedGreeterServiceServer) GetGreeterById(context.Context, *GetGreeterByIdRequest) (*GetGreeterByIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetGreeterById not implemented")
 }
+func (UnimplementedGreeterServiceServer) BatchGetGreeters(context.Context, *BatchGetGreetersRequest) (*BatchGetGreetersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetGreeters not implemented")
+}
 func (UnimplementedGreeterServiceServer) GetGreeterList(context.Context, *GetGreeterListRequest) (*GetGreeterListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetGreeterList not implemented")
 }
+func (UnimplementedGreeterServiceServer) UpdateGreeter(context.Context, *UpdateGreeterRequest) (*UpdateGreeterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGreeter not implemented")
+}
 func (UnimplementedGreeterServiceServer) UpdateGreeterStatus(context.Context, *UpdateGreeterStatusRequest) (*UpdateGreeterStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateGreeterStatus not implemented")
 }
@@ -156,6 +228,18 @@ func (UnimplementedGreeterServiceServer) UpdateGreeterCount(context.Context, *Up
 func (UnimplementedGreeterServiceServer) DeleteGreeterById(context.Context, *DeleteGreeterByIdRequest) (*DeleteGreeterByIdResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteGreeterById not implemented")
 }
+func (UnimplementedGreeterServiceServer) RestoreGreeter(context.Context, *RestoreGreeterRequest) (*RestoreGreeterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreGreeter not implemented")
+}
+func (UnimplementedGreeterServiceServer) PurgeDeletedGreeters(context.Context, *PurgeDeletedGreetersRequest) (*PurgeDeletedGreetersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeDeletedGreeters not implemented")
+}
+func (UnimplementedGreeterServiceServer) ListGreeterHistory(context.Context, *ListGreeterHistoryRequest) (*ListGreeterHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGreeterHistory not implemented")
+}
+func (UnimplementedGreeterServiceServer) SearchGreeters(context.Context, *SearchGreetersRequest) (*SearchGreetersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchGreeters not implemented")
+}
 func (UnimplementedGreeterServiceServer) GetGreeterListByStream(GreeterService_GetGreeterListByStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method GetGreeterListByStream not implemented")
 }
@@ -208,6 +292,24 @@ func _GreeterService_GetGreeterById_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GreeterService_BatchGetGreeters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetGreetersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).BatchGetGreeters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/BatchGetGreeters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).BatchGetGreeters(ctx, req.(*BatchGetGreetersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GreeterService_GetGreeterList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetGreeterListRequest)
 	if err := dec(in); err != nil {
@@ -226,6 +328,24 @@ func _GreeterService_GetGreeterList_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GreeterService_UpdateGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).UpdateGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/UpdateGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).UpdateGreeter(ctx, req.(*UpdateGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GreeterService_UpdateGreeterStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateGreeterStatusRequest)
 	if err := dec(in); err != nil {
@@ -280,6 +400,78 @@ func _GreeterService_DeleteGreeterById_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _GreeterService_RestoreGreeter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreGreeterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).RestoreGreeter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/RestoreGreeter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).RestoreGreeter(ctx, req.(*RestoreGreeterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GreeterService_PurgeDeletedGreeters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeDeletedGreetersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).PurgeDeletedGreeters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/PurgeDeletedGreeters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).PurgeDeletedGreeters(ctx, req.(*PurgeDeletedGreetersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GreeterService_ListGreeterHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGreeterHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).ListGreeterHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/ListGreeterHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).ListGreeterHistory(ctx, req.(*ListGreeterHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GreeterService_SearchGreeters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchGreetersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GreeterServiceServer).SearchGreeters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/greeter.GreeterService/SearchGreeters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GreeterServiceServer).SearchGreeters(ctx, req.(*SearchGreetersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _GreeterService_GetGreeterListByStream_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(GreeterServiceServer).GetGreeterListByStream(&greeterServiceGetGreeterListByStreamServer{stream})
 }
@@ -321,10 +513,18 @@ var GreeterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetGreeterById",
 			Handler:    _GreeterService_GetGreeterById_Handler,
 		},
+		{
+			MethodName: "BatchGetGreeters",
+			Handler:    _GreeterService_BatchGetGreeters_Handler,
+		},
 		{
 			MethodName: "GetGreeterList",
 			Handler:    _GreeterService_GetGreeterList_Handler,
 		},
+		{
+			MethodName: "UpdateGreeter",
+			Handler:    _GreeterService_UpdateGreeter_Handler,
+		},
 		{
 			MethodName: "UpdateGreeterStatus",
 			Handler:    _GreeterService_UpdateGreeterStatus_Handler,
@@ -337,6 +537,22 @@ var GreeterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteGreeterById",
 			Handler:    _GreeterService_DeleteGreeterById_Handler,
 		},
+		{
+			MethodName: "RestoreGreeter",
+			Handler:    _GreeterService_RestoreGreeter_Handler,
+		},
+		{
+			MethodName: "PurgeDeletedGreeters",
+			Handler:    _GreeterService_PurgeDeletedGreeters_Handler,
+		},
+		{
+			MethodName: "ListGreeterHistory",
+			Handler:    _GreeterService_ListGreeterHistory_Handler,
+		},
+		{
+			MethodName: "SearchGreeters",
+			Handler:    _GreeterService_SearchGreeters_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -9,26 +9,30 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 
 	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
 	"github.com/imind-lab/greeter/domain/greeter/service"
 	"github.com/imind-lab/greeter/pkg/constant"
-	"github.com/imind-lab/micro/broker"
-	"github.com/imind-lab/micro/status"
+	statusx "github.com/imind-lab/greeter/pkg/status"
+	"github.com/imind-lab/greeter/pkg/validate"
 	"github.com/imind-lab/micro/util"
 )
 
 type GreeterService struct {
 	greeter.UnimplementedGreeterServiceServer
 
-	vd *validator.Validate
+	vd *validate.Validator
 
 	dm service.GreeterDomain
 }
@@ -37,7 +41,7 @@ func NewGreeterService() *GreeterService {
 	dm := service.NewGreeterDomain()
 	svc := &GreeterService{
 		dm: dm,
-		vd: validator.New(),
+		vd: validate.New(),
 	}
 
 	return svc
@@ -50,62 +54,25 @@ func (svc *GreeterService) CreateGreeter(ctx context.Context, req *greeter.Creat
 
 	rsp := &greeter.CreateGreeterResponse{}
 
-	m := req.Data
-	err := svc.vd.Struct(req)
+	err := svc.validate(logger, req)
 	if err != nil {
-
-		if _, ok := err.(*validator.InvalidValidationError); ok {
-			fmt.Println(err)
-		}
-
-		for _, err := range err.(validator.ValidationErrors) {
-			fmt.Println(err.Namespace())
-			fmt.Println(err.Field())
-			fmt.Println(err.StructNamespace())
-			fmt.Println(err.StructField())
-			fmt.Println(err.Tag())
-			fmt.Println(err.ActualTag())
-			fmt.Println(err.Kind())
-			fmt.Println(err.Type())
-			fmt.Println(err.Value())
-			fmt.Println(err.Param())
-			fmt.Println()
-		}
-
-	}
-	if m == nil {
-		logger.Error("Greeter不能为空", zap.Any("params", m), zap.Error(err))
-		rsp.SetCode(status.MissingParams, "Greeter不能为空")
-		return rsp, nil
+		return nil, err
 	}
 
-	err = svc.vd.Var(m.Name, "email")
-	if err != nil {
-		logger.Error("Name不能为空", zap.Any("name", m.Name), zap.Error(err))
-		rsp.SetCode(status.InvalidParams, "Name不能为空")
-		return rsp, nil
-	}
+	m := req.Data
 	m.CreateTime = util.GetNowWithMillisecond()
 	m.CreateDatetime = time.Now().Format(util.DateTimeFmt)
 	m.UpdateDatetime = time.Now().Format(util.DateTimeFmt)
 	err = svc.dm.CreateGreeter(ctx, m)
 	if err != nil {
 		logger.Error("创建Greeter失败", zap.Any("greeter", m), zap.Error(err))
-		rsp.SetCode(status.DBSaveFailed, "创建Greeter失败")
-		return rsp, nil
-	}
-
-	endpoint, err := broker.NewBroker(constant.MQName)
-	if err != nil {
-		ctxzap.Error(ctx, "broker.NewBroker error", zap.Error(err))
-		return rsp, err
+		if errors.Is(err, service.ErrIllegalStatusTransition) {
+			return nil, statusx.InvalidArgument(constant.StatusTransitionIllegal, "不允许以该状态创建Greeter",
+				statusx.FieldViolation("data.status", "只能以待审核或正常状态创建Greeter"))
+		}
+		return nil, statusx.Internal(constant.CreateGreeterFailed, "创建Greeter失败")
 	}
-	endpoint.Publish(&broker.Message{
-		Topic: endpoint.Options().Topics["creategreeter"],
-		Body:  []byte(fmt.Sprintf("Greeter %s Created", m.Name)),
-	})
 
-	rsp.SetCode(status.Success, "")
 	return rsp, nil
 }
 
@@ -118,63 +85,151 @@ func (svc *GreeterService) GetGreeterById(ctx context.Context, req *greeter.GetG
 	m, err := svc.dm.GetGreeterById(ctx, req.Id)
 	if err != nil {
 		logger.Error("获取Greeter失败", zap.Any("greeter", m), zap.Error(err))
-		rsp.SetCode(status.DBQueryFailed, "获取Greeter失败")
-		return rsp, nil
+		return nil, statusx.Unavailable(constant.FetchGreeterFailed, "获取Greeter失败", constant.RetryDelay)
+	}
+	if m == nil {
+		logger.Info("Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "Greeter不存在")
 	}
-	rsp.SetBody(status.Success, m)
+	rsp.Data = m
 	return rsp, nil
 }
 
+// BatchGetGreeters 根据Id批量获取Greeter
+func (svc *GreeterService) BatchGetGreeters(ctx context.Context, req *greeter.BatchGetGreetersRequest) (*greeter.BatchGetGreetersResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "BatchGetGreeters"))
+	logger.Debug("Receive BatchGetGreeters request")
+
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &greeter.BatchGetGreetersResponse{}
+	list, notFound, err := svc.dm.GetGreetersByIds(ctx, req.Ids)
+	if err != nil {
+		logger.Error("批量获取Greeter失败", zap.Int32s("ids", req.Ids), zap.Error(err))
+		return nil, statusx.Unavailable(constant.FetchGreeterListFailed, "批量获取Greeter失败", constant.RetryDelay)
+	}
+	rsp.Data = list
+	rsp.NotFound = notFound
+	return rsp, nil
+}
+
+// greeterSortFields 列表排序字段与数据库列的对应关系
+var greeterSortFields = map[greeter.GreeterSortField]repository.GreeterSortField{
+	greeter.GreeterSortField_GREETER_SORT_FIELD_ID:          repository.GreeterSortById,
+	greeter.GreeterSortField_GREETER_SORT_FIELD_VIEW_NUM:    repository.GreeterSortByViewNum,
+	greeter.GreeterSortField_GREETER_SORT_FIELD_CREATE_TIME: repository.GreeterSortByCreateTime,
+}
+
 func (svc *GreeterService) GetGreeterList(ctx context.Context, req *greeter.GetGreeterListRequest) (*greeter.GetGreeterListResponse, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "GetGreeterList"))
 	logger.Debug("Receive GetGreeterList request")
 	rsp := &greeter.GetGreeterListResponse{}
 
-	err := svc.vd.Struct(req)
+	if req.Pagesize <= 0 {
+		req.Pagesize = 20
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	err := svc.validate(logger, req)
 	if err != nil {
+		return nil, err
+	}
 
-		if _, ok := err.(*validator.InvalidValidationError); ok {
-			fmt.Println(err)
+	var statuses []int32
+	for _, status := range req.Statuses {
+		statuses = append(statuses, int32(status))
+	}
+	list, err := svc.dm.GetGreeterList(ctx, repository.GreeterListQuery{
+		Status:         int32(req.Status),
+		Statuses:       statuses,
+		IncludeDeleted: req.IncludeDeleted,
+		NamePrefix:     req.NamePrefix,
+		NameContains:   req.NameContains,
+		CreateTimeFrom: req.CreateTimeFrom,
+		CreateTimeTo:   req.CreateTimeTo,
+		MinViewNum:     req.MinViewNum,
+		SortBy:         greeterSortFields[req.SortBy],
+		Ascending:      req.Order == greeter.SortOrder_SORT_ORDER_ASC,
+		PageSize:       req.Pagesize,
+		PageToken:      req.PageToken,
+		LastId:         req.Lastid,
+		Page:           req.Page,
+	})
+	if err != nil {
+		logger.Error("获取Greeter失败", zap.Any("list", list), zap.Error(err))
+		if errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, statusx.InvalidArgument(constant.PageTokenIsInvalid, "page_token不合法",
+				statusx.FieldViolation("page_token", "page_token不合法或与查询条件不一致"))
 		}
+		return nil, statusx.Unavailable(constant.FetchGreeterListFailed, "获取Greeter失败", constant.RetryDelay)
+	}
+	rsp.Data = list
+	return rsp, nil
+}
 
-		for _, err := range err.(validator.ValidationErrors) {
-
-			fmt.Println(err.Namespace())
-			fmt.Println(err.Field())
-			fmt.Println(err.StructNamespace())
-			fmt.Println(err.StructField())
-			fmt.Println(err.Tag())
-			fmt.Println(err.ActualTag())
-			fmt.Println(err.Kind())
-			fmt.Println(err.Type())
-			fmt.Println(err.Value())
-			fmt.Println(err.Param())
-			fmt.Println()
-		}
+// UpdateGreeter 根据FieldMask更新Greeter的部分字段
+func (svc *GreeterService) UpdateGreeter(ctx context.Context, req *greeter.UpdateGreeterRequest) (*greeter.UpdateGreeterResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "UpdateGreeter"))
+	logger.Debug("Receive UpdateGreeter request")
 
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		logger.Error("update_mask不能为空")
+		return nil, statusx.InvalidArgument(constant.RequestIsInvalid, "update_mask不能为空",
+			statusx.FieldViolation("update_mask", "update_mask不能为空"))
 	}
-	err = svc.vd.Var(req.Status, "gte=0,lte=3")
-	if err != nil {
-		logger.Error("请输入有效的Status", zap.Int32("status", req.Status), zap.Error(err))
-		rsp.SetCode(status.InvalidParams, "请输入有效的Status")
-		return rsp, nil
+	if req.Greeter != nil && !req.UpdateMask.IsValid(req.Greeter) {
+		logger.Error("update_mask包含未知字段", zap.Strings("paths", paths))
+		return nil, statusx.InvalidArgument(constant.RequestIsInvalid, "update_mask包含未知字段",
+			statusx.FieldViolation("update_mask", "update_mask包含未知字段"))
 	}
 
-	if req.Pagesize <= 0 {
-		req.Pagesize = 20
+	// 只校验需要更新的字段
+	fields := []string{"id", "greeter", "version"}
+	for _, path := range paths {
+		fields = append(fields, "greeter."+path)
+	}
+	violations, err := svc.vd.StructPartial(req, fields...)
+	err = svc.violationsError(logger, req, violations, err)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Page <= 0 {
-		req.Page = 1
+	m := req.Greeter
+	m.Id = req.Id
+	m.Version = req.Version
+	affected, err := svc.dm.UpdateGreeter(ctx, m, paths)
+	if err != nil {
+		logger.Error("更新Greeter失败", zap.Any("greeter", m), zap.Strings("paths", paths), zap.Error(err))
+		if errors.Is(err, service.ErrFieldNotUpdatable) {
+			return nil, statusx.InvalidArgument(constant.RequestIsInvalid, "update_mask包含不允许更新的字段",
+				statusx.FieldViolation("update_mask", "update_mask包含不允许更新的字段"))
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return nil, statusx.Aborted(constant.VersionConflict, "Greeter已被修改，请刷新后重试")
+		}
+		if errors.Is(err, service.ErrIllegalStatusTransition) {
+			return nil, statusTransitionError(req.Id, err)
+		}
+		return nil, statusx.Internal(constant.UpdateGreeterFailed, "更新Greeter失败")
+	}
+	if affected <= 0 {
+		logger.Error("Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "Greeter不存在")
 	}
 
-	list, err := svc.dm.GetGreeterList(ctx, req.Status, req.Lastid, req.Pagesize, req.Page)
+	rsp := &greeter.UpdateGreeterResponse{}
+	rsp.Data, err = svc.dm.GetGreeterById(ctx, req.Id)
 	if err != nil {
-		logger.Error("获取Greeter失败", zap.Any("list", list), zap.Error(err))
-		rsp.SetCode(status.DBQueryFailed, "获取Greeter失败")
-		return rsp, nil
+		logger.Error("获取Greeter失败", zap.Int32("id", req.Id), zap.Error(err))
+		return nil, statusx.Unavailable(constant.FetchGreeterFailed, "获取Greeter失败", constant.RetryDelay)
 	}
-	rsp.SetBody(status.Success, list)
 	return rsp, nil
 }
 
@@ -182,14 +237,27 @@ func (svc *GreeterService) UpdateGreeterStatus(ctx context.Context, req *greeter
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "UpdateGreeterStatus"))
 	logger.Debug("Receive UpdateGreeterStatus request")
 
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
 	rsp := &greeter.UpdateGreeterStatusResponse{}
-	affected, err := svc.dm.UpdateGreeterStatus(ctx, req.Id, req.Status)
-	if err != nil || affected <= 0 {
+	affected, err := svc.dm.UpdateGreeterStatus(ctx, req.Id, req.Status, req.Version)
+	if err != nil {
 		logger.Error("更新Greeter失败", zap.Int64("affected", affected), zap.Error(err))
-		rsp.SetCode(status.DBSaveFailed, "更新Greeter失败")
-		return rsp, nil
+		if errors.Is(err, service.ErrVersionConflict) {
+			return nil, statusx.Aborted(constant.VersionConflict, "Greeter已被修改，请刷新后重试")
+		}
+		if errors.Is(err, service.ErrIllegalStatusTransition) {
+			return nil, statusTransitionError(req.Id, err)
+		}
+		return nil, statusx.Internal(constant.UpdateGreeterFailed, "更新Greeter失败")
+	}
+	if affected <= 0 {
+		logger.Error("Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "Greeter不存在")
 	}
-	rsp.SetCode(status.Success, "")
 	return rsp, nil
 }
 
@@ -197,23 +265,28 @@ func (svc *GreeterService) UpdateGreeterCount(ctx context.Context, req *greeter.
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "UpdateGreeterCount"))
 	logger.Debug("Receive UpdateGreeterCount request")
 
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
 	rsp := &greeter.UpdateGreeterCountResponse{}
-	affected, err := svc.dm.UpdateGreeterCount(ctx, req.Id, req.Num, req.Column)
-	if err != nil || affected <= 0 {
+	affected, err := svc.dm.UpdateGreeterCount(ctx, req.Id, req.Num, req.Version, req.Column)
+	if err != nil {
 		logger.Error("更新Greeter失败", zap.Int64("affected", affected), zap.Error(err))
-		rsp.SetCode(status.DBSaveFailed, "更新Greeter失败")
-		return rsp, nil
+		if errors.Is(err, service.ErrCounterNotAllowed) {
+			return nil, statusx.InvalidArgument(constant.RequestIsInvalid, "column不是允许累加的计数字段",
+				statusx.FieldViolation("column", "column不是允许累加的计数字段"))
+		}
+		if errors.Is(err, service.ErrVersionConflict) {
+			return nil, statusx.Aborted(constant.VersionConflict, "Greeter已被修改，请刷新后重试")
+		}
+		return nil, statusx.Internal(constant.UpdateGreeterFailed, "更新Greeter失败")
 	}
-	endpoint, err := broker.NewBroker(constant.MQName)
-	if err != nil {
-		ctxzap.Error(ctx, "kafka.New error", zap.Error(err))
-		return rsp, err
+	if affected <= 0 {
+		logger.Error("Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "Greeter不存在")
 	}
-	endpoint.Publish(&broker.Message{
-		Topic: endpoint.Options().Topics["updategreetercount"],
-		Body:  nil,
-	})
-	rsp.SetCode(status.Success, "")
 	return rsp, nil
 }
 
@@ -221,17 +294,127 @@ func (svc *GreeterService) DeleteGreeterById(ctx context.Context, req *greeter.D
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "DeleteGreeterById"))
 	logger.Debug("Receive DeleteGreeterById request")
 
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
 	rsp := &greeter.DeleteGreeterByIdResponse{}
-	affected, err := svc.dm.DeleteGreeterById(ctx, req.Id)
-	if err != nil || affected <= 0 {
-		logger.Error("更新Greeter失败", zap.Int64("affected", affected), zap.Error(err))
-		rsp.SetCode(status.DBSaveFailed, "更新Greeter失败")
-		return rsp, nil
+	affected, err := svc.dm.DeleteGreeterById(ctx, req.Id, req.Version)
+	if err != nil {
+		logger.Error("删除Greeter失败", zap.Int64("affected", affected), zap.Error(err))
+		if errors.Is(err, service.ErrVersionConflict) {
+			return nil, statusx.Aborted(constant.VersionConflict, "Greeter已被修改，请刷新后重试")
+		}
+		return nil, statusx.Internal(constant.DeleteGreeterFailed, "删除Greeter失败")
+	}
+	if affected <= 0 {
+		logger.Error("Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "Greeter不存在")
+	}
+	return rsp, nil
+}
+
+// RestoreGreeter 恢复已软删除的Greeter
+func (svc *GreeterService) RestoreGreeter(ctx context.Context, req *greeter.RestoreGreeterRequest) (*greeter.RestoreGreeterResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "RestoreGreeter"))
+	logger.Debug("Receive RestoreGreeter request")
+
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := svc.dm.RestoreGreeter(ctx, req.Id, req.Version)
+	if err != nil {
+		logger.Error("恢复Greeter失败", zap.Int32("id", req.Id), zap.Error(err))
+		if errors.Is(err, service.ErrVersionConflict) {
+			return nil, statusx.Aborted(constant.VersionConflict, "Greeter已被修改，请刷新后重试")
+		}
+		return nil, statusx.Internal(constant.RestoreGreeterFailed, "恢复Greeter失败")
+	}
+	if affected <= 0 {
+		logger.Error("已删除的Greeter不存在", zap.Int32("id", req.Id))
+		return nil, statusx.NotFound(constant.GreeterNotFound, "已删除的Greeter不存在")
+	}
+
+	rsp := &greeter.RestoreGreeterResponse{}
+	rsp.Data, err = svc.dm.GetGreeterById(ctx, req.Id)
+	if err != nil {
+		logger.Error("获取Greeter失败", zap.Int32("id", req.Id), zap.Error(err))
+		return nil, statusx.Unavailable(constant.FetchGreeterFailed, "获取Greeter失败", constant.RetryDelay)
 	}
-	rsp.SetCode(status.Success, "")
 	return rsp, nil
 }
 
+// PurgeDeletedGreeters 物理删除软删除时间超过older_than的Greeter
+func (svc *GreeterService) PurgeDeletedGreeters(ctx context.Context, req *greeter.PurgeDeletedGreetersRequest) (*greeter.PurgeDeletedGreetersResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "PurgeDeletedGreeters"))
+	logger.Debug("Receive PurgeDeletedGreeters request")
+
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.OlderThan.CheckValid(); err != nil || req.OlderThan.AsDuration() < 0 {
+		logger.Error("older_than不合法", zap.Stringer("older_than", req.OlderThan), zap.Error(err))
+		return nil, statusx.InvalidArgument(constant.RequestIsInvalid, "older_than不能为负数",
+			statusx.FieldViolation("older_than", "older_than不能为负数"))
+	}
+
+	purged, err := svc.dm.PurgeDeletedGreeters(ctx, req.OlderThan.AsDuration())
+	if err != nil {
+		logger.Error("清理已删除的Greeter失败", zap.Duration("older_than", req.OlderThan.AsDuration()), zap.Error(err))
+		return nil, statusx.Internal(constant.PurgeGreeterFailed, "清理已删除的Greeter失败")
+	}
+	return &greeter.PurgeDeletedGreetersResponse{Purged: purged}, nil
+}
+
+// ListGreeterHistory 按时间倒序获取Greeter的变更记录
+func (svc *GreeterService) ListGreeterHistory(ctx context.Context, req *greeter.ListGreeterHistoryRequest) (*greeter.ListGreeterHistoryResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "ListGreeterHistory"))
+	logger.Debug("Receive ListGreeterHistory request")
+
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
+	list, nextPageToken, err := svc.dm.ListGreeterHistory(ctx, req.Id, req.PageToken, req.PageSize)
+	if err != nil {
+		logger.Error("获取Greeter变更记录失败", zap.Int32("id", req.Id), zap.String("page_token", req.PageToken), zap.Error(err))
+		if errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, statusx.InvalidArgument(constant.PageTokenIsInvalid, "page_token不合法",
+				statusx.FieldViolation("page_token", "page_token不合法"))
+		}
+		return nil, statusx.Unavailable(constant.FetchGreeterHistoryFailed, "获取Greeter变更记录失败", constant.RetryDelay)
+	}
+	return &greeter.ListGreeterHistoryResponse{Data: list, NextPageToken: nextPageToken}, nil
+}
+
+// SearchGreeters 按名称中的片段搜索Greeter
+func (svc *GreeterService) SearchGreeters(ctx context.Context, req *greeter.SearchGreetersRequest) (*greeter.SearchGreetersResponse, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "GreeterService"), zap.String("func", "SearchGreeters"))
+	logger.Debug("Receive SearchGreeters request")
+
+	req.Query = strings.TrimSpace(req.Query)
+	err := svc.validate(logger, req)
+	if err != nil {
+		return nil, err
+	}
+
+	list, total, nextPageToken, err := svc.dm.SearchGreeters(ctx, req.Query, req.PageToken, req.PageSize)
+	if err != nil {
+		logger.Error("搜索Greeter失败", zap.String("query", req.Query), zap.String("page_token", req.PageToken), zap.Error(err))
+		if errors.Is(err, service.ErrInvalidPageToken) {
+			return nil, statusx.InvalidArgument(constant.PageTokenIsInvalid, "page_token不合法",
+				statusx.FieldViolation("page_token", "page_token不合法或与query不一致"))
+		}
+		return nil, statusx.Unavailable(constant.SearchGreetersFailed, "搜索Greeter失败", constant.RetryDelay)
+	}
+	return &greeter.SearchGreetersResponse{Data: list, Total: total, NextPageToken: nextPageToken}, nil
+}
+
 func (svc *GreeterService) GetGreeterListByStream(stream greeter.GreeterService_GetGreeterListByStreamServer) error {
 	logger := ctxzap.Extract(stream.Context()).With(zap.String("layer", "GreeterService"), zap.String("func", "GetGreeterListByStream"))
 	logger.Debug("Receive GetGreeterListByStream request")
@@ -251,7 +434,7 @@ func (svc *GreeterService) GetGreeterListByStream(stream greeter.GreeterService_
 			m, err := svc.dm.GetGreeterById(stream.Context(), r.Id)
 			if err != nil {
 				logger.Error("GetGreeterById error", zap.Any("greeter", m), zap.Error(err))
-				return err
+				return statusx.Unavailable(constant.FetchGreeterFailed, "获取Greeter失败", constant.RetryDelay)
 			}
 
 			err = stream.Send(&greeter.GetGreeterListByStreamResponse{
@@ -270,3 +453,50 @@ func (svc *GreeterService) GetGreeterListByStream(stream greeter.GreeterService_
 		}
 	}
 }
+
+// statusTransitionError 状态机拒绝的状态变更，通过google.rpc.PreconditionFailure说明当前状态与目标状态
+func statusTransitionError(id int32, err error) error {
+	description := err.Error()
+	var transition *service.StatusTransitionError
+	if errors.As(err, &transition) {
+		description = transition.Error()
+	}
+	return statusx.FailedPrecondition(constant.StatusTransitionIllegal, "不允许的状态变更", &errdetails.PreconditionFailure_Violation{
+		Type:        "STATUS_TRANSITION",
+		Subject:     fmt.Sprintf("greeter/%d", id),
+		Description: description,
+	})
+}
+
+// validate 按照greeter.proto中@inject_tag声明的validate规则校验请求，失败时返回携带字段错误的InvalidArgument
+func (svc *GreeterService) validate(logger *zap.Logger, req interface{}) error {
+	violations, err := svc.vd.Struct(req)
+	return svc.violationsError(logger, req, violations, err)
+}
+
+// violationsError 将校验结果转换为InvalidArgument错误，同时返回google.rpc.BadRequest和greeter.FieldViolations
+func (svc *GreeterService) violationsError(logger *zap.Logger, req interface{}, violations []validate.Violation, err error) error {
+	if err != nil {
+		logger.Error("请求参数无法校验", zap.Any("req", req), zap.Error(err))
+		return statusx.InvalidArgument(constant.RequestIsInvalid, "请求参数无法校验")
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	details := &greeter.FieldViolations{}
+	for _, v := range violations {
+		logger.Warn("请求参数校验失败", zap.String("field", v.Field), zap.String("rule", v.Rule),
+			zap.String("param", v.Param), zap.String("message", v.Message))
+
+		badRequest.FieldViolations = append(badRequest.FieldViolations, statusx.FieldViolation(v.Field, v.Message))
+		details.Violations = append(details.Violations, &greeter.FieldViolation{
+			Field:   v.Field,
+			Rule:    v.Rule,
+			Param:   v.Param,
+			Message: v.Message,
+		})
+	}
+	return statusx.Error(codes.InvalidArgument, constant.RequestIsInvalid, violations[0].Message, badRequest, details)
+}
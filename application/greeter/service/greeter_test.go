@@ -2,13 +2,24 @@ package service
 
 import (
 	"context"
-	"github.com/go-playground/validator/v10"
 	"github.com/golang/mock/gomock"
 	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	"github.com/imind-lab/greeter/pkg/constant"
+	statusx "github.com/imind-lab/greeter/pkg/status"
+	"github.com/imind-lab/greeter/pkg/validate"
 	"github.com/imind-lab/greeter/test/mock"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"testing"
+	"time"
 )
 
 type Suite struct {
@@ -23,7 +34,7 @@ func (s *Suite) SetupSuite() {
 	s.dmMock = mock.NewMockGreeterDomain(s.ctl)
 	s.svc = GreeterService{
 		dm: s.dmMock,
-		vd: validator.New(),
+		vd: validate.New(),
 	}
 }
 
@@ -62,6 +73,244 @@ func (s *Suite) TestGreeterService_GetGreeterById() {
 	}
 }
 
+func (s *Suite) TestGreeterService_GetGreeterByIdNotFound() {
+	ctx := context.Background()
+	s.dmMock.EXPECT().GetGreeterById(ctx, int32(404)).Return(nil, nil)
+
+	m, err := s.svc.GetGreeterById(ctx, &greeter.GetGreeterByIdRequest{Id: 404})
+	require.Nil(s.T(), m)
+	require.Equal(s.T(), codes.NotFound, status.Code(err))
+	require.Equal(s.T(), constant.GreeterNotFound, statusx.Code(err))
+}
+
+func (s *Suite) TestGreeterService_BatchGetGreeters() {
+	ctx := context.Background()
+	data := []*greeter.Greeter{
+		{Id: 300, Name: "18601038093", ViewNum: 4, Status: 1},
+		{Id: 100, Name: "18601038091", ViewNum: 2, Status: 1},
+	}
+	s.dmMock.EXPECT().GetGreetersByIds(ctx, []int32{300, 200, 100}).Return(data, []int32{200}, nil)
+
+	m, err := s.svc.BatchGetGreeters(ctx, &greeter.BatchGetGreetersRequest{Ids: []int32{300, 200, 100}})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), &greeter.BatchGetGreetersResponse{Data: data, NotFound: []int32{200}}, m)
+
+	_, err = s.svc.BatchGetGreeters(ctx, &greeter.BatchGetGreetersRequest{})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (s *Suite) TestGreeterService_UpdateGreeter() {
+	ctx := context.Background()
+	data := &greeter.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 1}
+
+	// status不在update_mask中，不参与校验
+	req := &greeter.UpdateGreeterRequest{
+		Id:         100,
+		Greeter:    &greeter.Greeter{Name: "koofox@imind.tech", Status: 9},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	}
+	s.dmMock.EXPECT().UpdateGreeter(ctx, req.Greeter, []string{"name"}).Return(int64(1), nil)
+	s.dmMock.EXPECT().GetGreeterById(ctx, int32(100)).Return(data, nil)
+
+	m, err := s.svc.UpdateGreeter(ctx, req)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), data, m.Data)
+
+	_, err = s.svc.UpdateGreeter(ctx, &greeter.UpdateGreeterRequest{
+		Id:         100,
+		Greeter:    &greeter.Greeter{Name: "koofox"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	st := status.Convert(err)
+	require.Equal(s.T(), codes.InvalidArgument, st.Code())
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = br.FieldViolations
+		}
+	}
+	require.Len(s.T(), violations, 1)
+	require.Equal(s.T(), "greeter.name", violations[0].Field)
+
+	_, err = s.svc.UpdateGreeter(ctx, &greeter.UpdateGreeterRequest{
+		Id:         100,
+		Greeter:    &greeter.Greeter{Name: "koofox@imind.tech"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"nickname"}},
+	})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (s *Suite) TestGreeterService_UpdateGreeterStatusVersionConflict() {
+	ctx := context.Background()
+	s.dmMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), greeter.GreeterStatus_GREETER_STATUS_DISABLED, int32(3)).
+		Return(int64(0), errors.Wrap(service.ErrVersionConflict, "greeterRepository.UpdateGreeterStatus"))
+
+	m, err := s.svc.UpdateGreeterStatus(ctx, &greeter.UpdateGreeterStatusRequest{Id: 100, Status: 2, Version: 3})
+	require.Nil(s.T(), m)
+	require.Equal(s.T(), codes.Aborted, status.Code(err))
+	require.Equal(s.T(), constant.VersionConflict, statusx.Code(err))
+}
+
+func (s *Suite) TestGreeterService_UpdateGreeterStatusIllegal() {
+	ctx := context.Background()
+	transition := &service.StatusTransitionError{
+		From: greeter.GreeterStatus_GREETER_STATUS_DELETED,
+		To:   greeter.GreeterStatus_GREETER_STATUS_ACTIVE,
+	}
+	s.dmMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), greeter.GreeterStatus_GREETER_STATUS_ACTIVE, int32(0)).
+		Return(int64(0), errors.WithMessage(transition, "greeterDomain.UpdateGreeterStatus"))
+
+	_, err := s.svc.UpdateGreeterStatus(ctx, &greeter.UpdateGreeterStatusRequest{Id: 100, Status: greeter.GreeterStatus_GREETER_STATUS_ACTIVE})
+	st := status.Convert(err)
+	require.Equal(s.T(), codes.FailedPrecondition, st.Code())
+	require.Equal(s.T(), constant.StatusTransitionIllegal, statusx.Code(err))
+
+	var violations []*errdetails.PreconditionFailure_Violation
+	for _, detail := range st.Details() {
+		if pf, ok := detail.(*errdetails.PreconditionFailure); ok {
+			violations = pf.Violations
+		}
+	}
+	require.Len(s.T(), violations, 1)
+	require.Equal(s.T(), "greeter/100", violations[0].Subject)
+	require.Equal(s.T(), transition.Error(), violations[0].Description)
+}
+
+func (s *Suite) TestGreeterService_PurgeDeletedGreeters() {
+	ctx := context.Background()
+	s.dmMock.EXPECT().PurgeDeletedGreeters(ctx, time.Hour*24).Return(int64(5), nil)
+
+	m, err := s.svc.PurgeDeletedGreeters(ctx, &greeter.PurgeDeletedGreetersRequest{OlderThan: durationpb.New(time.Hour * 24)})
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 5, m.Purged)
+
+	_, err = s.svc.PurgeDeletedGreeters(ctx, &greeter.PurgeDeletedGreetersRequest{OlderThan: durationpb.New(-time.Hour)})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+
+	_, err = s.svc.PurgeDeletedGreeters(ctx, &greeter.PurgeDeletedGreetersRequest{})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+// TestGreeterService_MutateInvalidArgument 写操作的请求参数校验失败时不调用领域服务
+func (s *Suite) TestGreeterService_MutateInvalidArgument() {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		call  func() error
+		field string
+	}{
+		{"status-id", func() error {
+			_, err := s.svc.UpdateGreeterStatus(ctx, &greeter.UpdateGreeterStatusRequest{Status: 1})
+			return err
+		}, "id"},
+		{"status-status", func() error {
+			_, err := s.svc.UpdateGreeterStatus(ctx, &greeter.UpdateGreeterStatusRequest{Id: 100, Status: 9})
+			return err
+		}, "status"},
+		{"status-version", func() error {
+			_, err := s.svc.UpdateGreeterStatus(ctx, &greeter.UpdateGreeterStatusRequest{Id: 100, Status: 1, Version: -1})
+			return err
+		}, "version"},
+		{"count-version", func() error {
+			_, err := s.svc.UpdateGreeterCount(ctx, &greeter.UpdateGreeterCountRequest{Id: 100, Num: 1, Column: "view_num", Version: -1})
+			return err
+		}, "version"},
+		{"delete-id", func() error {
+			_, err := s.svc.DeleteGreeterById(ctx, &greeter.DeleteGreeterByIdRequest{})
+			return err
+		}, "id"},
+	}
+	for _, t := range tests {
+		s.Run(t.name, func() {
+			st := status.Convert(t.call())
+			require.Equal(s.T(), codes.InvalidArgument, st.Code())
+
+			var violations []*errdetails.BadRequest_FieldViolation
+			for _, detail := range st.Details() {
+				if br, ok := detail.(*errdetails.BadRequest); ok {
+					violations = br.FieldViolations
+				}
+			}
+			require.Len(s.T(), violations, 1)
+			require.Equal(s.T(), t.field, violations[0].Field)
+		})
+	}
+}
+
+func (s *Suite) TestGreeterService_GetGreeterListInvalidStatus() {
+	ctx := context.Background()
+	m, err := s.svc.GetGreeterList(ctx, &greeter.GetGreeterListRequest{Status: 9, Pagesize: 5})
+	require.Nil(s.T(), m)
+
+	st := status.Convert(err)
+	require.Equal(s.T(), codes.InvalidArgument, st.Code())
+	require.Equal(s.T(), constant.RequestIsInvalid, statusx.Code(err))
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = br.FieldViolations
+		}
+	}
+	require.Len(s.T(), violations, 1)
+	require.Equal(s.T(), "status", violations[0].Field)
+}
+
+func (s *Suite) TestGreeterService_GetGreeterListFilter() {
+	ctx := context.Background()
+	data := &greeter.GreeterList{Total: 1, TotalPage: 1, CurPage: 1, Datalist: []*greeter.Greeter{{Id: 100, Name: "koofox", ViewNum: 9, Status: 1}}}
+	s.dmMock.EXPECT().GetGreeterList(ctx, repository.GreeterListQuery{
+		Statuses:       []int32{1, 2},
+		NamePrefix:     "koo",
+		CreateTimeFrom: 1633000000000,
+		CreateTimeTo:   1634000000000,
+		MinViewNum:     5,
+		SortBy:         repository.GreeterSortByViewNum,
+		Ascending:      true,
+		PageSize:       5,
+		Page:           1,
+	}).Return(data, nil)
+
+	actual, err := s.svc.GetGreeterList(ctx, &greeter.GetGreeterListRequest{
+		Statuses:       []greeter.GreeterStatus{greeter.GreeterStatus_GREETER_STATUS_ACTIVE, greeter.GreeterStatus_GREETER_STATUS_DISABLED},
+		NamePrefix:     "koo",
+		CreateTimeFrom: 1633000000000,
+		CreateTimeTo:   1634000000000,
+		MinViewNum:     5,
+		SortBy:         greeter.GreeterSortField_GREETER_SORT_FIELD_VIEW_NUM,
+		Order:          greeter.SortOrder_SORT_ORDER_ASC,
+		Pagesize:       5,
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), data, actual.Data)
+
+	_, err = s.svc.GetGreeterList(ctx, &greeter.GetGreeterListRequest{Pagesize: 5, CreateTimeFrom: 1634000000000, CreateTimeTo: 1633000000000})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+
+	_, err = s.svc.GetGreeterList(ctx, &greeter.GetGreeterListRequest{Pagesize: 5, Statuses: []greeter.GreeterStatus{9}})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (s *Suite) TestGreeterService_CreateGreeterInvalidName() {
+	ctx := context.Background()
+	m, err := s.svc.CreateGreeter(ctx, &greeter.CreateGreeterRequest{Data: &greeter.Greeter{Name: "koofox", Status: 1}})
+	require.Nil(s.T(), m)
+
+	st := status.Convert(err)
+	require.Equal(s.T(), codes.InvalidArgument, st.Code())
+
+	var violations []*greeter.FieldViolation
+	for _, detail := range st.Details() {
+		if fv, ok := detail.(*greeter.FieldViolations); ok {
+			violations = fv.Violations
+		}
+	}
+	require.Len(s.T(), violations, 1)
+	require.Equal(s.T(), "data.name", violations[0].Field)
+	require.Equal(s.T(), "email", violations[0].Rule)
+	require.NotEmpty(s.T(), violations[0].Message)
+}
+
 func (s *Suite) TestGreeterService_GetGreeterList() {
 	tests := []struct {
 		name     string
@@ -72,7 +321,7 @@ func (s *Suite) TestGreeterService_GetGreeterList() {
 		data     *greeter.GreeterList
 		expected *greeter.GetGreeterListResponse
 	}{
-		{"status-1", 1, 0, 3, 1,
+		{"status-1", 1, 0, 5, 1,
 			&greeter.GreeterList{
 				Total:     5,
 				TotalPage: 2,
@@ -100,9 +349,9 @@ func (s *Suite) TestGreeterService_GetGreeterList() {
 	ctx := context.Background()
 	for _, t := range tests {
 		s.Run(t.name, func() {
-			s.dmMock.EXPECT().GetGreeterList(ctx, t.status, t.lastId, t.pageSize, t.page).Return(t.data, nil)
+			s.dmMock.EXPECT().GetGreeterList(ctx, repository.GreeterListQuery{Status: t.status, SortBy: repository.GreeterSortById, LastId: t.lastId, PageSize: t.pageSize, Page: t.page}).Return(t.data, nil)
 			actual, err := s.svc.GetGreeterList(ctx, &greeter.GetGreeterListRequest{
-				Status:   t.status,
+				Status:   greeter.GreeterStatus(t.status),
 				Lastid:   t.lastId,
 				Pagesize: t.pageSize,
 				Page:     t.page,
@@ -112,3 +361,40 @@ func (s *Suite) TestGreeterService_GetGreeterList() {
 		})
 	}
 }
+
+func (s *Suite) TestGreeterService_SearchGreeters() {
+	ctx := context.Background()
+	data := []*greeter.GreeterSearchResult{{Greeter: &greeter.Greeter{Id: 100, Name: "koofox@imind.tech"}, Score: 1, Highlight: "koofox@<em>imind</em>.tech"}}
+	s.dmMock.EXPECT().SearchGreeters(ctx, "imind", "", int32(0)).Return(data, int32(3), "next", nil)
+	m, err := s.svc.SearchGreeters(ctx, &greeter.SearchGreetersRequest{Query: " imind "})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), data, m.Data)
+	require.EqualValues(s.T(), 3, m.Total)
+	require.Equal(s.T(), "next", m.NextPageToken)
+
+	s.dmMock.EXPECT().SearchGreeters(ctx, "imind", "bad", int32(0)).Return(nil, int32(0), "", service.ErrInvalidPageToken)
+	_, err = s.svc.SearchGreeters(ctx, &greeter.SearchGreetersRequest{Query: "imind", PageToken: "bad"})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+	require.Equal(s.T(), constant.PageTokenIsInvalid, statusx.Code(err))
+
+	_, err = s.svc.SearchGreeters(ctx, &greeter.SearchGreetersRequest{Query: "  "})
+	require.Equal(s.T(), codes.InvalidArgument, status.Code(err))
+}
+
+func (s *Suite) TestGreeterService_UpdateGreeterCountNotAllowed() {
+	ctx := context.Background()
+	s.dmMock.EXPECT().UpdateGreeterCount(ctx, int32(100), int32(1), int32(0), "name").
+		Return(int64(0), errors.Wrap(service.ErrCounterNotAllowed, "greeterDomain.UpdateGreeterCount name"))
+
+	_, err := s.svc.UpdateGreeterCount(ctx, &greeter.UpdateGreeterCountRequest{Id: 100, Num: 1, Column: "name"})
+	st := status.Convert(err)
+	require.Equal(s.T(), codes.InvalidArgument, st.Code())
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			violations = br.FieldViolations
+		}
+	}
+	require.Len(s.T(), violations, 1)
+	require.Equal(s.T(), "column", violations[0].Field)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Maintain the greeter caches in Redis",
+}
+
+var (
+	cacheRepair    bool
+	cacheBatchSize int
+)
+
+// cacheVerifyCmd 以JSON输出每种不一致的数量和明细，--repair时同时修复
+var cacheVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Compare tbl_greeter with the greeter caches in Redis and report the drift as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := service.NewGreeterDomain().VerifyGreeterCache(context.Background(), cacheBatchSize, cacheRepair)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	},
+}
+
+func init() {
+	cacheVerifyCmd.Flags().BoolVar(&cacheRepair, "repair", false, "Repair the drifted cache entries")
+	cacheVerifyCmd.Flags().IntVar(&cacheBatchSize, "batch-size", constant.GreeterCacheVerifyBatchSize, "Number of greeters read from MySQL per batch")
+	cacheCmd.AddCommand(cacheVerifyCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
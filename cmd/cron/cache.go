@@ -0,0 +1,27 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+)
+
+// WarmGreeterCache 预热Greeter缓存，Redis故障切换后可以手动执行，进度输出到zap日志
+func (c Cron) WarmGreeterCache() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Println("创建日志失败", err)
+		return
+	}
+	defer logger.Sync()
+
+	if err := service.NewGreeterDomain().WarmGreeterCache(ctxzap.ToContext(context.Background(), logger)); err != nil {
+		log.Println("预热缓存失败", err)
+		return
+	}
+	log.Println("预热缓存完成")
+}
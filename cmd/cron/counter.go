@@ -0,0 +1,18 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+)
+
+// FlushGreeterCounters 将暂存的计数增量写入数据库，服务停止期间可以手动执行
+func (c Cron) FlushGreeterCounters() {
+	n, err := service.NewGreeterDomain().FlushGreeterCounters(context.Background())
+	if err != nil {
+		log.Println("写入计数增量失败", n, err)
+		return
+	}
+	log.Println("写入计数增量", n)
+}
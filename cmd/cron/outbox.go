@@ -0,0 +1,35 @@
+package cron
+
+import (
+	"context"
+	"log"
+
+	"github.com/spf13/viper"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// RelayGreeterOutbox 将发件箱中的事件发布到消息队列，服务停止期间可以手动执行
+func (c Cron) RelayGreeterOutbox() {
+	n, err := service.NewGreeterDomain().RelayGreeterOutbox(context.Background())
+	if err != nil {
+		log.Println("发布发件箱事件失败", n, err)
+		return
+	}
+	log.Println("发布发件箱事件", n)
+}
+
+// PurgeGreeterOutbox 删除发送时间超过outbox.retention的事件
+func (c Cron) PurgeGreeterOutbox() {
+	retention := viper.GetDuration("outbox.retention")
+	if retention <= 0 {
+		retention = constant.GreeterOutboxRetention
+	}
+	n, err := service.NewGreeterDomain().PurgeGreeterOutbox(context.Background(), retention)
+	if err != nil {
+		log.Println("删除已发送事件失败", err)
+		return
+	}
+	log.Println("删除已发送事件", n)
+}
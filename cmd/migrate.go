@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/persistence"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the greeter database schema with versioned migrations",
+}
+
+var (
+	migrateDryRun    bool
+	migrateUpSteps   int
+	migrateDownSteps int
+	migrateDir       string
+)
+
+// migrateUpCmd 执行未执行的迁移，--dry-run时只输出将要执行的SQL
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply the pending migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(true, migrateUpSteps)
+	},
+}
+
+// migrateDownCmd 回滚最近执行的迁移，默认回滚一个
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the most recently applied migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrate(false, migrateDownSteps)
+	},
+}
+
+// migrateStatusCmd 以JSON输出每个迁移是否已执行
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report which migrations have been applied as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		migrator, err := persistence.NewMigrator()
+		if err != nil {
+			return err
+		}
+		status, err := migrator.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	},
+}
+
+// migrateCreateCmd 为每种数据库创建下一个版本的空迁移文件
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create empty up and down migration files for every database driver",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := persistence.CreateMigration(migrateDir, args[0])
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		return err
+	},
+}
+
+func runMigrate(up bool, steps int) error {
+	ctx := context.Background()
+	migrator, err := persistence.NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	if migrateDryRun {
+		var plan []persistence.Migration
+		if up {
+			plan, err = migrator.Pending(ctx)
+		} else {
+			plan, err = migrator.Applied(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		if steps > 0 && len(plan) > steps {
+			plan = plan[:steps]
+		}
+		for _, m := range plan {
+			sql := m.Down
+			if up {
+				sql = m.Up
+			}
+			fmt.Printf("-- %04d_%s %s\n", m.Version, m.Name, direction)
+			for _, stmt := range persistence.Statements(sql) {
+				fmt.Printf("%s;\n", stmt)
+			}
+		}
+		return nil
+	}
+
+	var done []persistence.Migration
+	if up {
+		done, err = migrator.Up(ctx, steps)
+	} else {
+		done, err = migrator.Down(ctx, steps)
+	}
+	for _, m := range done {
+		fmt.Printf("%s %04d_%s\n", direction, m.Version, m.Name)
+	}
+	if err == nil && len(done) == 0 {
+		fmt.Println("no migrations to " + direction)
+	}
+	return err
+}
+
+func init() {
+	migrateUpCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the SQL instead of executing it")
+	migrateUpCmd.Flags().IntVar(&migrateUpSteps, "steps", 0, "Number of migrations to apply, 0 for all")
+	migrateDownCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print the SQL instead of executing it")
+	migrateDownCmd.Flags().IntVar(&migrateDownSteps, "steps", 1, "Number of migrations to roll back, 0 for all")
+	migrateCreateCmd.Flags().StringVar(&migrateDir, "dir", constant.MigrationDir, "Directory of the migration files")
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateCreateCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
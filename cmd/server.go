@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/imind-lab/greeter/pkg/constant"
 	"github.com/imind-lab/greeter/server"
 )
 
@@ -30,6 +31,8 @@ var serverCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "./conf/conf.yaml", "Start server with provided configuration file")
+	serverCmd.Flags().String("storage", constant.StorageMySQL, "Storage of the greeters: mysql, or memory to run without MySQL, Redis and Kafka")
+	viper.BindPFlag("storage", serverCmd.Flags().Lookup("storage"))
 	rootCmd.AddCommand(serverCmd)
 	cobra.OnInitialize(initConf)
 }
@@ -41,4 +44,5 @@ func initConf() {
 	if err != nil {
 		panic(fmt.Errorf("Fatal error config file: %s \n", err))
 	}
+	viper.SetDefault("db."+constant.DBName+".tablePrefix", constant.TablePrefix)
 }
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// GreeterAuditRepository Greeter变更记录的存储，只追加不修改
+type GreeterAuditRepository interface {
+	CreateGreeterAudit(ctx context.Context, m model.GreeterAudit) (model.GreeterAudit, error)
+	// FindGreeterAudits 按id倒序返回greeterId的变更记录，lastId大于0时只返回id小于lastId的记录
+	FindGreeterAudits(ctx context.Context, greeterId int32, lastId int64, pageSize int32) ([]model.GreeterAudit, error)
+}
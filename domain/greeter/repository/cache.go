@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+)
+
+// Greeter缓存与数据库不一致的类型
+const (
+	// GreeterCacheDriftStaleHash greeter_哈希表的字段与数据库不一致，或存在的Greeter缓存为空对象
+	GreeterCacheDriftStaleHash = "stale_hash"
+	// GreeterCacheDriftDeletedHash 已删除的Greeter仍缓存了非空的greeter_哈希表
+	GreeterCacheDriftDeletedHash = "deleted_hash"
+	// GreeterCacheDriftMissingMember 已缓存的greeter_ids_有序集合中缺少当前状态的Greeter
+	GreeterCacheDriftMissingMember = "missing_member"
+	// GreeterCacheDriftExtraMember greeter_ids_有序集合中包含其他状态、已删除或不存在的Greeter
+	GreeterCacheDriftExtraMember = "extra_member"
+)
+
+// GreeterCacheDrift 一处缓存与数据库不一致
+type GreeterCacheDrift struct {
+	Id   int32  `json:"id"`
+	Type string `json:"type"`
+	Key  string `json:"key"`
+	// Detail 不一致的字段等说明
+	Detail   string `json:"detail,omitempty"`
+	Repaired bool   `json:"repaired"`
+}
+
+// GreeterCacheReport 缓存一致性检查的结果，Counts为每种不一致类型的数量
+type GreeterCacheReport struct {
+	Checked  int                 `json:"checked"`
+	Counts   map[string]int      `json:"counts"`
+	Repaired int                 `json:"repaired"`
+	Drifts   []GreeterCacheDrift `json:"drifts"`
+}
+
+// Add 记录一处不一致
+func (r *GreeterCacheReport) Add(drift GreeterCacheDrift) {
+	if r.Counts == nil {
+		r.Counts = make(map[string]int)
+	}
+	r.Counts[drift.Type]++
+	if drift.Repaired {
+		r.Repaired++
+	}
+	r.Drifts = append(r.Drifts, drift)
+}
+
+// GreeterCacheVerifier 比较数据库与缓存中的Greeter
+type GreeterCacheVerifier interface {
+	// VerifyGreeterCache 按id分批遍历全部的Greeter（包括已删除的），比较数据库记录与缓存的哈希表和id有序集合，
+	// repair为true时修复不一致：删除哈希表由下次读取重建，有序集合中增删成员；检查期间的写入可能被报告为不一致
+	VerifyGreeterCache(ctx context.Context, batchSize int, repair bool) (GreeterCacheReport, error)
+}
+
+// GreeterCacheWarmer 从数据库预热Greeter缓存，用于Redis故障切换后避免大量请求同时未命中
+type GreeterCacheWarmer interface {
+	// WarmGreeterListIds 重建status对应的id有序集合和总数缓存，返回写入的id数量
+	WarmGreeterListIds(ctx context.Context, status int32) (int, error)
+	// WarmTopGreeters 将view_num最大的topN个Greeter写入哈希表缓存，返回写入的数量
+	WarmTopGreeters(ctx context.Context, topN int) (int, error)
+}
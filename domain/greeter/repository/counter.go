@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// ErrCounterNotAllowed 字段不在允许累加的计数字段中
+var ErrCounterNotAllowed = errors.New("greeter counter is not allowed")
+
+// greeterCounters 允许累加的计数字段，key为数据库列名，value将增量加到Greeter上
+var greeterCounters = map[string]func(m *model.Greeter, num int64){
+	"view_num": func(m *model.Greeter, num int64) {
+		m.ViewNum += int32(num)
+	},
+}
+
+// IsGreeterCounter column是否为允许累加的计数字段，只有通过校验的列名才能拼接到SQL中
+func IsGreeterCounter(column string) bool {
+	_, ok := greeterCounters[column]
+	return ok
+}
+
+// GreeterCounterDelta 一个Greeter尚未写入数据库的计数增量，key为计数字段
+type GreeterCounterDelta map[string]int64
+
+// Apply 将增量加到m上
+func (d GreeterCounterDelta) Apply(m *model.Greeter) {
+	for column, num := range d {
+		if apply, ok := greeterCounters[column]; ok {
+			apply(m, num)
+		}
+	}
+}
+
+// IsZero 全部字段的增量都为0
+func (d GreeterCounterDelta) IsZero() bool {
+	for _, num := range d {
+		if num != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GreeterCounterBatch 一次取出的一个Greeter的计数增量，Id在取出时生成，写入数据库时记录在model.Greeter.CounterBatch中，
+// 同一批增量再次写入时不会重复累加
+type GreeterCounterBatch struct {
+	Id    string
+	Delta GreeterCounterDelta
+}
+
+// GreeterCounterRepository 计数增量的暂存，增量先在暂存中累加，再定期批量写入数据库
+type GreeterCounterRepository interface {
+	// IncrGreeterCounter 累加id的column计数
+	IncrGreeterCounter(ctx context.Context, id int32, column string, num int32) error
+	// PendingGreeterCounters 返回list中各Greeter尚未计入的增量，正在写入的一批增量的Id与CounterBatch相同时已计入，不再返回；
+	// 没有增量的Greeter不在结果中
+	PendingGreeterCounters(ctx context.Context, list []model.Greeter) (map[int32]GreeterCounterDelta, error)
+	// FlushGreeterCounters 取出最多batchSize个Greeter的增量交给apply写入数据库，apply成功后删除这些增量，
+	// 失败时保留增量等待下次写入；多个实例同时调用时只有一个实例执行，返回写入的Greeter数量
+	FlushGreeterCounters(ctx context.Context, batchSize int, apply func(context.Context, map[int32]GreeterCounterBatch) error) (int, error)
+}
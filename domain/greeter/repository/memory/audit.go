@@ -0,0 +1,55 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+type greeterAuditRepository struct {
+	mu     sync.RWMutex
+	lastId int64
+	audits []model.GreeterAudit
+}
+
+// NewGreeterAuditRepository 创建内存中的变更记录仓库实例，用于测试和本地运行，进程退出后数据丢失
+func NewGreeterAuditRepository() repository.GreeterAuditRepository {
+	return &greeterAuditRepository{}
+}
+
+func (repo *greeterAuditRepository) CreateGreeterAudit(ctx context.Context, m model.GreeterAudit) (model.GreeterAudit, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.lastId++
+	m.Id = repo.lastId
+	m.Init(time.Now())
+	repo.audits = append(repo.audits, m)
+	return m, nil
+}
+
+func (repo *greeterAuditRepository) FindGreeterAudits(ctx context.Context, greeterId int32, lastId int64, pageSize int32) ([]model.GreeterAudit, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var list []model.GreeterAudit
+	// audits按id递增追加，倒序遍历即为id倒序
+	for i := len(repo.audits) - 1; i >= 0 && len(list) < int(pageSize); i-- {
+		m := repo.audits[i]
+		if m.GreeterId != greeterId || (lastId > 0 && m.Id >= lastId) {
+			continue
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
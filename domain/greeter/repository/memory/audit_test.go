@@ -0,0 +1,29 @@
+package memory
+
+import (
+	"context"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGreeterAuditRepository_FindGreeterAudits(t *testing.T) {
+	ctx := context.Background()
+	repo := NewGreeterAuditRepository()
+	for _, id := range []int32{100, 200, 100, 100} {
+		_, err := repo.CreateGreeterAudit(ctx, model.GreeterAudit{GreeterId: id, Actor: "koofox"})
+		require.NoError(t, err)
+	}
+
+	list, err := repo.FindGreeterAudits(ctx, 100, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.EqualValues(t, 4, list[0].Id)
+	require.EqualValues(t, 3, list[1].Id)
+	require.NotEmpty(t, list[0].CreateDatetime)
+
+	list, err = repo.FindGreeterAudits(ctx, 100, 3, 2)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.EqualValues(t, 1, list[0].Id)
+}
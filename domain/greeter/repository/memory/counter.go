@@ -0,0 +1,105 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+type greeterCounterRepository struct {
+	mu      sync.Mutex
+	pending map[int32]repository.GreeterCounterDelta
+	// batch 上一次写入的批次号，每次写入加1作为批次Id
+	batch int64
+}
+
+// NewGreeterCounterRepository 创建内存中的计数增量暂存实例，用于测试和本地运行
+func NewGreeterCounterRepository() repository.GreeterCounterRepository {
+	return &greeterCounterRepository{
+		pending: make(map[int32]repository.GreeterCounterDelta),
+	}
+}
+
+func (repo *greeterCounterRepository) IncrGreeterCounter(ctx context.Context, id int32, column string, num int32) error {
+	if !repository.IsGreeterCounter(column) {
+		return errors.Wrapf(repository.ErrCounterNotAllowed, "greeterCounterRepository.IncrGreeterCounter %s", column)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	delta, ok := repo.pending[id]
+	if !ok {
+		delta = make(repository.GreeterCounterDelta)
+		repo.pending[id] = delta
+	}
+	delta[column] += int64(num)
+	return nil
+}
+
+func (repo *greeterCounterRepository) PendingGreeterCounters(ctx context.Context, list []model.Greeter) (map[int32]repository.GreeterCounterDelta, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	pending := make(map[int32]repository.GreeterCounterDelta)
+	for _, m := range list {
+		if delta, ok := repo.pending[m.Id]; ok {
+			pending[m.Id] = copyDelta(delta)
+		}
+	}
+	return pending, nil
+}
+
+// FlushGreeterCounters 持有锁期间调用apply，同一实例中的写入与累加互斥，apply成功后才删除增量，
+// 因此不会有已写入但未删除的增量；apply失败时保留增量，下次使用新的批次Id写入
+func (repo *greeterCounterRepository) FlushGreeterCounters(ctx context.Context, batchSize int, apply func(context.Context, map[int32]repository.GreeterCounterBatch) error) (int, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	ids := make([]int32, 0, len(repo.pending))
+	for id := range repo.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > batchSize {
+		ids = ids[:batchSize]
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	repo.batch++
+	batch := strconv.FormatInt(repo.batch, 10)
+	batches := make(map[int32]repository.GreeterCounterBatch, len(ids))
+	for _, id := range ids {
+		batches[id] = repository.GreeterCounterBatch{Id: batch, Delta: copyDelta(repo.pending[id])}
+	}
+	if err := apply(ctx, batches); err != nil {
+		return 0, errors.WithMessage(err, "greeterCounterRepository.FlushGreeterCounters")
+	}
+	for _, id := range ids {
+		delete(repo.pending, id)
+	}
+	return len(ids), nil
+}
+
+func copyDelta(delta repository.GreeterCounterDelta) repository.GreeterCounterDelta {
+	c := make(repository.GreeterCounterDelta, len(delta))
+	for column, num := range delta {
+		c[column] = num
+	}
+	return c
+}
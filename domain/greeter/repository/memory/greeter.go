@@ -0,0 +1,388 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/micro/util"
+)
+
+// greeterColumns UpdateGreeter可以更新的列，key为数据库列名
+var greeterColumns = map[string]func(dst *model.Greeter, src model.Greeter){
+	"name":            func(dst *model.Greeter, src model.Greeter) { dst.Name = src.Name },
+	"view_num":        func(dst *model.Greeter, src model.Greeter) { dst.ViewNum = src.ViewNum },
+	"status":          func(dst *model.Greeter, src model.Greeter) { dst.Status = src.Status },
+	"create_time":     func(dst *model.Greeter, src model.Greeter) { dst.CreateTime = src.CreateTime },
+	"status_operator": func(dst *model.Greeter, src model.Greeter) { dst.StatusOperator = src.StatusOperator },
+	"status_datetime": func(dst *model.Greeter, src model.Greeter) { dst.StatusDatetime = src.StatusDatetime },
+}
+
+type greeterRepository struct {
+	mu       sync.RWMutex
+	lastId   int32
+	greeters map[int32]model.Greeter
+}
+
+// NewGreeterRepository 创建内存中的Greeter仓库实例，用于测试和本地运行，进程退出后数据丢失；
+// 排序、分页、软删除、版本号以及影响的行数与persistence的实现一致，没有缓存，Get和Find的结果相同
+func NewGreeterRepository() repository.GreeterRepository {
+	return &greeterRepository{
+		greeters: make(map[int32]model.Greeter),
+	}
+}
+
+func (repo *greeterRepository) CreateGreeter(ctx context.Context, m model.Greeter) (model.Greeter, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if m.Id == 0 {
+		m.Id = repo.lastId + 1
+	} else if _, ok := repo.greeters[m.Id]; ok {
+		return m, errors.Errorf("greeterRepository.CreateGreeter duplicate id %d", m.Id)
+	}
+	if m.Id > repo.lastId {
+		repo.lastId = m.Id
+	}
+	m.Init(time.Now())
+	repo.greeters[m.Id] = m
+	return m, nil
+}
+
+func (repo *greeterRepository) GetGreeterById(ctx context.Context, id int32, opt ...repository.GreeterByIdOption) (model.Greeter, error) {
+	return repo.FindGreeterById(ctx, id)
+}
+
+// FindGreeterById 不存在或已删除时返回空对象
+func (repo *greeterRepository) FindGreeterById(ctx context.Context, id int32) (model.Greeter, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	m, _ := repo.find(id, false)
+	return m, nil
+}
+
+// FindGreeterForUpdate 没有行锁，写入时由版本号检查并发修改
+func (repo *greeterRepository) FindGreeterForUpdate(ctx context.Context, id int32) (model.Greeter, error) {
+	return repo.FindGreeterById(ctx, id)
+}
+
+func (repo *greeterRepository) GetGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, []int32, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	greeters := make([]model.Greeter, 0, len(ids))
+	var notFound []int32
+	for _, id := range ids {
+		if m, ok := repo.find(id, false); ok {
+			greeters = append(greeters, m)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return greeters, notFound, nil
+}
+
+// FindGreetersByIds 结果按id递增排列，重复的id只返回一次
+func (repo *greeterRepository) FindGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var list []model.Greeter
+	seen := make(map[int32]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if m, ok := repo.find(id, false); ok {
+			list = append(list, m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+	return list, nil
+}
+
+func (repo *greeterRepository) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32, opt ...repository.GreeterListOption) ([]model.Greeter, int, []repository.GreeterFailure, error) {
+	opts := repository.NewGreeterListOptions()
+	for _, o := range opt {
+		o(opts)
+	}
+
+	var after *repository.GreeterListAfter
+	if lastId > 0 {
+		after = &repository.GreeterListAfter{Id: lastId}
+	}
+	filter := repository.GreeterListFilter{Statuses: []int32{status}, IncludeDeleted: opts.WithDeleted, SortBy: repository.GreeterSortById}
+	list, cnt, err := repo.FindGreeterList(ctx, filter, after, pageSize, page)
+	if err != nil {
+		return nil, 0, nil, errors.WithMessage(err, "greeterRepository.GetGreeterList")
+	}
+	return list, cnt, nil, nil
+}
+
+// FindGreeterList 名称的匹配不区分大小写，与MySQL默认的排序规则一致
+func (repo *greeterRepository) FindGreeterList(ctx context.Context, filter repository.GreeterListFilter, after *repository.GreeterListAfter, pageSize, page int32) ([]model.Greeter, int, error) {
+	value, ok := greeterSortValues[filter.SortBy]
+	if !ok {
+		return nil, 0, errors.Errorf("greeterRepository.FindGreeterList unknown sort field %q", filter.SortBy)
+	}
+	less := func(a, b model.Greeter) bool {
+		va, vb := value(a), value(b)
+		if va != vb {
+			return va < vb
+		}
+		return a.Id < b.Id
+	}
+	// before 按排序方向a在b之前
+	before := func(a, b model.Greeter) bool {
+		if filter.Ascending {
+			return less(a, b)
+		}
+		return less(b, a)
+	}
+
+	repo.mu.RLock()
+	matched := make([]model.Greeter, 0, len(repo.greeters))
+	for _, m := range repo.greeters {
+		if matchGreeter(m, filter) {
+			matched = append(matched, m)
+		}
+	}
+	repo.mu.RUnlock()
+	sort.Slice(matched, func(i, j int) bool { return before(matched[i], matched[j]) })
+
+	list := matched
+	if after != nil {
+		pivot := greeterAt(filter.SortBy, after)
+		list = matched[sort.Search(len(matched), func(i int) bool { return before(pivot, matched[i]) }):]
+	} else if page > 1 {
+		offset := int((page - 1) * pageSize)
+		if offset > len(list) {
+			offset = len(list)
+		}
+		list = list[offset:]
+	}
+	if pageSize > 0 && len(list) > int(pageSize) {
+		list = list[:pageSize]
+	}
+	return append([]model.Greeter{}, list...), len(matched), nil
+}
+
+// UpdateGreeter 只更新columns指定的列，m.Version大于0时作为期望的版本号
+func (repo *greeterRepository) UpdateGreeter(ctx context.Context, m model.Greeter, columns []string) (int64, error) {
+	for _, column := range columns {
+		if _, ok := greeterColumns[column]; !ok {
+			return 0, errors.Errorf("greeterRepository.UpdateGreeter unknown column %s", column)
+		}
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.update(m.Id, m.Version, func(cur *model.Greeter) {
+		for _, column := range columns {
+			greeterColumns[column](cur, m)
+		}
+	})
+}
+
+func (repo *greeterRepository) UpdateGreeterStatus(ctx context.Context, id, status, version int32, operator string) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.update(id, version, func(cur *model.Greeter) {
+		cur.Status = status
+		cur.StatusOperator = operator
+		cur.StatusDatetime = time.Now().Format(util.DateTimeFmt)
+	})
+}
+
+func (repo *greeterRepository) UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error) {
+	if !repository.IsGreeterCounter(column) {
+		return 0, errors.Wrapf(repository.ErrCounterNotAllowed, "greeterRepository.UpdateGreeterCount %s", column)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	return repo.update(id, version, func(cur *model.Greeter) {
+		repository.GreeterCounterDelta{column: int64(num)}.Apply(cur)
+	})
+}
+
+// AddGreeterCounters 全部字段校验通过后才写入，增量不全为0时版本号加1并记录批次Id，不修改更新时间；
+// 已删除的Greeter和CounterBatch已是这一批的Greeter不累加
+func (repo *greeterRepository) AddGreeterCounters(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+	for _, batch := range batches {
+		for column := range batch.Delta {
+			if !repository.IsGreeterCounter(column) {
+				return errors.Wrapf(repository.ErrCounterNotAllowed, "greeterRepository.AddGreeterCounters %s", column)
+			}
+		}
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for id, batch := range batches {
+		if m, ok := repo.find(id, false); ok && m.CounterBatch != batch.Id && !batch.Delta.IsZero() {
+			batch.Delta.Apply(&m)
+			m.CounterBatch = batch.Id
+			m.Version++
+			repo.greeters[id] = m
+		}
+	}
+	return nil
+}
+
+// DeleteGreeterById 软删除并递增版本号
+func (repo *greeterRepository) DeleteGreeterById(ctx context.Context, id, version int32) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	m, ok := repo.find(id, false)
+	if !ok {
+		return 0, nil
+	}
+	if version > 0 && m.Version != version {
+		return 0, versionConflict(id, version, m.Version)
+	}
+	m.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	m.Version++
+	repo.greeters[id] = m
+	return 1, nil
+}
+
+// RestoreGreeter 恢复已软删除的Greeter，记录未被删除时affected为0
+func (repo *greeterRepository) RestoreGreeter(ctx context.Context, id, version int32) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	m, ok := repo.find(id, true)
+	if !ok {
+		return 0, nil
+	}
+	if version > 0 && m.Version != version {
+		return 0, versionConflict(id, version, m.Version)
+	}
+	if !m.DeletedAt.Valid {
+		return 0, nil
+	}
+	m.DeletedAt = gorm.DeletedAt{}
+	m.Version++
+	repo.greeters[id] = m
+	return 1, nil
+}
+
+func (repo *greeterRepository) PurgeDeletedGreeters(ctx context.Context, before time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var affected int64
+	for id, m := range repo.greeters {
+		if m.DeletedAt.Valid && m.DeletedAt.Time.Before(before) {
+			delete(repo.greeters, id)
+			affected++
+		}
+	}
+	return affected, nil
+}
+
+// Transaction 内存中的仓库没有事务，fn返回错误时已执行的修改不会回滚
+func (repo *greeterRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// find withDeleted为true时包含已软删除的记录，调用方需要持有锁
+func (repo *greeterRepository) find(id int32, withDeleted bool) (model.Greeter, bool) {
+	m, ok := repo.greeters[id]
+	if !ok || (!withDeleted && m.DeletedAt.Valid) {
+		return model.Greeter{}, false
+	}
+	return m, true
+}
+
+// update 修改未删除的Greeter并递增版本号，version大于0时作为期望的版本号，调用方需要持有写锁
+func (repo *greeterRepository) update(id, version int32, fn func(*model.Greeter)) (int64, error) {
+	m, ok := repo.find(id, false)
+	if !ok {
+		return 0, nil
+	}
+	if version > 0 && m.Version != version {
+		return 0, versionConflict(id, version, m.Version)
+	}
+	fn(&m)
+	m.Version++
+	m.UpdateDatetime = time.Now().Format(util.DateTimeFmt)
+	repo.greeters[id] = m
+	return 1, nil
+}
+
+func versionConflict(id, expect, actual int32) error {
+	return errors.Wrapf(repository.ErrVersionConflict, "greeter %d expect version %d, actual %d", id, expect, actual)
+}
+
+// greeterSortValues 排序字段的取值
+var greeterSortValues = map[repository.GreeterSortField]func(m model.Greeter) int64{
+	repository.GreeterSortById:         func(m model.Greeter) int64 { return int64(m.Id) },
+	repository.GreeterSortByViewNum:    func(m model.Greeter) int64 { return int64(m.ViewNum) },
+	repository.GreeterSortByCreateTime: func(m model.Greeter) int64 { return m.CreateTime },
+}
+
+// greeterAt keyset分页位置对应的Greeter，只包含排序字段和id
+func greeterAt(field repository.GreeterSortField, after *repository.GreeterListAfter) model.Greeter {
+	m := model.Greeter{Id: after.Id}
+	switch field {
+	case repository.GreeterSortByViewNum:
+		m.ViewNum = int32(after.Value)
+	case repository.GreeterSortByCreateTime:
+		m.CreateTime = after.Value
+	}
+	return m
+}
+
+// matchGreeter m是否满足filter中的过滤条件
+func matchGreeter(m model.Greeter, filter repository.GreeterListFilter) bool {
+	if m.DeletedAt.Valid && !filter.IncludeDeleted {
+		return false
+	}
+	matched := false
+	for _, status := range filter.Statuses {
+		if m.Status == status {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	name := strings.ToLower(m.Name)
+	if filter.NamePrefix != "" && !strings.HasPrefix(name, strings.ToLower(filter.NamePrefix)) {
+		return false
+	}
+	if filter.NameContains != "" && !strings.Contains(name, strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if filter.CreateTimeFrom > 0 && m.CreateTime < filter.CreateTimeFrom {
+		return false
+	}
+	if filter.CreateTimeTo > 0 && m.CreateTime >= filter.CreateTimeTo {
+		return false
+	}
+	return filter.MinViewNum <= 0 || m.ViewNum >= filter.MinViewNum
+}
@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/repositorytest"
+)
+
+func TestGreeterRepository_Conformance(t *testing.T) {
+	suite.Run(t, &repositorytest.GreeterRepositorySuite{NewRepo: NewGreeterRepository})
+}
@@ -0,0 +1,151 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+type greeterOutboxRepository struct {
+	mu     sync.RWMutex
+	lastId int64
+	// outbox 按id递增追加
+	outbox []model.GreeterOutbox
+	// relayMu 同一时刻只有一个调用方在中继
+	relayMu sync.Mutex
+}
+
+// NewGreeterOutboxRepository 创建内存中的事件发件箱实例，用于测试和本地运行，进程退出后数据丢失
+func NewGreeterOutboxRepository() repository.GreeterOutboxRepository {
+	return &greeterOutboxRepository{}
+}
+
+func (repo *greeterOutboxRepository) CreateGreeterOutbox(ctx context.Context, m model.GreeterOutbox) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.lastId++
+	m.Id = repo.lastId
+	m.Init(time.Now())
+	repo.outbox = append(repo.outbox, m)
+	return nil
+}
+
+func (repo *greeterOutboxRepository) RelayGreeterOutbox(ctx context.Context, batchSize int, relay func(context.Context, []model.GreeterOutbox) error) (int, error) {
+	repo.relayMu.Lock()
+	defer repo.relayMu.Unlock()
+
+	repo.mu.RLock()
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	waiting := make(map[string]bool)
+	for _, m := range repo.outbox {
+		if pending(m) && m.NextAttemptTime > now {
+			waiting[m.MessageKey] = true
+		}
+	}
+	var list []model.GreeterOutbox
+	for _, m := range repo.outbox {
+		if len(list) >= batchSize {
+			break
+		}
+		if pending(m) && !waiting[m.MessageKey] {
+			list = append(list, m)
+		}
+	}
+	repo.mu.RUnlock()
+
+	if len(list) == 0 {
+		return 0, nil
+	}
+	return len(list), relay(ctx, list)
+}
+
+func (repo *greeterOutboxRepository) MarkGreeterOutboxSent(ctx context.Context, id int64) error {
+	repo.update(id, func(m *model.GreeterOutbox) {
+		m.SentTime = time.Now().UnixNano() / int64(time.Millisecond)
+	})
+	return nil
+}
+
+func (repo *greeterOutboxRepository) MarkGreeterOutboxFailed(ctx context.Context, id int64, next time.Time, lastError string) error {
+	repo.update(id, func(m *model.GreeterOutbox) {
+		m.Attempts++
+		m.NextAttemptTime = next.UnixNano() / int64(time.Millisecond)
+		m.LastError = lastError
+	})
+	return nil
+}
+
+func (repo *greeterOutboxRepository) MarkGreeterOutboxDead(ctx context.Context, id int64, lastError string) error {
+	repo.update(id, func(m *model.GreeterOutbox) {
+		m.Attempts++
+		m.DeadTime = time.Now().UnixNano() / int64(time.Millisecond)
+		m.LastError = lastError
+	})
+	return nil
+}
+
+func (repo *greeterOutboxRepository) CountPendingGreeterOutbox(ctx context.Context) (int64, error) {
+	return repo.count(pending), nil
+}
+
+func (repo *greeterOutboxRepository) CountDeadGreeterOutbox(ctx context.Context) (int64, error) {
+	return repo.count(func(m model.GreeterOutbox) bool { return m.DeadTime > 0 }), nil
+}
+
+func (repo *greeterOutboxRepository) count(fn func(model.GreeterOutbox) bool) int64 {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	var count int64
+	for _, m := range repo.outbox {
+		if fn(m) {
+			count++
+		}
+	}
+	return count
+}
+
+func (repo *greeterOutboxRepository) PurgeSentGreeterOutbox(ctx context.Context, before time.Time) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	ms := before.UnixNano() / int64(time.Millisecond)
+	kept := repo.outbox[:0]
+	for _, m := range repo.outbox {
+		if m.SentTime == 0 || m.SentTime >= ms {
+			kept = append(kept, m)
+		}
+	}
+	affected := int64(len(repo.outbox) - len(kept))
+	repo.outbox = kept
+	return affected, nil
+}
+
+// pending 未发送且未进入死信
+func pending(m model.GreeterOutbox) bool {
+	return m.SentTime == 0 && m.DeadTime == 0
+}
+
+// update 修改未发送的事件，已发送、已进入死信或不存在时忽略
+func (repo *greeterOutboxRepository) update(id int64, fn func(*model.GreeterOutbox)) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for i := range repo.outbox {
+		if repo.outbox[i].Id == id && pending(repo.outbox[i]) {
+			fn(&repo.outbox[i])
+			return
+		}
+	}
+}
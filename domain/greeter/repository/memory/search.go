@@ -0,0 +1,158 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// searchGramSize 倒排索引中词项的长度，query短于该长度时遍历全部的名称
+const searchGramSize = 3
+
+type greeterSearchIndex struct {
+	mu sync.RWMutex
+	// names id对应的小写名称
+	names map[int32]string
+	// grams 倒排索引，key为名称中长度为searchGramSize的子串
+	grams map[string]map[int32]struct{}
+}
+
+// NewGreeterSearchIndex 创建内存中基于n-gram倒排索引的搜索索引实例，用于测试和本地运行
+func NewGreeterSearchIndex() repository.GreeterSearchIndex {
+	return &greeterSearchIndex{
+		names: make(map[int32]string),
+		grams: make(map[string]map[int32]struct{}),
+	}
+}
+
+func (idx *greeterSearchIndex) IndexGreeter(ctx context.Context, m model.Greeter) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(m.Id)
+	name := strings.ToLower(m.Name)
+	idx.names[m.Id] = name
+	for _, gram := range searchGrams(name) {
+		ids, ok := idx.grams[gram]
+		if !ok {
+			ids = make(map[int32]struct{})
+			idx.grams[gram] = ids
+		}
+		ids[m.Id] = struct{}{}
+	}
+	return nil
+}
+
+func (idx *greeterSearchIndex) RemoveGreeter(ctx context.Context, id int32) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+	return nil
+}
+
+func (idx *greeterSearchIndex) remove(id int32) {
+	name, ok := idx.names[id]
+	if !ok {
+		return
+	}
+	for _, gram := range searchGrams(name) {
+		delete(idx.grams[gram], id)
+		if len(idx.grams[gram]) == 0 {
+			delete(idx.grams, gram)
+		}
+	}
+	delete(idx.names, id)
+}
+
+func (idx *greeterSearchIndex) SearchGreeters(ctx context.Context, query string, offset, limit int32) ([]repository.GreeterSearchHit, int, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	var hits []repository.GreeterSearchHit
+	for id := range idx.candidates(query) {
+		name := idx.names[id]
+		switch {
+		case name == query:
+			hits = append(hits, repository.GreeterSearchHit{Id: id, Score: repository.GreeterSearchScoreExact})
+		case strings.HasPrefix(name, query):
+			hits = append(hits, repository.GreeterSearchHit{Id: id, Score: repository.GreeterSearchScorePrefix})
+		case strings.Contains(name, query):
+			hits = append(hits, repository.GreeterSearchHit{Id: id, Score: repository.GreeterSearchScoreContains})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Id > hits[j].Id
+	})
+
+	total := len(hits)
+	if int(offset) >= total {
+		return []repository.GreeterSearchHit{}, total, nil
+	}
+	hits = hits[offset:]
+	if len(hits) > int(limit) {
+		hits = hits[:limit]
+	}
+	return hits, total, nil
+}
+
+// candidates 返回包含query全部词项的id，还需要进一步确认名称包含query
+func (idx *greeterSearchIndex) candidates(query string) map[int32]struct{} {
+	grams := searchGrams(query)
+	if len(grams) == 0 {
+		all := make(map[int32]struct{}, len(idx.names))
+		for id := range idx.names {
+			all[id] = struct{}{}
+		}
+		return all
+	}
+
+	// 从最短的倒排列表开始求交集
+	sort.Slice(grams, func(i, j int) bool {
+		return len(idx.grams[grams[i]]) < len(idx.grams[grams[j]])
+	})
+	result := make(map[int32]struct{}, len(idx.grams[grams[0]]))
+	for id := range idx.grams[grams[0]] {
+		result[id] = struct{}{}
+	}
+	for _, gram := range grams[1:] {
+		ids := idx.grams[gram]
+		for id := range result {
+			if _, ok := ids[id]; !ok {
+				delete(result, id)
+			}
+		}
+	}
+	return result
+}
+
+// searchGrams 返回s中长度为searchGramSize的不重复子串，按字符而不是字节切分
+func searchGrams(s string) []string {
+	runes := []rune(s)
+	seen := make(map[string]struct{})
+	var grams []string
+	for i := 0; i+searchGramSize <= len(runes); i++ {
+		gram := string(runes[i : i+searchGramSize])
+		if _, ok := seen[gram]; ok {
+			continue
+		}
+		seen[gram] = struct{}{}
+		grams = append(grams, gram)
+	}
+	return grams
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGreeterSearchIndex_SearchGreeters(t *testing.T) {
+	ctx := context.Background()
+	idx := NewGreeterSearchIndex()
+	for _, m := range []model.Greeter{
+		{Id: 100, Name: "koofox@imind.tech"},
+		{Id: 200, Name: "Imind.tech@example.com"},
+		{Id: 300, Name: "songli@imind.tech"},
+		{Id: 400, Name: "imind.tech"},
+		{Id: 500, Name: "koofox@example.com"},
+	} {
+		require.NoError(t, idx.IndexGreeter(ctx, m))
+	}
+
+	hits, total, err := idx.SearchGreeters(ctx, "IMIND.tech", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+	require.Equal(t, []repository.GreeterSearchHit{
+		{Id: 400, Score: repository.GreeterSearchScoreExact},
+		{Id: 200, Score: repository.GreeterSearchScorePrefix},
+		{Id: 300, Score: repository.GreeterSearchScoreContains},
+		{Id: 100, Score: repository.GreeterSearchScoreContains},
+	}, hits)
+
+	hits, total, err = idx.SearchGreeters(ctx, "imind.tech", 2, 10)
+	require.NoError(t, err)
+	require.Equal(t, 4, total)
+	require.Len(t, hits, 2)
+	require.EqualValues(t, 300, hits[0].Id)
+
+	// 短于词项长度的query遍历全部名称
+	hits, total, err = idx.SearchGreeters(ctx, "@e", 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Equal(t, []repository.GreeterSearchHit{{Id: 500, Score: repository.GreeterSearchScoreContains}}, hits)
+
+	// 更新后旧名称不再命中
+	require.NoError(t, idx.IndexGreeter(ctx, model.Greeter{Id: 100, Name: "koofox@example.com"}))
+	require.NoError(t, idx.RemoveGreeter(ctx, 400))
+	hits, total, err = idx.SearchGreeters(ctx, "imind.tech", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.EqualValues(t, 200, hits[0].Id)
+	require.EqualValues(t, 300, hits[1].Id)
+
+	hits, total, err = idx.SearchGreeters(ctx, "nobody", 0, 10)
+	require.NoError(t, err)
+	require.Zero(t, total)
+	require.Empty(t, hits)
+}
@@ -0,0 +1,42 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GreeterAudit Greeter的变更记录，Before和After为变更前后Greeter的JSON
+type GreeterAudit struct {
+	Id             int64 `gorm:"primary_key"`
+	GreeterId      int32
+	Action         int32
+	Actor          string
+	RequestId      string
+	Before         string
+	After          string
+	CreateTime     int64
+	CreateDatetime string
+}
+
+func (m *GreeterAudit) BeforeCreate(tx *gorm.DB) error {
+	m.Init(time.Now())
+	return nil
+}
+
+// Init 补全创建时间，非gorm的实现需要在写入前调用
+func (m *GreeterAudit) Init(now time.Time) {
+	if m.CreateTime == 0 {
+		m.CreateTime = now.UnixNano() / int64(time.Millisecond)
+	}
+	if m.CreateDatetime == "" {
+		m.CreateDatetime = now.Format("2006-01-02 15:04:05")
+	}
+}
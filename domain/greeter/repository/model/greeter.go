@@ -21,20 +21,37 @@ type Greeter struct {
 	CreateTime     int64  `redis:"create_time,omitempty"`
 	CreateDatetime string `redis:"create_datetime,omitempty"`
 	UpdateDatetime string `redis:"update_datetime,omitempty"`
-}
-
-func (Greeter) TableName() string {
-	return "tbl_greeter"
+	Version        int32  `redis:"version,omitempty"`
+	// StatusOperator、StatusDatetime 最近一次状态变更的操作人和时间
+	StatusOperator string `redis:"status_operator,omitempty"`
+	StatusDatetime string `redis:"status_datetime,omitempty"`
+	// CounterBatch 最近一次写入的计数增量批次，与计数在同一个事务中写入，用于识别已写入但尚未从暂存中删除的增量
+	CounterBatch string `redis:"counter_batch,omitempty"`
+	// DeletedAt 软删除时间，已删除的记录不会写入缓存
+	DeletedAt gorm.DeletedAt `redis:"-"`
 }
 
 func (m *Greeter) BeforeCreate(tx *gorm.DB) error {
-	m.CreateDatetime = time.Now().Format("2006-01-02 15:04:05")
-	m.UpdateDatetime = time.Now().Format("2006-01-02 15:04:05")
+	m.Init(time.Now())
 	return nil
 }
 
+// Init 设置创建和更新时间，补全初始版本号和状态变更时间，非gorm的实现需要在写入前调用
+func (m *Greeter) Init(now time.Time) {
+	m.CreateDatetime = now.Format("2006-01-02 15:04:05")
+	m.UpdateDatetime = now.Format("2006-01-02 15:04:05")
+	if m.Version == 0 {
+		m.Version = 1
+	}
+	if m.StatusDatetime == "" {
+		m.StatusDatetime = m.CreateDatetime
+	}
+}
+
+// BeforeUpdate 通过SetColumn写入update_datetime，使按map更新时同样生效
 func (m *Greeter) BeforeUpdate(tx *gorm.DB) error {
 	m.UpdateDatetime = time.Now().Format("2006-01-02 15:04:05")
+	tx.Statement.SetColumn("update_datetime", m.UpdateDatetime)
 	return nil
 }
 
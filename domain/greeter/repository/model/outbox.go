@@ -0,0 +1,49 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GreeterOutbox 待发布到消息队列的事件，与Greeter的变更在同一个事务中写入；
+// SentTime为0表示未发送，发布失败时Attempts加一，NextAttemptTime之前不再发布；
+// 失败次数达到上限后DeadTime记录进入死信的时间，不再发布，也不阻塞同一key后面的事件
+type GreeterOutbox struct {
+	Id              int64 `gorm:"primary_key"`
+	Topic           string
+	MessageKey      string
+	Body            []byte
+	Attempts        int32
+	NextAttemptTime int64
+	LastError       string
+	SentTime        int64
+	DeadTime        int64
+	CreateTime      int64
+	CreateDatetime  string
+}
+
+func (m *GreeterOutbox) BeforeCreate(tx *gorm.DB) error {
+	m.Init(time.Now())
+	return nil
+}
+
+// Init 补全创建时间，Body为nil时改为空，非gorm的实现需要在写入前调用
+func (m *GreeterOutbox) Init(now time.Time) {
+	if m.Body == nil {
+		m.Body = []byte{}
+	}
+	if m.CreateTime == 0 {
+		m.CreateTime = now.UnixNano() / int64(time.Millisecond)
+	}
+	if m.CreateDatetime == "" {
+		m.CreateDatetime = now.Format("2006-01-02 15:04:05")
+	}
+}
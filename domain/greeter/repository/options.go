@@ -19,3 +19,104 @@ func GreeterByIdRandExpire(expire time.Duration) GreeterByIdOption {
 		o.RandExpire = expire
 	}
 }
+
+type GreeterListOptions struct {
+	WithDeleted bool
+}
+
+func NewGreeterListOptions() *GreeterListOptions {
+	return &GreeterListOptions{}
+}
+
+type GreeterListOption func(*GreeterListOptions)
+
+// GreeterListWithDeleted 列表中包含已软删除的记录，直接查询数据库
+func GreeterListWithDeleted(withDeleted bool) GreeterListOption {
+	return func(o *GreeterListOptions) {
+		o.WithDeleted = withDeleted
+	}
+}
+
+// GreeterListQuery Greeter列表的查询条件
+type GreeterListQuery struct {
+	Status int32
+	// Statuses 按多个状态过滤，不为空时忽略Status
+	Statuses       []int32
+	IncludeDeleted bool
+	NamePrefix     string
+	NameContains   string
+	// CreateTimeFrom、CreateTimeTo 创建时间范围[CreateTimeFrom, CreateTimeTo)，为0时不限制
+	CreateTimeFrom int64
+	CreateTimeTo   int64
+	MinViewNum     int32
+	SortBy         GreeterSortField
+	Ascending      bool
+
+	PageSize int32
+	// PageToken 上一页返回的NextPageToken，不为空时忽略LastId和Page
+	PageToken string
+
+	// Deprecated: LastId、Page 兼容旧的分页参数，使用PageToken
+	LastId int32
+	Page   int32
+}
+
+// Filter 返回查询中的过滤和排序条件，Statuses为空时使用Status
+func (q GreeterListQuery) Filter() GreeterListFilter {
+	statuses := q.Statuses
+	if len(statuses) == 0 {
+		statuses = []int32{q.Status}
+	}
+	sortBy := q.SortBy
+	if sortBy == "" {
+		sortBy = GreeterSortById
+	}
+	return GreeterListFilter{
+		Statuses:       statuses,
+		IncludeDeleted: q.IncludeDeleted,
+		NamePrefix:     q.NamePrefix,
+		NameContains:   q.NameContains,
+		CreateTimeFrom: q.CreateTimeFrom,
+		CreateTimeTo:   q.CreateTimeTo,
+		MinViewNum:     q.MinViewNum,
+		SortBy:         sortBy,
+		Ascending:      q.Ascending,
+	}
+}
+
+// GreeterSortField 列表的排序字段，值为数据库列名
+type GreeterSortField string
+
+const (
+	GreeterSortById         GreeterSortField = "id"
+	GreeterSortByViewNum    GreeterSortField = "view_num"
+	GreeterSortByCreateTime GreeterSortField = "create_time"
+)
+
+// GreeterListFilter Greeter列表的过滤和排序条件，排序字段相同时按id以相同方向排序
+type GreeterListFilter struct {
+	Statuses       []int32          `json:"s"`
+	IncludeDeleted bool             `json:"d,omitempty"`
+	NamePrefix     string           `json:"np,omitempty"`
+	NameContains   string           `json:"nc,omitempty"`
+	CreateTimeFrom int64            `json:"cf,omitempty"`
+	CreateTimeTo   int64            `json:"ct,omitempty"`
+	MinViewNum     int32            `json:"mv,omitempty"`
+	SortBy         GreeterSortField `json:"sb,omitempty"`
+	Ascending      bool             `json:"a,omitempty"`
+}
+
+// Cacheable 只有单个状态、按id倒序且没有其他过滤条件时，才能使用按状态缓存的greeter_ids_有序集合，
+// 其他组合（包括按view_num排序，其值变化时不会更新有序集合）需要查询数据库
+func (f GreeterListFilter) Cacheable() bool {
+	return len(f.Statuses) == 1 && !f.IncludeDeleted &&
+		f.NamePrefix == "" && f.NameContains == "" &&
+		f.CreateTimeFrom == 0 && f.CreateTimeTo == 0 && f.MinViewNum == 0 &&
+		(f.SortBy == "" || f.SortBy == GreeterSortById) && !f.Ascending
+}
+
+// GreeterListAfter keyset分页的位置，即上一页最后一条记录的排序字段值和id
+type GreeterListAfter struct {
+	Value int64
+	Id    int32
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// GreeterOutboxRepository 事件发件箱，事件在GreeterRepository.Transaction中与Greeter的变更一起写入，
+// 再由中继按写入顺序发布到消息队列
+type GreeterOutboxRepository interface {
+	// CreateGreeterOutbox 写入待发布的事件，在Transaction中调用时与变更一起提交或回滚
+	CreateGreeterOutbox(ctx context.Context, m model.GreeterOutbox) error
+	// RelayGreeterOutbox 按id顺序取出最多batchSize个可以发布的事件交给relay：未发送、未进入死信，
+	// 并且同一key没有未到下次发布时间的事件，等待重试的key不影响其他key；relay通过MarkGreeterOutboxSent、
+	// MarkGreeterOutboxFailed和MarkGreeterOutboxDead记录结果；多个实例同时调用时只有一个实例执行，返回取出的数量
+	RelayGreeterOutbox(ctx context.Context, batchSize int, relay func(context.Context, []model.GreeterOutbox) error) (int, error)
+	MarkGreeterOutboxSent(ctx context.Context, id int64) error
+	// MarkGreeterOutboxFailed Attempts加一，next之前不再发布
+	MarkGreeterOutboxFailed(ctx context.Context, id int64, next time.Time, lastError string) error
+	// MarkGreeterOutboxDead Attempts加一并进入死信，不再发布
+	MarkGreeterOutboxDead(ctx context.Context, id int64, lastError string) error
+	// CountPendingGreeterOutbox 未发送且未进入死信的事件数量
+	CountPendingGreeterOutbox(ctx context.Context) (int64, error)
+	// CountDeadGreeterOutbox 死信中的事件数量
+	CountDeadGreeterOutbox(ctx context.Context) (int64, error)
+	// PurgeSentGreeterOutbox 删除发送时间早于before的事件，死信中的事件保留用于排查
+	PurgeSentGreeterOutbox(ctx context.Context, before time.Time) (int64, error)
+}
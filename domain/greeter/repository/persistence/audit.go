@@ -0,0 +1,64 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+
+	errorsx "github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/micro/dao"
+	"github.com/imind-lab/micro/tracing"
+)
+
+type greeterAuditRepository struct {
+	dao.Dao
+}
+
+// NewGreeterAuditRepository 创建基于数据库的变更记录仓库实例
+func NewGreeterAuditRepository() repository.GreeterAuditRepository {
+	rep := newDao()
+	repo := greeterAuditRepository{
+		Dao: rep,
+	}
+	return repo
+}
+
+// DB 在GreeterRepository.Transaction中使用当前事务
+func (repo greeterAuditRepository) DB(ctx context.Context) *gorm.DB {
+	return dbWithTx(ctx, repo.Dao)
+}
+
+func (repo greeterAuditRepository) CreateGreeterAudit(ctx context.Context, m model.GreeterAudit) (model.GreeterAudit, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterAuditRepository.CreateGreeterAudit")
+	defer span.Finish()
+
+	if err := repo.DB(ctx).Create(&m).Error; err != nil {
+		return m, errorsx.Wrap(err, "greeterAuditRepository.CreateGreeterAudit")
+	}
+	return m, nil
+}
+
+func (repo greeterAuditRepository) FindGreeterAudits(ctx context.Context, greeterId int32, lastId int64, pageSize int32) ([]model.GreeterAudit, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterAuditRepository.FindGreeterAudits")
+	defer span.Finish()
+
+	tx := repo.DB(ctx).Where("greeter_id = ?", greeterId)
+	if lastId > 0 {
+		tx = tx.Where("id < ?", lastId)
+	}
+	var list []model.GreeterAudit
+	err := tx.Order("id DESC").Limit(int(pageSize)).Find(&list).Error
+	if err != nil {
+		return nil, errorsx.Wrap(err, "greeterAuditRepository.FindGreeterAudits")
+	}
+	return list, nil
+}
@@ -0,0 +1,49 @@
+package persistence
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) auditRepo() greeterAuditRepository {
+	repo := greeterAuditRepository{
+		Dao: dao.NewDao(constant.DBName),
+	}
+	repo.SetDBMock(s.mysqlDB)
+	return repo
+}
+
+func (s *Suite) TestGreeterAuditRepository_CreateGreeterAudit() {
+	ctx := context.Background()
+	m := model.GreeterAudit{GreeterId: 100, Action: 3, Actor: "koofox", RequestId: "req-1", Before: `{"id":100}`, After: `{"id":100,"status":"GREETER_STATUS_ACTIVE"}`}
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("INSERT INTO `tbl_greeter_audit`").
+		WithArgs(m.GreeterId, m.Action, m.Actor, m.RequestId, m.Before, m.After, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(7, 1))
+	s.mysqlMock.ExpectCommit()
+
+	actual, err := s.auditRepo().CreateGreeterAudit(ctx, m)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 7, actual.Id)
+	require.NotEmpty(s.T(), actual.CreateDatetime)
+}
+
+func (s *Suite) TestGreeterAuditRepository_FindGreeterAudits() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter_audit` WHERE greeter_id = \\? AND id < \\? ORDER BY id DESC LIMIT 3").
+		WithArgs(100, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "greeter_id", "action", "actor"}).AddRow(8, 100, 3, "koofox").AddRow(5, 100, 1, "koofox"))
+
+	list, err := s.auditRepo().FindGreeterAudits(ctx, 100, 9, 3)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []model.GreeterAudit{
+		{Id: 8, GreeterId: 100, Action: 3, Actor: "koofox"},
+		{Id: 5, GreeterId: 100, Action: 1, Actor: "koofox"},
+	}, list)
+}
@@ -0,0 +1,190 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
+	mrand "math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// 缓存请求的结果，miss包含coalesced
+const (
+	cacheHit          = "hit"
+	cacheMiss         = "miss"
+	cacheCoalesced    = "coalesced"
+	cacheEarlyRefresh = "early_refresh"
+)
+
+// cacheRequests 按缓存和结果统计的请求数
+var cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "greeter",
+	Name:      "cache_requests_total",
+	Help:      "Greeter cache requests by cache and result (hit, miss, coalesced, early_refresh).",
+}, []string{"cache", "result"})
+
+func init() {
+	prometheus.MustRegister(cacheRequests)
+}
+
+// unlockScript 只释放自己持有的锁
+var unlockScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`
+
+// xfetchRand XFetch使用的随机数，测试中替换为固定值
+var xfetchRand = mrand.Float64
+
+// cacheLoader 合并同一缓存key的并发加载，并按XFetch算法在过期前提前刷新
+type cacheLoader struct {
+	name  string
+	group singleflight.Group
+	// delta 最近一次从数据库加载并写入缓存的耗时（纳秒）
+	delta int64
+}
+
+var (
+	greeterLoader      = &cacheLoader{name: "greeter"}
+	greeterCountLoader = &cacheLoader{name: "greeter_cnt"}
+)
+
+func (l *cacheLoader) observe(result string) {
+	cacheRequests.WithLabelValues(l.name, result).Inc()
+}
+
+// shouldRefresh XFetch：命中时以 delta*beta*(-ln(rand)) >= 剩余ttl 的概率提前刷新，
+// 加载越慢、越接近过期越可能刷新；没有过期时间或还没有加载过时不刷新
+func (l *cacheLoader) shouldRefresh(ttl time.Duration) bool {
+	delta := atomic.LoadInt64(&l.delta)
+	if ttl <= 0 || delta <= 0 {
+		return false
+	}
+	return float64(delta)*constant.CacheXFetchBeta*-math.Log(xfetchRand()) >= float64(ttl)
+}
+
+// do 同一进程内同一key同时只有一个请求执行load，其他请求等待并共享结果。load使用detach返回的上下文，
+// 执行load的请求被取消时不影响其他等待的请求，每个请求被取消时只有自己提前返回
+func (l *cacheLoader) do(ctx context.Context, key string, load func(context.Context) (interface{}, error)) (interface{}, error) {
+	leader := false
+	ch := l.group.DoChan(key, l.timed(func() (interface{}, error) {
+		leader = true
+		ctx, cancel := detach(ctx)
+		defer cancel()
+		return load(ctx)
+	}))
+	select {
+	case r := <-ch:
+		if !leader {
+			l.observe(cacheCoalesced)
+		}
+		return r.Val, r.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// refreshAsync 在后台重新加载，与同一key正在进行的加载合并，使用detach返回的上下文避免随请求取消
+func (l *cacheLoader) refreshAsync(ctx context.Context, key string, load func(context.Context) error) {
+	l.observe(cacheEarlyRefresh)
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "cacheLoader"), zap.String("func", "refreshAsync"))
+
+	ctx, cancel := detach(ctx)
+	ch := l.group.DoChan(key, l.timed(func() (interface{}, error) {
+		return nil, load(ctx)
+	}))
+	go func() {
+		defer cancel()
+		if r := <-ch; r.Err != nil {
+			logger.Warn("refresh cache failed", zap.String("key", key), zap.Error(r.Err))
+		}
+	}()
+}
+
+// detach 返回不随ctx取消的上下文，只保留ctx中的日志和链路追踪的span，CacheLockTTL后超时
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := ctxzap.ToContext(context.Background(), ctxzap.Extract(ctx))
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		detached = opentracing.ContextWithSpan(detached, span)
+	}
+	return context.WithTimeout(detached, constant.CacheLockTTL)
+}
+
+// timed 记录加载成功的耗时，作为XFetch的delta
+func (l *cacheLoader) timed(load func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		start := time.Now()
+		v, err := load()
+		if err == nil {
+			atomic.StoreInt64(&l.delta, int64(time.Since(start)))
+		}
+		return v, err
+	}
+}
+
+// withCacheLock 开启cache.lock时，多个实例中只有获得锁的实例执行load写入缓存，其他实例等待后调用cached读取缓存，
+// 等待超时或Redis不可用时直接执行load；未开启时直接执行load
+func (repo greeterRepository) withCacheLock(ctx context.Context, key string, cached func() bool, load func() error) error {
+	if !viper.GetBool("cache.lock") {
+		return load()
+	}
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "withCacheLock"))
+
+	cli := repo.Redis()
+	lockKey := key + "_lock"
+	token, err := lockToken()
+	if err != nil {
+		return load()
+	}
+	locked, err := cli.SetNX(ctx, lockKey, token, constant.CacheLockTTL).Result()
+	if err != nil {
+		logger.Warn("redis.SetNX", zap.String("key", lockKey), zap.Error(err))
+		return load()
+	}
+	if locked {
+		defer func() {
+			if err := cli.Eval(ctx, unlockScript, []string{lockKey}, token).Err(); err != nil {
+				logger.Warn("release cache lock failed", zap.String("key", lockKey), zap.Error(err))
+			}
+		}()
+		return load()
+	}
+
+	for i := 0; i < constant.CacheLockRetry; i++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(constant.CacheLockRetryDelay):
+		}
+		if cached() {
+			return nil
+		}
+	}
+	return load()
+}
+
+func lockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
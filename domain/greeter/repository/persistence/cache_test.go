@@ -0,0 +1,175 @@
+package persistence
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	redisx "github.com/imind-lab/micro/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func (s *Suite) TestCacheLoader_Do() {
+	loader := &cacheLoader{name: "test_do"}
+	coalesced := testutil.ToFloat64(cacheRequests.WithLabelValues("test_do", cacheCoalesced))
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	var loads int32
+
+	load := func(ctx context.Context) (interface{}, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(entered)
+		}
+		<-release
+		return 100, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 5)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = loader.do(context.Background(), "key", load)
+	}()
+	<-entered
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = loader.do(context.Background(), "key", load)
+		}(i)
+	}
+	// 等待其他请求加入正在进行的加载
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(s.T(), 1, atomic.LoadInt32(&loads))
+	require.Equal(s.T(), []interface{}{100, 100, 100, 100, 100}, results)
+	require.Equal(s.T(), coalesced+4, testutil.ToFloat64(cacheRequests.WithLabelValues("test_do", cacheCoalesced)))
+	require.Greater(s.T(), atomic.LoadInt64(&loader.delta), int64(0))
+}
+
+// TestCacheLoader_DoCanceled 执行加载的请求被取消时，加载继续进行，其他等待的请求仍得到结果
+func (s *Suite) TestCacheLoader_DoCanceled() {
+	loader := &cacheLoader{name: "test_do_canceled"}
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	logger := zap.NewNop()
+
+	load := func(ctx context.Context) (interface{}, error) {
+		require.Same(s.T(), logger, ctxzap.Extract(ctx))
+		close(entered)
+		<-release
+		return 100, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(ctxzap.ToContext(context.Background(), logger))
+	leader := make(chan error, 1)
+	go func() {
+		_, err := loader.do(ctx, "key", load)
+		leader <- err
+	}()
+	<-entered
+
+	waiter := make(chan interface{}, 1)
+	go func() {
+		v, err := loader.do(context.Background(), "key", load)
+		require.NoError(s.T(), err)
+		waiter <- v
+	}()
+	// 等待其他请求加入正在进行的加载
+	time.Sleep(time.Millisecond * 20)
+	cancel()
+	require.ErrorIs(s.T(), <-leader, context.Canceled)
+
+	close(release)
+	require.Equal(s.T(), 100, <-waiter)
+}
+
+func (s *Suite) TestCacheLoader_ShouldRefresh() {
+	defer func(fn func() float64) { xfetchRand = fn }(xfetchRand)
+
+	loader := &cacheLoader{name: "test_refresh"}
+	xfetchRand = func() float64 { return 0.1 }
+	require.False(s.T(), loader.shouldRefresh(time.Second), "never loaded")
+
+	loader.delta = int64(time.Second)
+	// -ln(0.1) ≈ 2.3
+	require.True(s.T(), loader.shouldRefresh(time.Second*2))
+	require.False(s.T(), loader.shouldRefresh(time.Second*3))
+	require.False(s.T(), loader.shouldRefresh(-1), "no expire")
+
+	xfetchRand = func() float64 { return 0.5 }
+	// -ln(0.5) ≈ 0.69
+	require.False(s.T(), loader.shouldRefresh(time.Second))
+}
+
+// TestGreeterRepository_GetGreeterByIdEarlyRefresh 命中但接近过期时返回缓存，并在后台重新加载
+func (s *Suite) TestGreeterRepository_GetGreeterByIdEarlyRefresh() {
+	defer func(fn func() float64) { xfetchRand = fn }(xfetchRand)
+	defer atomic.StoreInt64(&greeterLoader.delta, atomic.LoadInt64(&greeterLoader.delta))
+	xfetchRand = func() float64 { return 0.1 }
+	atomic.StoreInt64(&greeterLoader.delta, int64(time.Second))
+
+	ctx := context.Background()
+	key := utilx.CacheKey("greeter_", "100")
+	refreshed := model.Greeter{Id: 100, Name: "koofox", ViewNum: 2, Status: 1}
+
+	s.redisMock.ExpectPTTL(key).SetVal(time.Millisecond * 500)
+	s.redisMock.ExpectHGetAll(key).SetVal(map[string]string{"id": "100", "name": "koofox", "view_num": "1", "status": "1"})
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\?").WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "view_num", "status"}).AddRow(100, "koofox", 2, 1))
+	s.redisMock.ExpectHMSet(key, redisx.FlatStruct(refreshed)).SetVal(true)
+	s.redisMock.CustomMatch(anyExpire).ExpectExpire(key, constant.CacheMinute5).SetVal(true)
+
+	before := testutil.ToFloat64(cacheRequests.WithLabelValues(greeterLoader.name, cacheEarlyRefresh))
+	m, err := s.repo.GetGreeterById(ctx, 100)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, m.ViewNum)
+	require.Equal(s.T(), before+1, testutil.ToFloat64(cacheRequests.WithLabelValues(greeterLoader.name, cacheEarlyRefresh)))
+
+	// 等待后台刷新结束：加入正在进行的刷新，或在刷新结束后执行空操作
+	greeterLoader.group.Do(key, func() (interface{}, error) { return nil, nil })
+}
+
+func (s *Suite) TestGreeterRepository_GetGreetersCountLocked() {
+	viper.Set("cache.lock", true)
+	defer viper.Set("cache.lock", false)
+
+	ctx := context.Background()
+	key := utilx.CacheKey("greeter_cnt_", "2")
+	lockKey := key + "_lock"
+
+	// 获得锁的实例查询数据库并写入缓存
+	s.redisMock.ExpectPTTL(key).SetVal(-2)
+	s.redisMock.ExpectGet(key).RedisNil()
+	s.redisMock.CustomMatch(anyExpire).ExpectSetNX(lockKey, "token", constant.CacheLockTTL).SetVal(true)
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE status=\\?").
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	s.redisMock.CustomMatch(anyExpire).ExpectSet(key, int64(7), constant.CacheMinute5).SetVal("OK")
+	s.redisMock.CustomMatch(anyExpire).ExpectEval(unlockScript, []string{lockKey}, "token").SetVal(int64(1))
+
+	cnt, err := s.repo.GetGreetersCount(ctx, 2)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 7, cnt)
+
+	// 其他实例持有锁时等待缓存写入，不查询数据库
+	s.redisMock.ExpectPTTL(key).SetVal(-2)
+	s.redisMock.ExpectGet(key).RedisNil()
+	s.redisMock.CustomMatch(anyExpire).ExpectSetNX(lockKey, "token", constant.CacheLockTTL).SetVal(false)
+	s.redisMock.ExpectPTTL(key).SetVal(constant.CacheMinute5)
+	s.redisMock.ExpectGet(key).SetVal("7")
+
+	cnt, err = s.repo.GetGreetersCount(ctx, 2)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 7, cnt)
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/repositorytest"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+)
+
+// TestGreeterRepository_ConformanceSQLite 对临时目录中的SQLite数据库运行一致性测试，不依赖外部服务
+func TestGreeterRepository_ConformanceSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "greeter.db")), &gorm.Config{NamingStrategy: namingStrategy()})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+
+	runConformance(t, db)
+}
+
+// TestGreeterRepository_ConformanceMySQL 设置GREETER_TEST_MYSQL_DSN时对MySQL运行一致性测试
+func TestGreeterRepository_ConformanceMySQL(t *testing.T) {
+	dsn := os.Getenv("GREETER_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GREETER_TEST_MYSQL_DSN is not set")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{NamingStrategy: namingStrategy()})
+	require.NoError(t, err)
+
+	runConformance(t, db)
+}
+
+// TestGreeterRepository_ConformancePostgres 设置GREETER_TEST_POSTGRES_DSN时对PostgreSQL运行一致性测试
+func TestGreeterRepository_ConformancePostgres(t *testing.T) {
+	dsn := os.Getenv("GREETER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GREETER_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{NamingStrategy: namingStrategy()})
+	require.NoError(t, err)
+
+	runConformance(t, db)
+}
+
+// runConformance 通过迁移创建表，Redis使用miniredis，每个用例前清空tbl_greeter和tbl_greeter_outbox；使用DELETE而不是TRUNCATE，id不会重复，
+// 避免异步写入的缓存影响后续用例
+func runConformance(t *testing.T, db *gorm.DB) {
+	migrator, err := newMigrator(db, constant.TablePrefix)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background(), 0)
+	require.NoError(t, err)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+
+	suite.Run(t, &repositorytest.GreeterRepositorySuite{NewRepo: func() repository.GreeterRepository {
+		require.NoError(t, db.Exec("DELETE FROM tbl_greeter").Error)
+		mr.FlushAll()
+
+		d := greeterDao{Dao: dao.NewDao(constant.DBName), db: db}
+		d.SetRedisMock(rdb)
+		return greeterRepository{Dao: d}
+	}})
+
+	suite.Run(t, &repositorytest.GreeterOutboxRepositorySuite{NewRepo: func() repository.GreeterOutboxRepository {
+		require.NoError(t, db.Exec("DELETE FROM tbl_greeter_outbox").Error)
+		mr.FlushAll()
+
+		d := greeterDao{Dao: dao.NewDao(constant.DBName), db: db}
+		d.SetRedisMock(rdb)
+		return greeterOutboxRepository{Dao: d}
+	}})
+}
@@ -0,0 +1,370 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+	"github.com/imind-lab/micro/tracing"
+)
+
+const (
+	// greeterCounterDirtyKey 有未写入数据库的增量的Greeter id集合
+	greeterCounterDirtyKey = "greeter_counter_dirty"
+	// greeterCounterLockKey 写入数据库时持有的锁，保证同一时刻只有一个实例在写入
+	greeterCounterLockKey = "greeter_counter_lock"
+	// greeterCounterBatchField 正在写入的hash中记录批次Id的字段
+	greeterCounterBatchField = "_batch"
+)
+
+// greeterCounterTakeScript 没有正在写入的hash时将增量hash改名为正在写入的hash，并记录新的批次Id，返回正在写入的hash；
+// 上次写入失败留下的hash保持原来的批次和增量，新的增量留在增量hash中，id也留在dirty集合中，等这批写入后再取出
+var greeterCounterTakeScript = `if redis.call('EXISTS', KEYS[2]) == 0 and redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('RENAME', KEYS[1], KEYS[2])
+end
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('HSETNX', KEYS[2], ARGV[3], ARGV[2])
+end
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	redis.call('SREM', KEYS[3], ARGV[1])
+end
+return redis.call('HGETALL', KEYS[2])`
+
+// greeterCounterDoneScript 删除已写入数据库的hash，批次Id不同时说明这批已由其他实例写入并取出了新的一批，保留
+var greeterCounterDoneScript = `for i = 1, #KEYS do
+	if redis.call('HGET', KEYS[i], ARGV[1]) == ARGV[i + 1] then
+		redis.call('DEL', KEYS[i])
+	end
+end
+return 0`
+
+// refreshLockScript 锁仍由自己持有时延长过期时间
+var refreshLockScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`
+
+type greeterCounterRepository struct {
+	dao.Dao
+
+	// lockRefresh 写入期间延长锁的间隔，为0时使用constant.GreeterCounterFlushLockRefresh
+	lockRefresh time.Duration
+}
+
+// NewGreeterCounterRepository 创建基于Redis的计数增量暂存实例，增量用HINCRBY累加在greeter_counter_<id>中
+func NewGreeterCounterRepository() repository.GreeterCounterRepository {
+	return greeterCounterRepository{
+		Dao: newDao(),
+	}
+}
+
+func greeterCounterKey(id int32) string {
+	return utilx.CacheKey("greeter_counter_", strconv.Itoa(int(id)))
+}
+
+func greeterCounterFlushingKey(id int32) string {
+	return utilx.CacheKey("greeter_counter_flushing_", strconv.Itoa(int(id)))
+}
+
+func (repo greeterCounterRepository) IncrGreeterCounter(ctx context.Context, id int32, column string, num int32) error {
+	if !repository.IsGreeterCounter(column) {
+		return errorsx.Wrapf(repository.ErrCounterNotAllowed, "greeterCounterRepository.IncrGreeterCounter %s", column)
+	}
+
+	pipe := repo.Redis().TxPipeline()
+	pipe.HIncrBy(ctx, greeterCounterKey(id), column, int64(num))
+	pipe.SAdd(ctx, utilx.CacheKey(greeterCounterDirtyKey), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errorsx.Wrap(err, "greeterCounterRepository.IncrGreeterCounter")
+	}
+	return nil
+}
+
+func (repo greeterCounterRepository) PendingGreeterCounters(ctx context.Context, list []model.Greeter) (map[int32]repository.GreeterCounterDelta, error) {
+	pending := make(map[int32]repository.GreeterCounterDelta)
+	if len(list) == 0 {
+		return pending, nil
+	}
+
+	// 在一个事务中读取两个hash，避免与取出增量的脚本交错时同一增量被读到两次
+	pipe := repo.Redis().TxPipeline()
+	cmds := make([][2]*redis.StringStringMapCmd, len(list))
+	for i, m := range list {
+		cmds[i] = [2]*redis.StringStringMapCmd{
+			pipe.HGetAll(ctx, greeterCounterKey(m.Id)),
+			pipe.HGetAll(ctx, greeterCounterFlushingKey(m.Id)),
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, errorsx.Wrap(err, "greeterCounterRepository.PendingGreeterCounters")
+	}
+
+	for i, m := range list {
+		if err := addGreeterCounterDelta(pending, m.Id, cmds[i][0].Val()); err != nil {
+			return nil, errorsx.WithMessage(err, "greeterCounterRepository.PendingGreeterCounters")
+		}
+		flushing := cmds[i][1].Val()
+		// 这批增量已与m在同一个事务中写入，只是还没有从Redis中删除
+		if batch, ok := flushing[greeterCounterBatchField]; ok && batch == m.CounterBatch {
+			continue
+		}
+		if err := addGreeterCounterDelta(pending, m.Id, flushing); err != nil {
+			return nil, errorsx.WithMessage(err, "greeterCounterRepository.PendingGreeterCounters")
+		}
+	}
+	return pending, nil
+}
+
+// FlushGreeterCounters 增量从greeter_counter_<id>改名为greeter_counter_flushing_<id>并记录批次Id后交给apply，
+// apply与计数在同一个事务中记录批次Id，成功后删除flushing hash，失败时把id放回dirty集合，下次按原来的批次重新写入；
+// apply成功但删除flushing hash前进程退出，或锁过期后其他实例取出同一批时，已记录的批次不会重复累加。
+// 持有锁期间每隔lockRefresh延长锁的过期时间，锁已不属于当前实例时取消apply
+func (repo greeterCounterRepository) FlushGreeterCounters(ctx context.Context, batchSize int, apply func(context.Context, map[int32]repository.GreeterCounterBatch) error) (int, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterCounterRepository"), zap.String("func", "FlushGreeterCounters"))
+
+	cli := repo.Redis()
+	token, err := lockToken()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterCounterRepository.FlushGreeterCounters")
+	}
+	lockKey := utilx.CacheKey(greeterCounterLockKey)
+	locked, err := cli.SetNX(ctx, lockKey, token, constant.GreeterCounterFlushLockTTL).Result()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterCounterRepository.FlushGreeterCounters.SetNX")
+	}
+	if !locked {
+		logger.Debug("greeter counters are being flushed by another instance")
+		return 0, nil
+	}
+	flushCtx, cancel := context.WithCancel(ctx)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		repo.refreshLock(flushCtx, logger, lockKey, token, cancel, stop)
+	}()
+	defer func() {
+		close(stop)
+		<-stopped
+		cancel()
+		if err := cli.Eval(ctx, unlockScript, []string{lockKey}, token).Err(); err != nil {
+			logger.Warn("release greeter counter lock failed", zap.Error(err))
+		}
+	}()
+
+	dirtyKey := utilx.CacheKey(greeterCounterDirtyKey)
+	members, err := cli.SRandMemberN(flushCtx, dirtyKey, int64(batchSize)).Result()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterCounterRepository.FlushGreeterCounters.SRandMember")
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int32, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.Atoi(member)
+		if err != nil {
+			// 非法成员直接移除，避免每次都取到
+			cli.SRem(flushCtx, dirtyKey, member)
+			logger.Warn("invalid greeter counter id", zap.String("member", member))
+			continue
+		}
+		ids = append(ids, int32(id))
+	}
+
+	batch, err := lockToken()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterCounterRepository.FlushGreeterCounters")
+	}
+	pipe := cli.Pipeline()
+	cmds := make([]*redis.Cmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.Eval(flushCtx, greeterCounterTakeScript, []string{greeterCounterKey(id), greeterCounterFlushingKey(id), dirtyKey},
+			id, batch, greeterCounterBatchField)
+	}
+	if _, err := pipe.Exec(flushCtx); err != nil {
+		return 0, errorsx.Wrap(err, "greeterCounterRepository.FlushGreeterCounters.Take")
+	}
+
+	batches := make(map[int32]repository.GreeterCounterBatch, len(ids))
+	for i, id := range ids {
+		// 脚本返回HGETALL的结果，flushing hash不存在时为空数组
+		values, _ := cmds[i].Val().([]interface{})
+		fields := make(map[string]string, len(values)/2)
+		for j := 0; j+1 < len(values); j += 2 {
+			fields[fmt.Sprint(values[j])] = fmt.Sprint(values[j+1])
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		deltas := make(map[int32]repository.GreeterCounterDelta, 1)
+		if err := addGreeterCounterDelta(deltas, id, fields); err != nil {
+			return 0, errorsx.WithMessage(err, "greeterCounterRepository.FlushGreeterCounters")
+		}
+		batches[id] = repository.GreeterCounterBatch{Id: fields[greeterCounterBatchField], Delta: deltas[id]}
+	}
+
+	if err := apply(flushCtx, batches); err != nil {
+		// 锁已失去时flushCtx已取消，使用调用方的ctx放回
+		if err := cli.SAdd(ctx, dirtyKey, int32sToInterfaces(ids)...).Err(); err != nil {
+			logger.Error("restore greeter counter ids failed", zap.Int32s("ids", ids), zap.Error(err))
+		}
+		return 0, errorsx.WithMessage(err, "greeterCounterRepository.FlushGreeterCounters")
+	}
+
+	keys := make([]string, 0, len(batches))
+	args := make([]interface{}, 0, len(batches)+1)
+	args = append(args, greeterCounterBatchField)
+	for _, id := range ids {
+		if b, ok := batches[id]; ok {
+			keys = append(keys, greeterCounterFlushingKey(id))
+			args = append(args, b.Id)
+		}
+	}
+	if len(keys) > 0 {
+		if err := cli.Eval(ctx, greeterCounterDoneScript, keys, args...).Err(); err != nil && err != redis.Nil {
+			logger.Error("delete flushed greeter counters failed", zap.Int32s("ids", ids), zap.Error(err))
+		}
+	}
+	return len(batches), nil
+}
+
+// refreshLock 每隔lockRefresh延长锁的过期时间直到stop关闭，锁已不属于当前实例时调用cancel；
+// 延长失败时只记录日志，下一次继续延长
+func (repo greeterCounterRepository) refreshLock(ctx context.Context, logger *zap.Logger, lockKey, token string, cancel context.CancelFunc, stop <-chan struct{}) {
+	interval := repo.lockRefresh
+	if interval <= 0 {
+		interval = constant.GreeterCounterFlushLockRefresh
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := repo.Redis().Eval(ctx, refreshLockScript, []string{lockKey}, token,
+				constant.GreeterCounterFlushLockTTL.Milliseconds()).Int64()
+			if err != nil {
+				logger.Warn("refresh greeter counter lock", zap.Error(err))
+				continue
+			}
+			if n == 0 {
+				logger.Error("greeter counter lock lost")
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// AddGreeterCounters 按id顺序逐条累加并将版本号加1，同时记录批次Id，避免并发写入时死锁；counter_batch已是这一批时不再累加，
+// 增量为0的字段不写入，全部为0时不修改版本号
+func (repo greeterRepository) AddGreeterCounters(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.AddGreeterCounters")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "AddGreeterCounters"))
+
+	ids := make([]int32, 0, len(batches))
+	for id, batch := range batches {
+		for column := range batch.Delta {
+			if !repository.IsGreeterCounter(column) {
+				return errorsx.Wrapf(repository.ErrCounterNotAllowed, "greeterRepository.AddGreeterCounters %s", column)
+			}
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	err := repo.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			batch := batches[id]
+			values := make(map[string]interface{}, len(batch.Delta)+2)
+			for column, num := range batch.Delta {
+				if num != 0 {
+					values[column] = increment(column, num)
+				}
+			}
+			if len(values) == 0 {
+				continue
+			}
+			values["version"] = increment("version", 1)
+			values["counter_batch"] = batch.Id
+			if err := tx.Model(&model.Greeter{}).Where("id = ? AND counter_batch <> ?", id, batch.Id).UpdateColumns(values).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errorsx.Wrap(err, "greeterRepository.AddGreeterCounters")
+	}
+
+	if len(ids) > 0 {
+		afterCommit(ctx, func(ctx context.Context) {
+			pipe := repo.Redis().Pipeline()
+			for _, id := range ids {
+				pipe.Del(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(id))))
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				logger.Warn("redis.Pipeline AddGreeterCounters", zap.Int32s("ids", ids), zap.Error(err))
+			}
+		})
+	}
+	return nil
+}
+
+// addGreeterCounterDelta 将HGETALL的结果累加到pending中，不在允许列表中的字段忽略
+func addGreeterCounterDelta(pending map[int32]repository.GreeterCounterDelta, id int32, fields map[string]string) error {
+	for column, value := range fields {
+		if !repository.IsGreeterCounter(column) {
+			continue
+		}
+		num, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid greeter counter %d %s: %w", id, column, err)
+		}
+		if num == 0 {
+			continue
+		}
+		delta, ok := pending[id]
+		if !ok {
+			delta = make(repository.GreeterCounterDelta)
+			pending[id] = delta
+		}
+		delta[column] += num
+	}
+	return nil
+}
+
+func int32sToInterfaces(ids []int32) []interface{} {
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	return members
+}
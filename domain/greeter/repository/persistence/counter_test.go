@@ -0,0 +1,230 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) counterRepo() greeterCounterRepository {
+	repo := greeterCounterRepository{
+		Dao: dao.NewDao(constant.DBName),
+	}
+	repo.SetRedisMock(s.redisDB)
+	return repo
+}
+
+func (s *Suite) TestGreeterCounterRepository_IncrGreeterCounter() {
+	ctx := context.Background()
+
+	s.redisMock.ExpectTxPipeline()
+	s.redisMock.ExpectHIncrBy(greeterCounterKey(100), "view_num", 3).SetVal(3)
+	s.redisMock.ExpectSAdd(utilx.CacheKey(greeterCounterDirtyKey), int32(100)).SetVal(1)
+	s.redisMock.ExpectTxPipelineExec()
+	require.NoError(s.T(), s.counterRepo().IncrGreeterCounter(ctx, 100, "view_num", 3))
+
+	err := s.counterRepo().IncrGreeterCounter(ctx, 100, "version", 1)
+	require.True(s.T(), errors.Is(err, repository.ErrCounterNotAllowed))
+}
+
+func (s *Suite) TestGreeterCounterRepository_PendingGreeterCounters() {
+	ctx := context.Background()
+
+	// 100正在写入的一批已计入，200正在写入的一批尚未计入
+	s.redisMock.ExpectTxPipeline()
+	s.redisMock.ExpectHGetAll(greeterCounterKey(100)).SetVal(map[string]string{"view_num": "3"})
+	s.redisMock.ExpectHGetAll(greeterCounterFlushingKey(100)).SetVal(map[string]string{"view_num": "2", greeterCounterBatchField: "b1"})
+	s.redisMock.ExpectHGetAll(greeterCounterKey(200)).SetVal(map[string]string{"view_num": "1"})
+	s.redisMock.ExpectHGetAll(greeterCounterFlushingKey(200)).SetVal(map[string]string{"view_num": "2", "removed": "9", greeterCounterBatchField: "b2"})
+	s.redisMock.ExpectHGetAll(greeterCounterKey(300)).SetVal(map[string]string{})
+	s.redisMock.ExpectHGetAll(greeterCounterFlushingKey(300)).SetVal(map[string]string{})
+	s.redisMock.ExpectTxPipelineExec()
+
+	pending, err := s.counterRepo().PendingGreeterCounters(ctx, []model.Greeter{
+		{Id: 100, CounterBatch: "b1"},
+		{Id: 200, CounterBatch: "b1"},
+		{Id: 300},
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), map[int32]repository.GreeterCounterDelta{100: {"view_num": 3}, 200: {"view_num": 3}}, pending)
+}
+
+func (s *Suite) TestGreeterCounterRepository_FlushGreeterCounters() {
+	ctx := context.Background()
+	lockKey := utilx.CacheKey(greeterCounterLockKey)
+	dirtyKey := utilx.CacheKey(greeterCounterDirtyKey)
+	// 批次Id随机生成，只比较脚本
+	take := func(id int32, values ...interface{}) {
+		s.redisMock.CustomMatch(anyExpire).ExpectEval(greeterCounterTakeScript, []string{greeterCounterKey(id), greeterCounterFlushingKey(id), dirtyKey},
+			id, "batch", greeterCounterBatchField).SetVal(values)
+	}
+
+	// 写入成功后删除flushing hash，上次失败留下的一批保持原来的批次Id
+	s.redisMock.CustomMatch(anyExpire).ExpectSetNX(lockKey, "token", constant.GreeterCounterFlushLockTTL).SetVal(true)
+	s.redisMock.ExpectSRandMemberN(dirtyKey, 2).SetVal([]string{"100", "200"})
+	take(100, "view_num", "5", greeterCounterBatchField, "b1")
+	take(200, "view_num", "-1", greeterCounterBatchField, "b0")
+	s.redisMock.ExpectEval(greeterCounterDoneScript, []string{greeterCounterFlushingKey(100), greeterCounterFlushingKey(200)},
+		greeterCounterBatchField, "b1", "b0").SetVal(int64(0))
+	s.redisMock.CustomMatch(anyExpire).ExpectEval(unlockScript, []string{lockKey}, "token").SetVal(int64(1))
+
+	var applied map[int32]repository.GreeterCounterBatch
+	n, err := s.counterRepo().FlushGreeterCounters(ctx, 2, func(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+		applied = batches
+		return nil
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, n)
+	require.Equal(s.T(), map[int32]repository.GreeterCounterBatch{
+		100: {Id: "b1", Delta: repository.GreeterCounterDelta{"view_num": 5}},
+		200: {Id: "b0", Delta: repository.GreeterCounterDelta{"view_num": -1}},
+	}, applied)
+
+	// 写入失败时放回dirty集合，保留flushing hash
+	s.redisMock.CustomMatch(anyExpire).ExpectSetNX(lockKey, "token", constant.GreeterCounterFlushLockTTL).SetVal(true)
+	s.redisMock.ExpectSRandMemberN(dirtyKey, 2).SetVal([]string{"100"})
+	take(100, "view_num", "5", greeterCounterBatchField, "b1")
+	s.redisMock.ExpectSAdd(dirtyKey, int32(100)).SetVal(1)
+	s.redisMock.CustomMatch(anyExpire).ExpectEval(unlockScript, []string{lockKey}, "token").SetVal(int64(1))
+
+	_, err = s.counterRepo().FlushGreeterCounters(ctx, 2, func(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+		return errors.New("deadlock")
+	})
+	require.Error(s.T(), err)
+
+	// 其他实例持有锁时直接返回
+	s.redisMock.CustomMatch(anyExpire).ExpectSetNX(lockKey, "token", constant.GreeterCounterFlushLockTTL).SetVal(false)
+	n, err = s.counterRepo().FlushGreeterCounters(ctx, 2, func(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+		s.T().Fatal("apply should not be called")
+		return nil
+	})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, n)
+}
+
+func (s *Suite) TestGreeterRepository_AddGreeterCounters() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `counter_batch`=\\?,`version`=`version` \\+ \\?,`view_num`=`view_num` \\+ \\? WHERE \\(id = \\? AND counter_batch <> \\?\\)").
+		WithArgs("b1", 1, int64(5), 100, "b1").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `counter_batch`=\\?,`version`=`version` \\+ \\?,`view_num`=`view_num` \\+ \\? WHERE \\(id = \\? AND counter_batch <> \\?\\)").
+		WithArgs("b1", 1, int64(-1), 200, "b1").WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectCommit()
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_100")).SetVal(1)
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_200")).SetVal(1)
+
+	err := s.repo.AddGreeterCounters(ctx, map[int32]repository.GreeterCounterBatch{
+		200: {Id: "b1", Delta: repository.GreeterCounterDelta{"view_num": -1}},
+		100: {Id: "b1", Delta: repository.GreeterCounterDelta{"view_num": 5}},
+		300: {Id: "b1", Delta: repository.GreeterCounterDelta{"view_num": 0}},
+	})
+	require.NoError(s.T(), err)
+
+	err = s.repo.AddGreeterCounters(ctx, map[int32]repository.GreeterCounterBatch{100: {Id: "b2", Delta: repository.GreeterCounterDelta{"name": 1}}})
+	require.True(s.T(), errors.Is(err, repository.ErrCounterNotAllowed))
+}
+
+func (s *Suite) TestGreeterRepository_UpdateGreeterCount() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `update_datetime`=\\?,`version`=`version` \\+ \\?,`view_num`=`view_num` \\+ \\? WHERE id = \\? AND version = \\?").
+		WithArgs(sqlmock.AnyArg(), 1, int32(2), 100, int32(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectCommit()
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_100")).SetVal(1)
+
+	affected, err := s.repo.UpdateGreeterCount(ctx, 100, 2, 1, "view_num")
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+
+	// column不在允许列表中时不执行SQL
+	_, err = s.repo.UpdateGreeterCount(ctx, 100, 2, 1, "view_num = 0, name")
+	require.True(s.T(), errors.Is(err, repository.ErrCounterNotAllowed))
+}
+
+func TestGreeterCounterRepository_FlushGreeterCountersOnce(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	d := greeterDao{Dao: dao.NewDao(constant.DBName), db: db}
+	d.SetRedisMock(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	repo := greeterRepository{Dao: d}
+	counterRepo := greeterCounterRepository{Dao: d}
+
+	created, err := repo.CreateGreeter(ctx, model.Greeter{Name: "koofox", Status: 1})
+	require.NoError(t, err)
+	find := func() model.Greeter {
+		var got model.Greeter
+		require.NoError(t, db.First(&got, created.Id).Error)
+		return got
+	}
+
+	// 事务已提交但删除flushing hash前失败，这批增量不再计入未写入的增量，也不会再次累加
+	require.NoError(t, counterRepo.IncrGreeterCounter(ctx, created.Id, "view_num", 3))
+	_, err = counterRepo.FlushGreeterCounters(ctx, 10, func(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+		if err := repo.AddGreeterCounters(ctx, batches); err != nil {
+			return err
+		}
+		return errors.New("connection reset")
+	})
+	require.Error(t, err)
+	got := find()
+	require.EqualValues(t, 3, got.ViewNum)
+	require.NoError(t, counterRepo.IncrGreeterCounter(ctx, created.Id, "view_num", 2))
+	pending, err := counterRepo.PendingGreeterCounters(ctx, []model.Greeter{got})
+	require.NoError(t, err)
+	require.Equal(t, map[int32]repository.GreeterCounterDelta{created.Id: {"view_num": 2}}, pending)
+
+	// 先重新写入留下的一批，再写入新的增量
+	for i := 0; i < 2; i++ {
+		_, err = counterRepo.FlushGreeterCounters(ctx, 10, repo.AddGreeterCounters)
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 5, find().ViewNum)
+	pending, err = counterRepo.PendingGreeterCounters(ctx, []model.Greeter{find()})
+	require.NoError(t, err)
+	require.Empty(t, pending)
+	require.False(t, mr.Exists(utilx.CacheKey(greeterCounterDirtyKey)))
+
+	// 写入期间延长锁，锁被其他实例取得后取消写入
+	counterRepo.lockRefresh = time.Millisecond * 10
+	require.NoError(t, counterRepo.IncrGreeterCounter(ctx, created.Id, "view_num", 1))
+	_, err = counterRepo.FlushGreeterCounters(ctx, 10, func(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+		lockKey := utilx.CacheKey(greeterCounterLockKey)
+		mr.FastForward(constant.GreeterCounterFlushLockTTL / 2)
+		time.Sleep(time.Millisecond * 50)
+		mr.FastForward(constant.GreeterCounterFlushLockTTL / 2)
+		require.True(t, mr.Exists(lockKey))
+
+		require.NoError(t, mr.Set(lockKey, "other"))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualValues(t, 5, find().ViewNum)
+	require.True(t, mr.Exists(utilx.CacheKey(greeterCounterDirtyKey)))
+	lock, err := mr.Get(utilx.CacheKey(greeterCounterLockKey))
+	require.NoError(t, err)
+	require.Equal(t, "other", lock)
+}
@@ -0,0 +1,130 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+)
+
+var (
+	driverDBOnce sync.Once
+	driverDB     *gorm.DB
+
+	replicasOnce sync.Once
+)
+
+// greeterDao db.<name>.driver为sqlite或postgres时，数据库由本包打开，Redis仍由micro/dao管理
+type greeterDao struct {
+	dao.Dao
+
+	db *gorm.DB
+}
+
+func (d greeterDao) DB(ctx context.Context) *gorm.DB {
+	return d.db.WithContext(ctx)
+}
+
+// newDao 按db.<name>.driver创建Dao，mysql（默认）由micro/dao打开，支持读写分离，db.<name>.read中的从库在进程内只注册一次；
+// sqlite和postgres在进程内只打开一次
+func newDao() dao.Dao {
+	d := dao.NewDao(constant.DBName)
+	driver := viper.GetString("db." + constant.DBName + ".driver")
+	if driver == "" || driver == constant.DBDriverMySQL {
+		replicasOnce.Do(func() {
+			if err := registerGreeterReplicas(d); err != nil {
+				log.Fatalf("can't register greeter replicas: %v", err)
+			}
+		})
+		return d
+	}
+
+	driverDBOnce.Do(func() {
+		var err error
+		driverDB, err = openDriverDB(driver)
+		if err != nil {
+			log.Fatalf("can't open %s database: %v", driver, err)
+		}
+	})
+	return greeterDao{Dao: d, db: driverDB}
+}
+
+// openDriverDB 打开sqlite或postgres数据库，日志级别和表名规则与micro/dao打开MySQL时一致；
+// SQLite同一时间只允许一个写入，只使用一个连接，避免并发写入时返回database is locked
+func openDriverDB(driver string) (*gorm.DB, error) {
+	prefix := "db." + constant.DBName + "." + driver + "."
+	var dialector gorm.Dialector
+	switch driver {
+	case constant.DBDriverSQLite:
+		dialector = sqlite.Open(viper.GetString(prefix + "path"))
+	case constant.DBDriverPostgres:
+		sslMode := viper.GetString(prefix + "sslmode")
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dialector = postgres.Open(fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			viper.GetString(prefix+"host"), viper.GetInt(prefix+"port"), viper.GetString(prefix+"user"),
+			viper.GetString(prefix+"pass"), viper.GetString(prefix+"name"), sslMode))
+	default:
+		return nil, fmt.Errorf("unknown driver %q", driver)
+	}
+
+	logMode := viper.GetInt("db.logLevel")
+	if logMode < 1 || logMode > 4 {
+		logMode = 1
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.LogLevel(logMode)),
+		NamingStrategy: namingStrategy(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if driver == constant.DBDriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxOpenConns(viper.GetInt("db.max.open"))
+		sqlDB.SetMaxIdleConns(viper.GetInt("db.max.idle"))
+		sqlDB.SetConnMaxLifetime(time.Duration(viper.GetInt("db.max.life")) * time.Minute)
+	}
+	return db, nil
+}
+
+// namingStrategy 与micro/dao打开MySQL时的表名规则一致，模型不指定表名，迁移文件中的表名使用同一前缀
+func namingStrategy() schema.NamingStrategy {
+	return schema.NamingStrategy{
+		TablePrefix:   tablePrefix(),
+		SingularTable: true,
+	}
+}
+
+// tablePrefix 读取db.<name>.tablePrefix，未配置时使用constant.TablePrefix
+func tablePrefix() string {
+	key := "db." + constant.DBName + ".tablePrefix"
+	if !viper.IsSet(key) {
+		return constant.TablePrefix
+	}
+	return viper.GetString(key)
+}
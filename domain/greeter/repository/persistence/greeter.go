@@ -10,14 +10,18 @@ package persistence
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"strconv"
-	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	errorsx "github.com/pkg/errors"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/imind-lab/greeter/domain/greeter/repository"
 	"github.com/imind-lab/greeter/domain/greeter/repository/model"
@@ -35,7 +39,7 @@ type greeterRepository struct {
 
 //NewGreeterRepository 创建用户仓库实例
 func NewGreeterRepository() repository.GreeterRepository {
-	rep := dao.NewDao(constant.DBName)
+	rep := newDao()
 	repo := greeterRepository{
 		Dao: rep,
 	}
@@ -46,10 +50,16 @@ func (repo greeterRepository) CreateGreeter(ctx context.Context, m model.Greeter
 	span, ctx := tracing.StartSpan(ctx, "greeterRepository.CreateGreeter")
 	defer span.Finish()
 
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "CreateGreeter"))
+
 	if err := repo.DB(ctx).Create(&m).Error; err != nil {
 		return m, errorsx.Wrap(err, "greeterRepository.CreateGreeter")
 	}
-	repo.CacheGreeter(ctx, m)
+	afterCommit(ctx, func(ctx context.Context) {
+		repo.markWritten(ctx)
+		repo.CacheGreeter(ctx, m)
+		repo.addGreeterListCache(ctx, logger, m.Id, m.Status)
+	})
 	return m, nil
 }
 
@@ -76,18 +86,66 @@ func (repo greeterRepository) GetGreeterById(ctx context.Context, id int32, opt
 
 	var m model.Greeter
 	key := utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
-	err := redisx.HGet(ctx, repo.Redis(), key, &m)
+	ttl, err := repo.getGreeterCache(ctx, key, &m)
 	logger.Debug("redis.HGetAll", zap.Any("greeter", m), zap.String("key", key), zap.Error(err))
 	if err == nil {
+		greeterLoader.observe(cacheHit)
+		if greeterLoader.shouldRefresh(ttl) {
+			greeterLoader.refreshAsync(ctx, key, func(ctx context.Context) error {
+				_, err := repo.loadGreeter(ctx, id, key, opts.RandExpire)
+				return err
+			})
+		}
 		return m, nil
 	}
 
-	m, err = repo.FindGreeterById(ctx, id)
+	greeterLoader.observe(cacheMiss)
+	v, err := greeterLoader.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		var m model.Greeter
+		err := repo.withCacheLock(ctx, key, func() bool {
+			_, err := repo.getGreeterCache(ctx, key, &m)
+			return err == nil
+		}, func() (err error) {
+			m, err = repo.loadGreeter(ctx, id, key, opts.RandExpire)
+			return err
+		})
+		return m, err
+	})
+	if err != nil {
+		return model.Greeter{}, errorsx.WithMessage(err, "greeterRepository.GetGreeterById")
+	}
+	return v.(model.Greeter), nil
+}
+
+// getGreeterCache 读取Greeter缓存及其剩余的过期时间，缓存不存在时返回redis.Nil
+func (repo greeterRepository) getGreeterCache(ctx context.Context, key string, m *model.Greeter) (time.Duration, error) {
+	pipe := repo.Redis().Pipeline()
+	ttl := pipe.PTTL(ctx, key)
+	all := pipe.HGetAll(ctx, key)
+	// 逐个命令检查结果，PTTL失败时不提前刷新
+	pipe.Exec(ctx)
+
+	v, err := all.Result()
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, redis.Nil
+	}
+	if err := all.Scan(m); err != nil {
+		return 0, err
+	}
+	return ttl.Val(), nil
+}
+
+// loadGreeter 从主库读取Greeter并写入缓存，不存在时缓存空对象；从库的复制延迟会让旧数据在缓存中保留到过期
+func (repo greeterRepository) loadGreeter(ctx context.Context, id int32, key string, randExpire time.Duration) (model.Greeter, error) {
+	m, err := repo.findGreeterById(ctx, id, false)
 	if err != nil {
-		return m, errorsx.WithMessage(err, "greeterRepository.GetGreeterById")
+		return m, err
 	}
 
-	expire := constant.CacheMinute5 + opts.RandExpire
+	expire := constant.CacheMinute5 + randExpire
 	if m.IsEmpty() {
 		expire = constant.CacheMinute1
 	}
@@ -100,7 +158,7 @@ func (repo greeterRepository) FindGreeterById(ctx context.Context, id int32) (mo
 	defer span.Finish()
 
 	var m model.Greeter
-	err := repo.DB(ctx).Where("id = ?", id).First(&m).Error
+	err := repo.readDB(ctx).Where("id = ?", id).First(&m).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return m, nil
@@ -110,6 +168,139 @@ func (repo greeterRepository) FindGreeterById(ctx context.Context, id int32) (mo
 	return m, nil
 }
 
+func (repo greeterRepository) FindGreeterForUpdate(ctx context.Context, id int32) (model.Greeter, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.FindGreeterForUpdate")
+	defer span.Finish()
+
+	var m model.Greeter
+	err := repo.masterDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&m).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return m, nil
+		}
+		return m, errorsx.Wrap(err, "greeterRepository.FindGreeterForUpdate")
+	}
+	return m, nil
+}
+
+// findGreeterById 从主库读取，withDeleted为true时包含已软删除的记录
+func (repo greeterRepository) findGreeterById(ctx context.Context, id int32, withDeleted bool) (model.Greeter, error) {
+	tx := repo.masterDB(ctx)
+	if withDeleted {
+		tx = tx.Unscoped()
+	}
+
+	var m model.Greeter
+	err := tx.Where("id = ?", id).First(&m).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return m, nil
+		}
+		return m, errorsx.Wrap(err, "greeterRepository.findGreeterById")
+	}
+	return m, nil
+}
+
+// GetGreetersByIds 批量获取Greeter，通过一次Redis Pipeline读取缓存，未命中的id合并为一次IN查询，
+// 返回结果按ids的顺序排列，不存在的id通过notFound返回
+func (repo greeterRepository) GetGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, []int32, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.GetGreetersByIds")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "GetGreetersByIds"))
+
+	uniq := make([]int32, 0, len(ids))
+	seen := make(map[int32]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		uniq = append(uniq, id)
+	}
+
+	pipe := repo.Redis().Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(uniq))
+	for i, id := range uniq {
+		cmds[i] = pipe.HGetAll(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(id))))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Warn("redis.Pipeline HGetAll", zap.Int32s("ids", uniq), zap.Error(err))
+	}
+
+	found := make(map[int32]model.Greeter, len(uniq))
+	var misses []int32
+	for i, cmd := range cmds {
+		var m model.Greeter
+		if cmd.Err() != nil || len(cmd.Val()) == 0 || cmd.Scan(&m) != nil {
+			misses = append(misses, uniq[i])
+			continue
+		}
+		// 空对象是不存在的id的缓存
+		if !m.IsEmpty() {
+			found[uniq[i]] = m
+		}
+	}
+	logger.Debug("cache lookup", zap.Int("hits", len(uniq)-len(misses)), zap.Int32s("misses", misses))
+
+	if len(misses) > 0 {
+		list, err := findGreetersByIds(repo.masterDB(ctx), misses)
+		if err != nil {
+			return nil, nil, errorsx.WithMessage(err, "greeterRepository.GetGreetersByIds")
+		}
+		for _, m := range list {
+			found[m.Id] = m
+		}
+
+		pipe := repo.Redis().Pipeline()
+		for _, id := range misses {
+			key := utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
+			m, ok := found[id]
+			expire := constant.CacheMinute5 + util.RandDuration(120)
+			if !ok {
+				expire = constant.CacheMinute1
+			}
+			pipe.HMSet(ctx, key, redisx.FlatStruct(m))
+			pipe.Expire(ctx, key, expire)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.Warn("redis.Pipeline HMSet", zap.Int32s("ids", misses), zap.Error(err))
+		}
+	}
+
+	greeters := make([]model.Greeter, 0, len(ids))
+	var notFound []int32
+	for _, id := range ids {
+		if m, ok := found[id]; ok {
+			greeters = append(greeters, m)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return greeters, notFound, nil
+}
+
+func (repo greeterRepository) FindGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.FindGreetersByIds")
+	defer span.Finish()
+
+	list, err := findGreetersByIds(repo.readDB(ctx), ids)
+	return list, errorsx.WithMessage(err, "greeterRepository.FindGreetersByIds")
+}
+
+// findGreetersByIds 使用tx批量读取，写入缓存时tx应为主库
+func findGreetersByIds(tx *gorm.DB, ids []int32) ([]model.Greeter, error) {
+	var list []model.Greeter
+	if len(ids) == 0 {
+		return list, nil
+	}
+	if err := tx.Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, errorsx.Wrap(err, "findGreetersByIds")
+	}
+	return list, nil
+}
+
 func (repo greeterRepository) GetGreetersCount(ctx context.Context, status int32) (int64, error) {
 	span, ctx := tracing.StartSpan(ctx, "greeterRepository.GetGreetersCount")
 	defer span.Finish()
@@ -117,15 +308,58 @@ func (repo greeterRepository) GetGreetersCount(ctx context.Context, status int32
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "GetGreetersCount"))
 
 	key := utilx.CacheKey("greeter_cnt_", strconv.Itoa(int(status)))
-	cnt, err := redisx.GetNumber(ctx, repo.Redis(), key)
+	cnt, ttl, err := repo.getGreetersCountCache(ctx, key)
 	if err == nil {
+		greeterCountLoader.observe(cacheHit)
+		if greeterCountLoader.shouldRefresh(ttl) {
+			greeterCountLoader.refreshAsync(ctx, key, func(ctx context.Context) error {
+				_, err := repo.loadGreetersCount(ctx, logger, status, key)
+				return err
+			})
+		}
 		return cnt, nil
 	}
-	cnt, err = repo.FindGreetersCount(ctx, status)
+
+	greeterCountLoader.observe(cacheMiss)
+	v, err := greeterCountLoader.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		var cnt int64
+		err := repo.withCacheLock(ctx, key, func() bool {
+			var err error
+			cnt, _, err = repo.getGreetersCountCache(ctx, key)
+			return err == nil
+		}, func() (err error) {
+			cnt, err = repo.loadGreetersCount(ctx, logger, status, key)
+			return err
+		})
+		return cnt, err
+	})
 	if err != nil {
 		return 0, errorsx.WithMessage(err, "greeterRepository.GetGreetersCount")
 	}
-	err = repo.Redis().Set(ctx, key, cnt, constant.CacheMinute5).Err()
+	return v.(int64), nil
+}
+
+// getGreetersCountCache 读取总数缓存及其剩余的过期时间
+func (repo greeterRepository) getGreetersCountCache(ctx context.Context, key string) (int64, time.Duration, error) {
+	pipe := repo.Redis().Pipeline()
+	ttl := pipe.PTTL(ctx, key)
+	get := pipe.Get(ctx, key)
+	pipe.Exec(ctx)
+
+	cnt, err := get.Int64()
+	if err != nil {
+		return 0, 0, err
+	}
+	return cnt, ttl.Val(), nil
+}
+
+// loadGreetersCount 从主库读取总数并写入缓存
+func (repo greeterRepository) loadGreetersCount(ctx context.Context, logger *zap.Logger, status int32, key string) (int64, error) {
+	cnt, err := countGreeters(repo.masterDB(ctx), status)
+	if err != nil {
+		return 0, err
+	}
+	err = repo.Redis().Set(ctx, key, cnt, constant.CacheMinute5+util.RandDuration(60)).Err()
 	if err != nil {
 		logger.Error("redis.Set", zap.String("key", key), zap.Error(err))
 	}
@@ -133,34 +367,55 @@ func (repo greeterRepository) GetGreetersCount(ctx context.Context, status int32
 }
 
 func (repo greeterRepository) FindGreetersCount(ctx context.Context, status int32) (int64, error) {
+	count, err := countGreeters(repo.readDB(ctx), status)
+	return count, errorsx.WithMessage(err, "greeterRepository.FindGreetersCount")
+}
+
+// countGreeters 使用tx统计status下的数量，写入缓存时tx应为主库
+func countGreeters(tx *gorm.DB, status int32) (int64, error) {
 	var count int64
-	tx := repo.DB(ctx).Model(model.Greeter{}).Select("count(id)")
-	tx = tx.Where("status=?", status)
-	if err := tx.Count(&count).Error; err != nil {
-		return 0, errorsx.Wrap(err, "greeterRepository.FindGreetersCount")
+	if err := tx.Model(model.Greeter{}).Where("status=?", status).Count(&count).Error; err != nil {
+		return 0, errorsx.Wrap(err, "countGreeters")
 	}
 	return count, nil
 }
 
-func (repo greeterRepository) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) ([]model.Greeter, int, error) {
+func (repo greeterRepository) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32, opt ...repository.GreeterListOption) ([]model.Greeter, int, []repository.GreeterFailure, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "GetGreeterList"))
 
+	opts := repository.NewGreeterListOptions()
+	for _, o := range opt {
+		o(opts)
+	}
+	// 缓存中没有已删除的记录，因此直接查询数据库
+	if opts.WithDeleted {
+		var after *repository.GreeterListAfter
+		if lastId > 0 {
+			after = &repository.GreeterListAfter{Id: lastId}
+		}
+		filter := repository.GreeterListFilter{Statuses: []int32{status}, IncludeDeleted: true, SortBy: repository.GreeterSortById}
+		list, cnt, err := repo.FindGreeterList(ctx, filter, after, pageSize, page)
+		return list, cnt, nil, err
+	}
+
 	ids, cnt, err := repo.GetGreeterListIds(ctx, status, lastId, pageSize, page)
 	if err != nil {
-		return nil, 0, errorsx.WithMessage(err, "greeterRepository.GetGreeterList.GetGreeterListIds")
+		return nil, 0, nil, errorsx.WithMessage(err, "greeterRepository.GetGreeterList.GetGreeterListIds")
 	}
 
 	ctx1, cancel := context.WithTimeout(ctx, constant.CRequestTimeout)
 	defer cancel()
 
-	greeters, err := repo.GetGreeterList4Concurrent(ctx1, ids, repo.GetGreeterById)
-	logger.Debug("GetGreeterList4Concurrent", zap.Any("greeters", greeters), zap.Error(err))
-	if err != nil {
-		return nil, 0, errorsx.WithMessage(err, "greeterRepository.GetGreeterList.GetGreeterList4Concurrent")
+	greeters, failed := repo.GetGreeterList4Concurrent(ctx1, ids, repo.GetGreeterById)
+	logger.Debug("GetGreeterList4Concurrent", zap.Any("greeters", greeters), zap.Int("failed", len(failed)))
+	for _, f := range failed {
+		logger.Warn("GetGreeterList4Concurrent failed", zap.Int32("id", f.Id), zap.Error(f.Err))
 	}
-	return greeters, cnt, nil
+	return greeters, cnt, failed, nil
 }
 
+// GetGreeterListIds 优先从greeter_ids_有序集合分页，未命中时只查询当前页的id和总数，
+// 有序集合由后台分批重建，避免每次未命中都扫描status下全部的id
 func (repo greeterRepository) GetGreeterListIds(ctx context.Context, status, lastId, pageSize, page int32) ([]int32, int, error) {
 	key := utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))
 
@@ -169,146 +424,336 @@ func (repo greeterRepository) GetGreeterListIds(ctx context.Context, status, las
 		return ids, cnt, nil
 	}
 
-	ids, args, err := repo.FindGreeterListIds(ctx, status, lastId, pageSize)
+	ids, err = repo.FindGreeterListIds(ctx, status, lastId, pageSize, page)
 	if err != nil {
-		return nil, 0, errorsx.WithMessage(err, "greeterRepository.GetGreeterList")
+		return nil, 0, errorsx.WithMessage(err, "greeterRepository.GetGreeterListIds")
 	}
-	expire := constant.CacheMinute5 + util.RandDuration(120)
-	redisx.SetSortedSet(ctx, repo.Redis(), key, args, expire)
-	return ids, len(args), nil
+	total, err := repo.GetGreetersCount(ctx, status)
+	if err != nil {
+		return nil, 0, errorsx.WithMessage(err, "greeterRepository.GetGreeterListIds")
+	}
+	repo.rebuildGreeterListIdsAsync(ctx, status)
+	return ids, int(total), nil
 }
 
-func (repo greeterRepository) FindGreeterListIds(ctx context.Context, status, lastId, pageSize int32) ([]int32, []*redis.Z, error) {
+// FindGreeterListIds 按id倒序返回status下当前页的id，分页方式与redisx.ZRevRangeWithCard一致：
+// lastId大于0时取id小于lastId的pageSize个（keyset分页），否则跳过前(page-1)*pageSize个
+func (repo greeterRepository) FindGreeterListIds(ctx context.Context, status, lastId, pageSize, page int32) ([]int32, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.FindGreeterListIds")
+	defer span.Finish()
 
-	tx := repo.DB(ctx).Model(model.Greeter{}).Select("id")
-	tx = tx.Where("status=?", status)
-	tx = tx.Order("id DESC")
-	rows, err := tx.Rows()
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return []int32{}, []*redis.Z{}, nil
-		}
-		return nil, nil, errorsx.Wrap(err, "greeterRepository.FindGreeterListIds.Rows")
+	ids, err := findGreeterListIds(repo.readDB(ctx), status, lastId, pageSize, page)
+	return ids, errorsx.WithMessage(err, "greeterRepository.FindGreeterListIds")
+}
+
+// findGreeterListIds 使用tx读取当前页的id，重建缓存时tx应为主库
+func findGreeterListIds(tx *gorm.DB, status, lastId, pageSize, page int32) ([]int32, error) {
+	tx = tx.Model(model.Greeter{}).Where("status = ?", status)
+	if lastId > 0 {
+		tx = tx.Where("id < ?", lastId)
+	} else if page > 1 {
+		tx = tx.Offset(int((page - 1) * pageSize))
 	}
-	defer rows.Close()
+	tx = tx.Order("id DESC").Limit(int(pageSize))
 
-	var ids []int32
-	var args []*redis.Z
-	for rows.Next() {
-		var (
-			id int32
-		)
-		err = rows.Scan(&id)
-		if err != nil {
-			return nil, nil, errorsx.Wrap(err, "greeterRepository.FindGreeterListIds.Scan")
-		}
+	ids := []int32{}
+	if err := tx.Pluck("id", &ids).Error; err != nil {
+		return nil, errorsx.Wrap(err, "findGreeterListIds")
+	}
+	return ids, nil
+}
 
-		check := false
-		if lastId == 0 {
-			check = true
-		} else if lastId > id {
-			check = true
-		}
-		if check {
-			if len(ids) < int(pageSize) {
-				ids = append(ids, id)
-			}
-		}
-		args = append(args, &redis.Z{Score: float64(id), Member: id})
+// GetGreeterList4Concurrent 最多用pagination.concurrency个worker并发读取ids，结果按ids的顺序排列；
+// ctx结束后立即返回，不等待正在进行的读取，读取失败和尚未读取完成的id通过failed返回，不存在的Greeter不在结果中
+func (repo greeterRepository) GetGreeterList4Concurrent(ctx context.Context, ids []int32, fn func(context.Context, int32, ...repository.GreeterByIdOption) (model.Greeter, error)) ([]model.Greeter, []repository.GreeterFailure) {
+	count := len(ids)
+	workers := viper.GetInt("pagination.concurrency")
+	if workers <= 0 {
+		workers = constant.GreeterListConcurrency
 	}
-	if err = rows.Err(); err != nil {
-		return nil, nil, errorsx.Wrap(err, "greeterRepository.FindGreeterListIds.Err")
+	if workers > count {
+		workers = count
 	}
-	return ids, args, nil
-}
 
-func (repo greeterRepository) GetGreeterList4Concurrent(ctx context.Context, ids []int32, fn func(context.Context, int32, ...repository.GreeterByIdOption) (model.Greeter, error)) ([]model.Greeter, error) {
-	var wg sync.WaitGroup
+	// 两个channel的容量都足够，ctx结束后worker不会阻塞
+	jobs := make(chan int, count)
+	for idx := range ids {
+		jobs <- idx
+	}
+	close(jobs)
+	results := make(chan concurrentGreeterOutput, count)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for idx := range jobs {
+				if err := ctx.Err(); err != nil {
+					results <- concurrentGreeterOutput{idx: idx, err: err}
+					continue
+				}
+				greeter, err := fn(ctx, ids[idx])
+				results <- concurrentGreeterOutput{idx: idx, object: greeter, err: err}
+			}
+		}()
+	}
 
-	count := len(ids)
 	outputs := make([]*concurrentGreeterOutput, count)
-	wg.Add(count)
-
-	for idx, id := range ids {
-		go func(idx int, id int32, wg *sync.WaitGroup) {
-			defer wg.Done()
-			greeter, err := fn(ctx, id)
-			outputs[idx] = &concurrentGreeterOutput{
-				object: greeter,
-				err:    err,
-			}
-		}(idx, id, &wg)
+wait:
+	for received := 0; received < count; received++ {
+		select {
+		case output := <-results:
+			outputs[output.idx] = &output
+		case <-ctx.Done():
+			break wait
+		}
 	}
-	wg.Wait()
 
 	greeters := make([]model.Greeter, 0, count)
-	for _, output := range outputs {
-		if output.err == nil {
+	var failed []repository.GreeterFailure
+	for idx, output := range outputs {
+		switch {
+		case output == nil:
+			failed = append(failed, repository.GreeterFailure{Id: ids[idx], Err: ctx.Err()})
+		case output.err != nil:
+			failed = append(failed, repository.GreeterFailure{Id: ids[idx], Err: output.err})
+		case !output.object.IsEmpty():
 			greeters = append(greeters, output.object)
 		}
 	}
-	return greeters, nil
+	return greeters, failed
 }
 
 type concurrentGreeterOutput struct {
+	idx    int
 	object model.Greeter
 	err    error
 }
 
-func (repo greeterRepository) UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error) {
+// UpdateGreeter 只更新columns指定的列，m.Version大于0时作为期望的版本号，update_datetime由BeforeUpdate钩子维护
+func (repo greeterRepository) UpdateGreeter(ctx context.Context, m model.Greeter, columns []string) (int64, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.UpdateGreeter")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "UpdateGreeter"))
+
+	logger.Debug("invoke info", zap.Int32("id", m.Id), zap.Strings("columns", columns), zap.Int32("version", m.Version))
+	db := repo.DB(ctx)
+	values, err := columnValues(db, &m, columns)
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterRepository.UpdateGreeter")
+	}
+	values["version"] = increment("version", 1)
+
+	tx := versionScope(db.Model(&m), m.Version).Updates(values)
+	if tx.Error != nil {
+		return 0, errorsx.Wrap(tx.Error, "greeterRepository.UpdateGreeter")
+	}
+	affected := tx.RowsAffected
+	afterCommit(ctx, func(ctx context.Context) {
+		if affected > 0 {
+			repo.markWritten(ctx)
+		}
+		if _, ok := values["status"]; ok && affected > 0 {
+			repo.moveGreeterListCache(ctx, logger, m.Id, m.Status)
+		} else {
+			repo.delGreeterCache(ctx, logger, m.Id)
+		}
+	})
+	return tx.RowsAffected, repo.versionConflict(ctx, m.Id, m.Version, tx.RowsAffected, false)
+}
+
+// UpdateGreeterStatus 更新状态，同时记录操作人和变更时间，状态变更是否合法由领域层校验
+func (repo greeterRepository) UpdateGreeterStatus(ctx context.Context, id, status, version int32, operator string) (int64, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "UpdateGreeterStatus"))
 
-	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("status", status))
-	tx := repo.DB(ctx).Model(model.Greeter{}).Where("id = ?", id)
-	tx = tx.Update("status", status)
+	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("status", status), zap.Int32("version", version), zap.String("operator", operator))
+	values := map[string]interface{}{
+		"status":          status,
+		"status_operator": operator,
+		"status_datetime": time.Now().Format(util.DateTimeFmt),
+		"version":         increment("version", 1),
+	}
+	tx := repo.DB(ctx).Model(&model.Greeter{}).Where("id = ?", id)
+	tx = versionScope(tx, version).Updates(values)
 	if tx.Error != nil {
 		return 0, errorsx.Wrap(tx.Error, "greeterRepository.UpdateGreeterStatus")
 	}
-	key := utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
-	reply, err := repo.Redis().Del(ctx, key).Result()
-	if err != nil {
-		logger.Warn("Del Cache", zap.String("key", key), zap.Int64("reply", reply), zap.Error(err))
-	}
-	return tx.RowsAffected, nil
+	affected := tx.RowsAffected
+	afterCommit(ctx, func(ctx context.Context) {
+		if affected > 0 {
+			repo.markWritten(ctx)
+			repo.moveGreeterListCache(ctx, logger, id, status)
+		} else {
+			repo.delGreeterCache(ctx, logger, id)
+		}
+	})
+	return tx.RowsAffected, repo.versionConflict(ctx, id, version, tx.RowsAffected, false)
 }
 
-func (repo greeterRepository) UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error) {
+func (repo greeterRepository) UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "UpdateGreeterCount"))
 
-	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("num", num), zap.String("column", column))
-	tx := repo.DB(ctx).Model(model.Greeter{}).Where("id = ?", id)
-	tx = tx.Updates(map[string]interface{}{column: gorm.Expr(column+" + ?", num)})
+	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("num", num), zap.String("column", column), zap.Int32("version", version))
+	// column来自请求参数，只允许计数字段，并作为标识符引用而不是直接拼接到SQL中
+	if !repository.IsGreeterCounter(column) {
+		return 0, errorsx.Wrapf(repository.ErrCounterNotAllowed, "greeterRepository.UpdateGreeterCount %s", column)
+	}
+	tx := repo.DB(ctx).Model(&model.Greeter{}).Where("id = ?", id)
+	tx = versionScope(tx, version).Updates(map[string]interface{}{column: increment(column, int64(num)), "version": increment("version", 1)})
 	if tx.Error != nil {
 		return 0, errorsx.Wrap(tx.Error, "greeterRepository.UpdateGreeterCount")
 	}
-	key := utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
-	reply, err := repo.Redis().Del(ctx, key).Result()
-	if err != nil {
-		logger.Warn("Del Cache", zap.String("key", key), zap.Int64("reply", reply), zap.Error(err))
-	}
-	return tx.RowsAffected, nil
+	affected := tx.RowsAffected
+	afterCommit(ctx, func(ctx context.Context) {
+		if affected > 0 {
+			repo.markWritten(ctx)
+		}
+		repo.delGreeterCache(ctx, logger, id)
+	})
+	return tx.RowsAffected, repo.versionConflict(ctx, id, version, tx.RowsAffected, false)
 }
 
-func (repo greeterRepository) DeleteGreeterById(ctx context.Context, id int32) (int64, error) {
+// DeleteGreeterById 软删除，同时清除Greeter缓存以及所有状态下的列表缓存
+func (repo greeterRepository) DeleteGreeterById(ctx context.Context, id, version int32) (int64, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "DeleteGreeterById"))
 
-	logger.Debug("invoke info", zap.Int32("id", id))
-	tx := repo.DB(ctx).Delete(&model.Greeter{}, id)
+	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("version", version))
+	tx := repo.DB(ctx).Model(&model.Greeter{}).Where("id = ?", id)
+	tx = versionScope(tx, version).Updates(map[string]interface{}{"deleted_at": time.Now(), "version": increment("version", 1)})
 	if tx.Error != nil {
 		return 0, errorsx.Wrap(tx.Error, "greeterRepository.DeleteGreeterById")
 	}
+	if err := repo.versionConflict(ctx, id, version, tx.RowsAffected, false); err != nil {
+		return 0, err
+	}
+	if tx.RowsAffected > 0 {
+		afterCommit(ctx, func(ctx context.Context) {
+			repo.markWritten(ctx)
+			repo.delGreeterListCache(ctx, logger, id)
+		})
+	}
+	return tx.RowsAffected, nil
+}
+
+// RestoreGreeter 恢复已软删除的Greeter，记录未被删除时affected为0
+func (repo greeterRepository) RestoreGreeter(ctx context.Context, id, version int32) (int64, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "RestoreGreeter"))
+
+	logger.Debug("invoke info", zap.Int32("id", id), zap.Int32("version", version))
+	tx := repo.DB(ctx).Unscoped().Model(&model.Greeter{}).Where("id = ? AND deleted_at IS NOT NULL", id)
+	tx = versionScope(tx, version).Updates(map[string]interface{}{"deleted_at": nil, "version": increment("version", 1)})
+	if tx.Error != nil {
+		return 0, errorsx.Wrap(tx.Error, "greeterRepository.RestoreGreeter")
+	}
+	if err := repo.versionConflict(ctx, id, version, tx.RowsAffected, true); err != nil {
+		return 0, err
+	}
+	if tx.RowsAffected > 0 {
+		afterCommit(ctx, func(ctx context.Context) {
+			repo.markWritten(ctx)
+			repo.resetGreeterListCache(ctx, logger, id)
+		})
+	}
+	return tx.RowsAffected, nil
+}
+
+// PurgeDeletedGreeters 物理删除软删除时间早于before的记录，这些记录在软删除时已清除缓存
+func (repo greeterRepository) PurgeDeletedGreeters(ctx context.Context, before time.Time) (int64, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "PurgeDeletedGreeters"))
+
+	tx := repo.DB(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&model.Greeter{})
+	if tx.Error != nil {
+		return 0, errorsx.Wrap(tx.Error, "greeterRepository.PurgeDeletedGreeters")
+	}
+	logger.Info("purge deleted greeters", zap.Time("before", before), zap.Int64("affected", tx.RowsAffected))
+	return tx.RowsAffected, nil
+}
+
+func (repo greeterRepository) delGreeterCache(ctx context.Context, logger *zap.Logger, id int32) {
 	key := utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
 	reply, err := repo.Redis().Del(ctx, key).Result()
-	logger.Debug("Del Cache", zap.String("key", key), zap.Int64("reply", reply), zap.Error(err))
+	if err != nil {
+		logger.Warn("Del Cache", zap.String("key", key), zap.Int64("reply", reply), zap.Error(err))
+	}
+}
 
-	status := []int{0, 1}
-	for _, s := range status {
-		key := utilx.CacheKey("greeter_ids_", strconv.Itoa(s))
-		err := repo.Redis().ZRem(ctx, key, id).Err()
-		if err != nil {
-			logger.Warn("redis.ZRem", zap.String("key", key), zap.Int32("id", id), zap.Error(err))
-		}
+// delGreeterListCache 清除Greeter缓存，并从所有状态的id列表中移除，同时清除各状态的总数缓存
+func (repo greeterRepository) delGreeterListCache(ctx context.Context, logger *zap.Logger, id int32) {
+	pipe := repo.Redis().Pipeline()
+	pipe.Del(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(id))))
+	for s := 0; s <= constant.GreeterStatusMax; s++ {
+		pipe.ZRem(ctx, utilx.CacheKey("greeter_ids_", strconv.Itoa(s)), id)
+		touchGreeterListIds(ctx, pipe, s)
+		pipe.Del(ctx, utilx.CacheKey("greeter_cnt_", strconv.Itoa(s)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("redis.Pipeline delGreeterListCache", zap.Int32("id", id), zap.Error(err))
 	}
+}
 
-	return tx.RowsAffected, nil
+// resetGreeterListCache 清除Greeter缓存（包括不存在时写入的空对象），以及所有状态的id列表和总数缓存，
+// 由下次查询从数据库重建
+func (repo greeterRepository) resetGreeterListCache(ctx context.Context, logger *zap.Logger, id int32) {
+	pipe := repo.Redis().Pipeline()
+	pipe.Del(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(id))))
+	for s := 0; s <= constant.GreeterStatusMax; s++ {
+		pipe.Del(ctx, utilx.CacheKey("greeter_ids_", strconv.Itoa(s)), utilx.CacheKey("greeter_cnt_", strconv.Itoa(s)))
+		touchGreeterListIds(ctx, pipe, s)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("redis.Pipeline resetGreeterListCache", zap.Int32("id", id), zap.Error(err))
+	}
+}
+
+// versionConflict 带版本号的写操作没有影响任何行时，区分记录不存在与版本号不一致，
+// withDeleted为true时已软删除的记录也视为存在
+func (repo greeterRepository) versionConflict(ctx context.Context, id, version int32, affected int64, withDeleted bool) error {
+	if affected > 0 || version <= 0 {
+		return nil
+	}
+	m, err := repo.findGreeterById(ctx, id, withDeleted)
+	if err != nil {
+		return errorsx.WithMessage(err, "greeterRepository.versionConflict")
+	}
+	if m.IsEmpty() || m.Version == version {
+		return nil
+	}
+	return errorsx.Wrapf(repository.ErrVersionConflict, "greeter %d expect version %d, actual %d", id, version, m.Version)
+}
+
+// increment column加上num的表达式，列名按数据库的方言引用，num作为参数传递
+func increment(column string, num int64) clause.Expr {
+	return gorm.Expr("? + ?", clause.Column{Name: column}, num)
+}
+
+// versionScope version大于0时追加乐观锁条件
+func versionScope(tx *gorm.DB, version int32) *gorm.DB {
+	if version > 0 {
+		return tx.Where("version = ?", version)
+	}
+	return tx
+}
+
+// unscoped withDeleted为true时查询包含已软删除的记录
+func unscoped(tx *gorm.DB, withDeleted bool) *gorm.DB {
+	if withDeleted {
+		return tx.Unscoped()
+	}
+	return tx
+}
+
+// columnValues 从m中取出columns对应的值，用于按列更新
+func columnValues(db *gorm.DB, m *model.Greeter, columns []string) (map[string]interface{}, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(m); err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(m).Elem()
+	values := make(map[string]interface{}, len(columns)+1)
+	for _, column := range columns {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil {
+			return nil, fmt.Errorf("unknown column %s", column)
+		}
+		values[field.DBName], _ = field.ValueOf(rv)
+	}
+	return values, nil
 }
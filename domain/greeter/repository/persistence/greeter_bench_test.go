@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redis/v8"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fullScanGreeterListIds 缓存未命中时原有的做法：读取status下全部的id用于写入有序集合，在内存中过滤出当前页
+func fullScanGreeterListIds(db *gorm.DB, status, lastId, pageSize int32) ([]int32, []*redis.Z, error) {
+	rows, err := db.Model(model.Greeter{}).Select("id").Where("status=?", status).Order("id DESC").Rows()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ids []int32
+	var args []*redis.Z
+	for rows.Next() {
+		var id int32
+		if err = rows.Scan(&id); err != nil {
+			return nil, nil, err
+		}
+		if (lastId == 0 || lastId > id) && len(ids) < int(pageSize) {
+			ids = append(ids, id)
+		}
+		args = append(args, &redis.Z{Score: float64(id), Member: id})
+	}
+	return ids, args, rows.Err()
+}
+
+// BenchmarkFindGreeterListIds 对比status下有total条记录时，全量扫描与keyset分页查询一页id的开销
+func BenchmarkFindGreeterListIds(b *testing.B) {
+	const pageSize = 20
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: logger.Default.LogMode(logger.Silent), NamingStrategy: namingStrategy()})
+	if err != nil {
+		b.Fatal(err)
+	}
+	repo := greeterRepository{Dao: dao.NewDao(constant.DBName)}
+	repo.SetDBMock(gdb)
+
+	idRows := func(from, n int) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id"})
+		for id := from; id > from-n && id > 0; id-- {
+			rows.AddRow(id)
+		}
+		return rows
+	}
+
+	ctx := context.Background()
+	for _, total := range []int{1000, 10000, 100000} {
+		lastId := int32(total / 2)
+
+		b.Run(fmt.Sprintf("full-scan/%d", total), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mock.ExpectQuery("SELECT `id` FROM `tbl_greeter`").WillReturnRows(idRows(total, total))
+				b.StartTimer()
+
+				if _, _, err := fullScanGreeterListIds(gdb, 1, lastId, pageSize); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("keyset/%d", total), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mock.ExpectQuery("SELECT `id` FROM `tbl_greeter`").WillReturnRows(idRows(int(lastId)-1, pageSize))
+				b.StartTimer()
+
+				if _, err := repo.FindGreeterListIds(ctx, 1, lastId, pageSize, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
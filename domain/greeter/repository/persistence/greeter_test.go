@@ -3,8 +3,10 @@ package persistence
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/agiledragon/gomonkey"
 	"github.com/go-redis/redis/v8"
 	"github.com/go-redis/redismock/v8"
 	"github.com/imind-lab/greeter/domain/greeter/repository"
@@ -13,12 +15,15 @@ import (
 	utilx "github.com/imind-lab/greeter/pkg/util"
 	"github.com/imind-lab/micro/dao"
 	redisx "github.com/imind-lab/micro/redis"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -43,7 +48,7 @@ func (s *Suite) SetupSuite() {
 		Conn:                      db,
 		SkipInitializeWithVersion: true,
 	})
-	s.mysqlDB, err = gorm.Open(dialector, &gorm.Config{})
+	s.mysqlDB, err = gorm.Open(dialector, &gorm.Config{NamingStrategy: namingStrategy()})
 	require.NoError(s.T(), err)
 
 	s.redisDB, s.redisMock = redismock.NewClientMock()
@@ -104,12 +109,20 @@ func (s *Suite) TestGreeterRepository_CreateGreeter() {
 		s.Run(test.name, func() {
 			datetime := time.Now().Format("2006-01-02 15:04:05")
 			s.mysqlMock.ExpectBegin()
-			s.mysqlMock.ExpectExec("INSERT INTO `tbl_greeter`").WithArgs(test.data.Name, test.data.ViewNum, test.data.Status, test.data.CreateTime, datetime, datetime).WillReturnResult(sqlmock.NewResult(test.id, 1))
+			s.mysqlMock.ExpectExec("INSERT INTO `tbl_greeter`").WithArgs(test.data.Name, test.data.ViewNum, test.data.Status, test.data.CreateTime, datetime, datetime, 1, "", datetime, "", nil).WillReturnResult(sqlmock.NewResult(test.id, 1))
 			s.mysqlMock.ExpectCommit()
+			key := utilx.CacheKey("greeter_", strconv.Itoa(int(test.id)))
+			cached := test.data
+			cached.Id, cached.Version = int32(test.id), 1
+			cached.CreateDatetime, cached.UpdateDatetime, cached.StatusDatetime = datetime, datetime, datetime
+			s.redisMock.ExpectHMSet(key, redisx.FlatStruct(cached)).SetVal(true)
+			s.redisMock.ExpectExpire(key, constant.CacheMinute5).SetVal(true)
+			s.expectAddGreeterListCache(int32(test.id), test.data.Status)
 			m, err := s.repo.CreateGreeter(ctx, test.data)
 			require.NoError(s.T(), err)
 			require.EqualValues(s.T(), test.id, m.Id)
 			require.EqualValues(s.T(), datetime, m.CreateDatetime)
+			require.EqualValues(s.T(), 1, m.Version)
 		})
 	}
 }
@@ -140,6 +153,19 @@ func (s *Suite) TestGreeterRepository_FindGreeterById() {
 	}
 }
 
+func (s *Suite) TestGreeterRepository_FindGreeterForUpdate() {
+	rows := sqlmock.NewRows([]string{"id", "name", "status", "version"}).AddRow(100, "koofox", 1, 3)
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\? .* FOR UPDATE").WithArgs(100).WillReturnRows(rows)
+	actual, err := s.repo.FindGreeterForUpdate(context.Background(), 100)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), model.Greeter{Id: 100, Name: "koofox", Status: 1, Version: 3}, actual)
+
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\? .* FOR UPDATE").WithArgs(200).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	actual, err = s.repo.FindGreeterForUpdate(context.Background(), 200)
+	require.NoError(s.T(), err)
+	require.True(s.T(), actual.IsEmpty())
+}
+
 func (s *Suite) TestGreeterRepository_GetGreeterById() {
 	tests := []struct {
 		name     string
@@ -180,14 +206,15 @@ func (s *Suite) TestGreeterRepository_GetGreeterById() {
 		s.Run(test.name, func() {
 			key := utilx.CacheKey("greeter_" + strconv.Itoa(int(test.id)))
 			if test.data == nil {
+				s.redisMock.ExpectPTTL(key).SetVal(-2)
 				s.redisMock.ExpectHGetAll(key).RedisNil()
 				s.redisMock.ExpectHMSet(key, redisx.FlatStruct(test.val)).SetVal(true)
 				s.redisMock.ExpectExpire(key, constant.CacheMinute5).SetVal(true)
-
-				gomonkey.ApplyMethod(reflect.TypeOf(s.repo), "FindGreeterById", func(greeter greeterRepository, ctx context.Context, id int32) (model.Greeter, error) {
-					return test.val, nil
-				})
+				s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\?").WithArgs(test.id).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name", "view_num", "status"}).
+						AddRow(test.val.Id, test.val.Name, test.val.ViewNum, test.val.Status))
 			} else {
+				s.redisMock.ExpectPTTL(key).SetVal(constant.CacheMinute5)
 				s.redisMock.ExpectHGetAll(key).SetVal(test.data)
 			}
 
@@ -202,36 +229,65 @@ func (s *Suite) TestGreeterRepository_GetGreeterById() {
 func (s *Suite) TestGreeterRepository_FindGreeterListIds() {
 	tests := []struct {
 		name     string
+		sql      string
+		args     []driver.Value
 		rows     *sqlmock.Rows
 		status   int32
 		lastId   int32
 		pageSize int32
-		eptIds   []int32
-		eptRedis []*redis.Z
+		page     int32
+		expected []int32
 	}{
 		{"id-100",
-			sqlmock.NewRows([]string{"id"}).AddRow(100).AddRow(200).AddRow(300).AddRow(400).AddRow(500),
+			"SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 3$",
+			[]driver.Value{1},
+			sqlmock.NewRows([]string{"id"}).AddRow(500).AddRow(400).AddRow(300),
 			1,
 			0,
 			3,
-			[]int32{100, 200, 300},
-			[]*redis.Z{
-				{float64(100), int32(100)},
-				{float64(200), int32(200)},
-				{float64(300), int32(300)},
-				{float64(400), int32(400)},
-				{float64(500), int32(500)}},
+			1,
+			[]int32{500, 400, 300},
+		},
+		{"lastid-400",
+			"SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND id < \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 2$",
+			[]driver.Value{1, 400},
+			sqlmock.NewRows([]string{"id"}).AddRow(300).AddRow(200),
+			1,
+			400,
+			2,
+			1,
+			[]int32{300, 200},
+		},
+		// 与redisx.ZRevRangeWithCard一致，按page跳过前面的数据
+		{"page-2",
+			"SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 2 OFFSET 2$",
+			[]driver.Value{1},
+			sqlmock.NewRows([]string{"id"}).AddRow(300).AddRow(200),
+			1,
+			0,
+			2,
+			2,
+			[]int32{300, 200},
+		},
+		{"empty",
+			"SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY id DESC LIMIT 2$",
+			[]driver.Value{2},
+			sqlmock.NewRows([]string{"id"}),
+			2,
+			0,
+			2,
+			1,
+			[]int32{},
 		},
 	}
 
 	ctx := context.Background()
 	for _, test := range tests {
 		s.Run(test.name, func() {
-			s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter`").WithArgs(test.status).WillReturnRows(test.rows)
-			ids, zs, err := s.repo.FindGreeterListIds(ctx, test.status, test.lastId, test.pageSize)
+			s.mysqlMock.ExpectQuery(test.sql).WithArgs(test.args...).WillReturnRows(test.rows)
+			ids, err := s.repo.FindGreeterListIds(ctx, test.status, test.lastId, test.pageSize, test.page)
 			require.NoError(s.T(), err)
-			require.EqualValues(s.T(), test.eptIds, ids)
-			require.EqualValues(s.T(), test.eptRedis, zs)
+			require.EqualValues(s.T(), test.expected, ids)
 		})
 	}
 }
@@ -272,3 +328,373 @@ func (s *Suite) TestGreeterRepository_GetGreeterListIds() {
 		})
 	}
 }
+
+// TestGreeterRepository_GetGreeterListIdsMiss 未命中时只查询当前页和总数，有序集合在后台重建
+func (s *Suite) TestGreeterRepository_GetGreeterListIdsMiss() {
+	ctx := context.Background()
+	key := utilx.CacheKey("greeter_ids_", "1")
+	cntKey := utilx.CacheKey("greeter_cnt_", "1")
+
+	s.redisMock.ExpectType(key).SetVal("none")
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND id < \\? .* ORDER BY id DESC LIMIT 2$").
+		WithArgs(1, 400).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(300).AddRow(200))
+	s.redisMock.ExpectPTTL(cntKey).SetVal(-2)
+	s.redisMock.ExpectGet(cntKey).RedisNil()
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE status=\\?").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	s.redisMock.CustomMatch(anyExpire).ExpectSet(cntKey, int64(5), constant.CacheMinute5).SetVal("OK")
+
+	// 后台重建
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(500).AddRow(400).AddRow(300).AddRow(200).AddRow(100))
+	s.expectRebuildGreeterListIds(1, []int32{500, 400, 300, 200, 100})
+
+	ids, cnt, err := s.repo.GetGreeterListIds(ctx, 1, 400, 2, 1)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 5, cnt)
+	require.EqualValues(s.T(), []int32{300, 200}, ids)
+
+	if done, ok := greeterIdsRebuilding.Load(key); ok {
+		<-done.(chan struct{})
+	}
+}
+
+func (s *Suite) TestGreeterRepository_RebuildGreeterListIds() {
+	ctx := context.Background()
+
+	chunk := sqlmock.NewRows([]string{"id"})
+	members := make([]int32, 0, constant.GreeterIdsChunkSize+1)
+	for id := constant.GreeterIdsChunkSize + 1; id > 1; id-- {
+		chunk.AddRow(id)
+		members = append(members, int32(id))
+	}
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(1).WillReturnRows(chunk)
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? AND id < \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.expectRebuildGreeterListIds(1, members[:constant.GreeterIdsChunkSize], []int32{1})
+
+	cnt, err := s.repo.RebuildGreeterListIds(ctx, 1)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), constant.GreeterIdsChunkSize+1, cnt)
+}
+
+func (s *Suite) TestGreeterRepository_RebuildGreeterListIdsEmpty() {
+	ctx := context.Background()
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.expectRebuildGreeterListIds(2)
+
+	cnt, err := s.repo.RebuildGreeterListIds(ctx, 2)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, cnt)
+}
+
+// TestGreeterRepository_RebuildGreeterListIdsChanged 重建过程中有序集合被修改时放弃重建的结果
+func (s *Suite) TestGreeterRepository_RebuildGreeterListIdsChanged() {
+	ctx := context.Background()
+
+	s.redisMock.ExpectGet(greeterIdsVersionKey(1)).SetVal("7")
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.expectRenameGreeterListIds(1, "7", 0).SetVal(int64(0))
+
+	cnt, err := s.repo.RebuildGreeterListIds(ctx, 1)
+	require.True(s.T(), errors.Is(err, errGreeterIdsChanged))
+	require.Equal(s.T(), 0, cnt)
+}
+
+// expectRebuildGreeterListIds 读取版本号后每批id写入临时key，最后在版本号未变时RENAME为status的有序集合，临时key的名称带有时间戳
+func (s *Suite) expectRebuildGreeterListIds(status int32, chunks ...[]int32) {
+	key := utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))
+	tmp := func(expected, actual []interface{}) error {
+		name, ok := actual[1].(string)
+		if !ok || !strings.HasPrefix(name, key+"_rebuild_") {
+			return fmt.Errorf("expect temporary key of %s, but gave %v", key, actual)
+		}
+		if len(expected) != len(actual) || !reflect.DeepEqual(expected[2:], actual[2:]) {
+			return fmt.Errorf("expect %v, but gave %v", expected, actual)
+		}
+		return nil
+	}
+	s.redisMock.ExpectGet(greeterIdsVersionKey(int(status))).RedisNil()
+	total := 0
+	for _, ids := range chunks {
+		members := make([]*redis.Z, len(ids))
+		for i, id := range ids {
+			members[i] = &redis.Z{Score: float64(id), Member: id}
+		}
+		s.redisMock.CustomMatch(tmp).ExpectZAdd(key, members...).SetVal(int64(len(ids)))
+		s.redisMock.CustomMatch(tmp).ExpectExpire(key, constant.GreeterIdsRebuildTimeout).SetVal(true)
+		total += len(ids)
+	}
+	s.expectRenameGreeterListIds(status, "", total).SetVal(int64(1))
+}
+
+// expectRenameGreeterListIds 重建结束时比较版本号并RENAME，忽略临时key的名称和带随机值的过期时间
+func (s *Suite) expectRenameGreeterListIds(status int32, ver string, total int) *redismock.ExpectedCmd {
+	key := utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))
+	return s.redisMock.CustomMatch(func(expected, actual []interface{}) error {
+		name, ok := actual[3].(string)
+		if !ok || !strings.HasPrefix(name, key+"_rebuild_") ||
+			!reflect.DeepEqual(expected[4:7], actual[4:7]) || !reflect.DeepEqual(expected[8:], actual[8:]) {
+			return fmt.Errorf("expect rename to %s, but gave %v", key, actual)
+		}
+		return nil
+	}).ExpectEval(greeterIdsRenameScript, []string{key, key, greeterIdsVersionKey(int(status))}, ver, 0, total)
+}
+
+// anyExpire 只比较命令和key，忽略带随机值的过期时间
+func anyExpire(expected, actual []interface{}) error {
+	if !reflect.DeepEqual(expected[:2], actual[:2]) {
+		return fmt.Errorf("expect %v, but gave %v", expected, actual)
+	}
+	return nil
+}
+
+func (s *Suite) TestGreeterRepository_GetGreetersByIds() {
+	ctx := context.Background()
+	key := func(id int32) string {
+		return utilx.CacheKey("greeter_", strconv.Itoa(int(id)))
+	}
+
+	s.redisMock.ExpectHGetAll(key(300)).SetVal(map[string]string{})
+	s.redisMock.ExpectHGetAll(key(100)).SetVal(map[string]string{"id": "100", "name": "18601038090", "view_num": "1", "status": "1"})
+	s.redisMock.ExpectHGetAll(key(200)).SetVal(map[string]string{"id": "0"})
+	s.redisMock.ExpectHGetAll(key(400)).SetVal(map[string]string{})
+
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id IN \\(\\?,\\?\\)").WithArgs(300, 400).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "view_num", "status"}).AddRow(300, "18601038093", 3, 1))
+
+	found := model.Greeter{Id: 300, Name: "18601038093", ViewNum: 3, Status: 1}
+	s.redisMock.ExpectHMSet(key(300), redisx.FlatStruct(found)).SetVal(true)
+	s.redisMock.CustomMatch(anyExpire).ExpectExpire(key(300), constant.CacheMinute5).SetVal(true)
+	s.redisMock.ExpectHMSet(key(400), redisx.FlatStruct(model.Greeter{})).SetVal(true)
+	s.redisMock.ExpectExpire(key(400), constant.CacheMinute1).SetVal(true)
+
+	greeters, notFound, err := s.repo.GetGreetersByIds(ctx, []int32{300, 100, 200, 400, 100})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []model.Greeter{
+		found,
+		{Id: 100, Name: "18601038090", ViewNum: 1, Status: 1},
+		{Id: 100, Name: "18601038090", ViewNum: 1, Status: 1},
+	}, greeters)
+	require.Equal(s.T(), []int32{200, 400}, notFound)
+}
+
+func (s *Suite) TestGreeterRepository_UpdateGreeter() {
+	ctx := context.Background()
+	datetime := time.Now().Format("2006-01-02 15:04:05")
+	key := utilx.CacheKey("greeter_", "100")
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `name`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE version = \\? AND `tbl_greeter`.`deleted_at` IS NULL AND `id` = \\?").
+		WithArgs("koofox@imind.tech", datetime, 1, 3, 100).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectCommit()
+	s.redisMock.ExpectDel(key).SetVal(1)
+
+	affected, err := s.repo.UpdateGreeter(ctx, model.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 2, Version: 3}, []string{"name"})
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+
+	// 修改状态时在各状态的id列表之间移动
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `status`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE version = \\? AND `tbl_greeter`.`deleted_at` IS NULL AND `id` = \\?").
+		WithArgs(2, datetime, 1, 3, 100).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectCommit()
+	s.expectMoveGreeterListCache(100, 2)
+
+	affected, err = s.repo.UpdateGreeter(ctx, model.Greeter{Id: 100, Status: 2, Version: 3}, []string{"status"})
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+}
+
+// expectAddGreeterListCache 新建后将id加入所在状态的id列表，并清除该状态的总数缓存
+func (s *Suite) expectAddGreeterListCache(id, status int32) {
+	s.redisMock.ExpectEval(greeterIdsAddScript, []string{utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))}, id, id).SetVal(int64(1))
+	s.expectTouchGreeterListIds(int(status))
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_cnt_", strconv.Itoa(int(status)))).SetVal(1)
+}
+
+// expectTouchGreeterListIds 修改有序集合时增加版本号
+func (s *Suite) expectTouchGreeterListIds(status int) {
+	s.redisMock.ExpectIncr(greeterIdsVersionKey(status)).SetVal(1)
+	s.redisMock.ExpectExpire(greeterIdsVersionKey(status), constant.GreeterIdsRebuildTimeout*2).SetVal(true)
+}
+
+// expectMoveGreeterListCache 状态变更后将id从其他状态的id列表移到status的列表，并清除所有状态的总数缓存
+func (s *Suite) expectMoveGreeterListCache(id, status int32) {
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_", strconv.Itoa(int(id)))).SetVal(1)
+	for i := 0; i <= constant.GreeterStatusMax; i++ {
+		key := utilx.CacheKey("greeter_ids_", strconv.Itoa(i))
+		if int32(i) == status {
+			s.redisMock.ExpectEval(greeterIdsAddScript, []string{key}, id, id).SetVal(int64(1))
+		} else {
+			s.redisMock.ExpectZRem(key, id).SetVal(0)
+		}
+		s.expectTouchGreeterListIds(i)
+		s.redisMock.ExpectDel(utilx.CacheKey("greeter_cnt_", strconv.Itoa(i))).SetVal(1)
+	}
+}
+
+func (s *Suite) TestGreeterRepository_UpdateGreeterStatus() {
+	tests := []struct {
+		name     string
+		version  int32
+		affected int64
+		rows     *sqlmock.Rows
+		conflict bool
+	}{
+		{"unchecked", 0, 1, nil, false},
+		{"matched", 3, 1, nil, false},
+		{"conflict", 3, 0, sqlmock.NewRows([]string{"id", "name", "status", "version"}).AddRow(100, "18601038090", 1, 4), true},
+		{"not-found", 3, 0, sqlmock.NewRows([]string{"id"}), false},
+	}
+
+	ctx := context.Background()
+	key := utilx.CacheKey("greeter_", "100")
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			datetime := time.Now().Format("2006-01-02 15:04:05")
+			s.mysqlMock.ExpectBegin()
+			if test.version > 0 {
+				s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `status`=\\?,`status_datetime`=\\?,`status_operator`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE id = \\? AND version = \\? AND `tbl_greeter`.`deleted_at` IS NULL").
+					WithArgs(2, datetime, "koofox", datetime, 1, 100, test.version).WillReturnResult(sqlmock.NewResult(0, test.affected))
+			} else {
+				s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `status`=\\?,`status_datetime`=\\?,`status_operator`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE id = \\? AND `tbl_greeter`.`deleted_at` IS NULL").
+					WithArgs(2, datetime, "koofox", datetime, 1, 100).WillReturnResult(sqlmock.NewResult(0, test.affected))
+			}
+			s.mysqlMock.ExpectCommit()
+			if test.affected > 0 {
+				s.expectMoveGreeterListCache(100, 2)
+			} else {
+				s.redisMock.ExpectDel(key).SetVal(1)
+			}
+			if test.rows != nil {
+				s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter`").WithArgs(100).WillReturnRows(test.rows)
+			}
+
+			affected, err := s.repo.UpdateGreeterStatus(ctx, 100, 2, test.version, "koofox")
+			require.EqualValues(s.T(), test.affected, affected)
+			if test.conflict {
+				require.True(s.T(), errors.Is(err, repository.ErrVersionConflict))
+			} else {
+				require.NoError(s.T(), err)
+			}
+		})
+	}
+}
+
+func (s *Suite) TestGreeterRepository_DeleteGreeterById() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `deleted_at`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE id = \\? AND version = \\? AND `tbl_greeter`.`deleted_at` IS NULL").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 100, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mysqlMock.ExpectCommit()
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_", "100")).SetVal(1)
+	for status := 0; status <= constant.GreeterStatusMax; status++ {
+		s.redisMock.ExpectZRem(utilx.CacheKey("greeter_ids_", strconv.Itoa(status)), int32(100)).SetVal(1)
+		s.expectTouchGreeterListIds(status)
+		s.redisMock.ExpectDel(utilx.CacheKey("greeter_cnt_", strconv.Itoa(status))).SetVal(1)
+	}
+
+	affected, err := s.repo.DeleteGreeterById(ctx, 100, 3)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+}
+
+func (s *Suite) TestGreeterRepository_RestoreGreeter() {
+	tests := []struct {
+		name     string
+		affected int64
+		rows     *sqlmock.Rows
+		conflict bool
+	}{
+		{"restored", 1, nil, false},
+		{"conflict", 0, sqlmock.NewRows([]string{"id", "status", "version", "deleted_at"}).AddRow(100, 1, 4, time.Now()), true},
+		{"not-deleted", 0, sqlmock.NewRows([]string{"id", "status", "version", "deleted_at"}).AddRow(100, 1, 3, nil), false},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			datetime := time.Now().Format("2006-01-02 15:04:05")
+			s.mysqlMock.ExpectBegin()
+			s.mysqlMock.ExpectExec("UPDATE `tbl_greeter` SET `deleted_at`=\\?,`update_datetime`=\\?,`version`=`version` \\+ \\? WHERE \\(id = \\? AND deleted_at IS NOT NULL\\) AND version = \\?").
+				WithArgs(nil, datetime, 1, 100, 3).WillReturnResult(sqlmock.NewResult(0, test.affected))
+			s.mysqlMock.ExpectCommit()
+			if test.rows != nil {
+				s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\? ORDER BY").WithArgs(100).WillReturnRows(test.rows)
+			}
+			if test.affected > 0 {
+				s.redisMock.ExpectDel(utilx.CacheKey("greeter_", "100")).SetVal(1)
+				for status := 0; status <= constant.GreeterStatusMax; status++ {
+					s.redisMock.ExpectDel(utilx.CacheKey("greeter_ids_", strconv.Itoa(status)), utilx.CacheKey("greeter_cnt_", strconv.Itoa(status))).SetVal(2)
+					s.expectTouchGreeterListIds(status)
+				}
+			}
+
+			affected, err := s.repo.RestoreGreeter(ctx, 100, 3)
+			require.EqualValues(s.T(), test.affected, affected)
+			if test.conflict {
+				require.True(s.T(), errors.Is(err, repository.ErrVersionConflict))
+			} else {
+				require.NoError(s.T(), err)
+			}
+		})
+	}
+}
+
+func (s *Suite) TestGreeterRepository_PurgeDeletedGreeters() {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Hour * 24 * 30)
+
+	s.mysqlMock.ExpectBegin()
+	s.mysqlMock.ExpectExec("DELETE FROM `tbl_greeter` WHERE deleted_at < \\?").WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 5))
+	s.mysqlMock.ExpectCommit()
+
+	affected, err := s.repo.PurgeDeletedGreeters(ctx, before)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 5, affected)
+}
+
+func (s *Suite) TestGreeterRepository_GetGreeterList4Concurrent() {
+	viper.Set("pagination.concurrency", 2)
+	defer viper.Set("pagination.concurrency", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	var running, maxRunning int32
+	failure := errors.New("redis: connection refused")
+	fn := func(ctx context.Context, id int32, _ ...repository.GreeterByIdOption) (model.Greeter, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		switch id {
+		case 200:
+			return model.Greeter{}, failure
+		case 300:
+			return model.Greeter{}, nil
+		case 400:
+			// 不响应ctx的读取，超时后不再等待
+			time.Sleep(time.Millisecond * 200)
+		}
+		return model.Greeter{Id: id}, nil
+	}
+
+	start := time.Now()
+	greeters, failed := s.repo.GetGreeterList4Concurrent(ctx, []int32{100, 200, 300, 400, 500, 600}, fn)
+	require.Less(s.T(), int64(time.Since(start)), int64(time.Millisecond*150))
+	require.LessOrEqual(s.T(), atomic.LoadInt32(&maxRunning), int32(2))
+
+	// 不存在的300不在结果中，400读取期间另一个worker读取了500、600
+	require.Equal(s.T(), []model.Greeter{{Id: 100}, {Id: 500}, {Id: 600}}, greeters)
+	require.Equal(s.T(), []repository.GreeterFailure{{Id: 200, Err: failure}, {Id: 400, Err: context.DeadlineExceeded}}, failed)
+}
@@ -0,0 +1,189 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/util"
+)
+
+// greeterIdsRebuilding 正在后台重建的有序集合，key为缓存key，value在重建结束时关闭，
+// 保证同一进程内同一状态只有一个重建任务
+var greeterIdsRebuilding sync.Map
+
+// rebuildGreeterListIdsAsync 在后台重建status对应的greeter_ids_有序集合，已有重建任务时直接返回
+func (repo greeterRepository) rebuildGreeterListIdsAsync(ctx context.Context, status int32) {
+	key := utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))
+	done := make(chan struct{})
+	if _, loaded := greeterIdsRebuilding.LoadOrStore(key, done); loaded {
+		return
+	}
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "rebuildGreeterListIdsAsync"))
+	go func() {
+		defer close(done)
+		defer greeterIdsRebuilding.Delete(key)
+
+		ctx, cancel := context.WithTimeout(ctxzap.ToContext(context.Background(), logger), constant.GreeterIdsRebuildTimeout)
+		defer cancel()
+
+		cnt, err := repo.RebuildGreeterListIds(ctx, status)
+		if errorsx.Is(err, errGreeterIdsChanged) {
+			logger.Debug("greeter ids changed during rebuild", zap.Int32("status", status))
+			return
+		}
+		if err != nil {
+			logger.Warn("rebuild greeter ids failed", zap.Int32("status", status), zap.Error(err))
+			return
+		}
+		logger.Debug("rebuild greeter ids", zap.Int32("status", status), zap.Int("count", cnt))
+	}()
+}
+
+// errGreeterIdsChanged 重建过程中有序集合被修改，重建的结果可能缺少这些修改，因此放弃
+var errGreeterIdsChanged = errorsx.New("greeter ids changed during rebuild")
+
+// greeterIdsRenameScript 有序集合的版本号与重建开始时一致时将临时key RENAME为有序集合，
+// 没有id时缓存为空字符串，与redisx.SetSortedSet一致；版本号不一致时删除临时key并返回0
+var greeterIdsRenameScript = `if (redis.call('GET', KEYS[3]) or '') ~= ARGV[1] then
+	redis.call('DEL', KEYS[1])
+	return 0
+end
+if tonumber(ARGV[3]) == 0 then
+	redis.call('SET', KEYS[2], '', 'PX', ARGV[2])
+else
+	redis.call('RENAME', KEYS[1], KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], ARGV[2])
+end
+return 1`
+
+// RebuildGreeterListIds 按id倒序以keyset方式分批读取status下全部的id，写入临时key后RENAME为
+// greeter_ids_有序集合，重建过程中读请求看到的仍是旧的集合或未命中，返回写入的id数量。
+// 重建过程中其他请求修改了有序集合（版本号改变）时放弃重建的结果，返回errGreeterIdsChanged
+func (repo greeterRepository) RebuildGreeterListIds(ctx context.Context, status int32) (int, error) {
+	key := utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))
+	tmp := key + "_rebuild_" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	verKey := greeterIdsVersionKey(int(status))
+
+	cli := repo.Redis()
+	// 先读取版本号再查询数据库，之后提交的修改都会改变版本号
+	ver, err := cli.Get(ctx, verKey).Result()
+	if err != nil && err != redis.Nil {
+		return 0, errorsx.Wrap(err, "greeterRepository.RebuildGreeterListIds.Get")
+	}
+
+	var (
+		lastId int32
+		total  int
+	)
+	for {
+		ids, err := findGreeterListIds(repo.masterDB(ctx), status, lastId, constant.GreeterIdsChunkSize, 1)
+		if err != nil {
+			cli.Del(ctx, tmp)
+			return 0, errorsx.WithMessage(err, "greeterRepository.RebuildGreeterListIds")
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		members := make([]*redis.Z, len(ids))
+		for i, id := range ids {
+			members[i] = &redis.Z{Score: float64(id), Member: id}
+		}
+		pipe := cli.Pipeline()
+		pipe.ZAdd(ctx, tmp, members...)
+		pipe.Expire(ctx, tmp, constant.GreeterIdsRebuildTimeout)
+		if _, err := pipe.Exec(ctx); err != nil {
+			cli.Del(ctx, tmp)
+			return 0, errorsx.Wrap(err, "greeterRepository.RebuildGreeterListIds.ZAdd")
+		}
+
+		total += len(ids)
+		lastId = ids[len(ids)-1]
+		if len(ids) < constant.GreeterIdsChunkSize {
+			break
+		}
+	}
+
+	expire := constant.CacheMinute5 + util.RandDuration(120)
+	renamed, err := cli.Eval(ctx, greeterIdsRenameScript, []string{tmp, key, verKey}, ver, expire.Milliseconds(), total).Int()
+	if err != nil {
+		cli.Del(ctx, tmp)
+		return 0, errorsx.Wrap(err, "greeterRepository.RebuildGreeterListIds.Rename")
+	}
+	if renamed == 0 {
+		return 0, errorsx.WithMessage(errGreeterIdsChanged, "greeterRepository.RebuildGreeterListIds")
+	}
+	return total, nil
+}
+
+// greeterIdsVersionKey status对应有序集合的版本号，每次修改有序集合时加1，重建时用来判断是否有并发的修改
+func greeterIdsVersionKey(status int) string {
+	return utilx.CacheKey("greeter_ids_ver_", strconv.Itoa(status))
+}
+
+// touchGreeterListIds 在pipe中增加status对应有序集合的版本号，与修改有序集合的命令一起执行；
+// 过期时间长于重建的超时时间，重建过程中版本号不会过期
+func touchGreeterListIds(ctx context.Context, pipe redis.Pipeliner, status int) {
+	key := greeterIdsVersionKey(status)
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, constant.GreeterIdsRebuildTimeout*2)
+}
+
+// greeterIdsAddScript 只在有序集合已缓存时加入id；缓存为空列表（空字符串）时删除，由下次查询重建；
+// 未缓存时不写入，避免只包含一个id的集合被当作完整的列表
+var greeterIdsAddScript = `local t = redis.call('TYPE', KEYS[1]).ok
+if t == 'zset' then
+	return redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+end
+if t ~= 'none' then
+	redis.call('DEL', KEYS[1])
+end
+return 0`
+
+// addGreeterListCache 新建Greeter后加入status对应的id列表，并清除该状态的总数缓存
+func (repo greeterRepository) addGreeterListCache(ctx context.Context, logger *zap.Logger, id, status int32) {
+	pipe := repo.Redis().Pipeline()
+	pipe.Eval(ctx, greeterIdsAddScript, []string{utilx.CacheKey("greeter_ids_", strconv.Itoa(int(status)))}, id, id)
+	touchGreeterListIds(ctx, pipe, int(status))
+	pipe.Del(ctx, utilx.CacheKey("greeter_cnt_", strconv.Itoa(int(status))))
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("redis.Pipeline addGreeterListCache", zap.Int32("id", id), zap.Int32("status", status), zap.Error(err))
+	}
+}
+
+// moveGreeterListCache 状态变更后清除Greeter缓存，将id从其他状态的id列表移到status对应的列表，
+// 并清除所有状态的总数缓存；变更前的状态未知，因此从所有其他状态的列表中移除
+func (repo greeterRepository) moveGreeterListCache(ctx context.Context, logger *zap.Logger, id, status int32) {
+	pipe := repo.Redis().Pipeline()
+	pipe.Del(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(id))))
+	for s := 0; s <= constant.GreeterStatusMax; s++ {
+		key := utilx.CacheKey("greeter_ids_", strconv.Itoa(s))
+		if int32(s) == status {
+			pipe.Eval(ctx, greeterIdsAddScript, []string{key}, id, id)
+		} else {
+			pipe.ZRem(ctx, key, id)
+		}
+		touchGreeterListIds(ctx, pipe, s)
+		pipe.Del(ctx, utilx.CacheKey("greeter_cnt_", strconv.Itoa(s)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("redis.Pipeline moveGreeterListCache", zap.Int32("id", id), zap.Int32("status", status), zap.Error(err))
+	}
+}
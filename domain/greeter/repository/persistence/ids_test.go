@@ -0,0 +1,78 @@
+package persistence
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+)
+
+// TestGreeterRepository_RebuildGreeterListIdsRace 重建过程中修改有序集合时放弃重建的结果，避免RENAME覆盖这次修改
+func TestGreeterRepository_RebuildGreeterListIdsRace(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	d := greeterDao{Dao: dao.NewDao(constant.DBName), db: db}
+	d.SetRedisMock(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	repo := greeterRepository{Dao: d}
+
+	var ids []int32
+	for _, name := range []string{"koofox", "foxkoo"} {
+		created, err := repo.CreateGreeter(ctx, model.Greeter{Name: name, Status: 1})
+		require.NoError(t, err)
+		ids = append(ids, created.Id)
+	}
+	key := utilx.CacheKey("greeter_ids_", "1")
+
+	// 读取id之后、RENAME之前，另一个请求将第一个Greeter改为状态2
+	query := db.Callback().Query()
+	moved := false
+	require.NoError(t, query.After("gorm:query").Register("test:move", func(tx *gorm.DB) {
+		if moved {
+			return
+		}
+		moved = true
+		require.NoError(t, tx.Session(&gorm.Session{NewDB: true}).Model(&model.Greeter{}).Where("id = ?", ids[0]).Update("status", 2).Error)
+		repo.moveGreeterListCache(ctx, zap.NewNop(), ids[0], 2)
+	}))
+	defer query.Remove("test:move")
+
+	_, err = repo.RebuildGreeterListIds(ctx, 1)
+	require.True(t, errors.Is(err, errGreeterIdsChanged))
+	require.False(t, mr.Exists(key))
+	for _, k := range mr.Keys() {
+		require.NotContains(t, k, "_rebuild_", "temporary key is removed")
+	}
+
+	// 没有并发修改时重建成功
+	cnt, err := repo.RebuildGreeterListIds(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, cnt)
+	members, err := mr.ZMembers(key)
+	require.NoError(t, err)
+	require.Equal(t, []string{strconv.Itoa(int(ids[1]))}, members)
+
+	// 没有id时缓存为空列表
+	cnt, err = repo.RebuildGreeterListIds(ctx, 3)
+	require.NoError(t, err)
+	require.Zero(t, cnt)
+	empty, err := mr.Get(utilx.CacheKey("greeter_ids_", "3"))
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
@@ -0,0 +1,101 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	errorsx "github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/tracing"
+)
+
+// greeterSortColumns 允许排序的字段，排序字段会拼接到SQL中，只能使用其中的值
+var greeterSortColumns = map[repository.GreeterSortField]string{
+	repository.GreeterSortById:         "id",
+	repository.GreeterSortByViewNum:    "view_num",
+	repository.GreeterSortByCreateTime: "create_time",
+}
+
+// likeEscaper 转义LIKE中的通配符，转义符为!：SQLite没有默认的转义符，而反斜杠在MySQL和PostgreSQL的字符串中含义不同
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// nameLike name匹配LIKE模式的条件，PostgreSQL使用ILIKE，与MySQL默认的排序规则以及SQLite一样不区分大小写
+func nameLike(db *gorm.DB) string {
+	if db.Dialector.Name() == constant.DBDriverPostgres {
+		return "name ILIKE ? ESCAPE '!'"
+	}
+	return "name LIKE ? ESCAPE '!'"
+}
+
+func (repo greeterRepository) FindGreeterList(ctx context.Context, filter repository.GreeterListFilter, after *repository.GreeterListAfter, pageSize, page int32) ([]model.Greeter, int, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.FindGreeterList")
+	defer span.Finish()
+
+	column, ok := greeterSortColumns[filter.SortBy]
+	if !ok {
+		return nil, 0, errorsx.Errorf("greeterRepository.FindGreeterList unknown sort field %q", filter.SortBy)
+	}
+	direction, op := "DESC", "<"
+	if filter.Ascending {
+		direction, op = "ASC", ">"
+	}
+
+	var count int64
+	if err := greeterListScope(repo.DB(ctx), filter).Model(&model.Greeter{}).Count(&count).Error; err != nil {
+		return nil, 0, errorsx.Wrap(err, "greeterRepository.FindGreeterList.Count")
+	}
+
+	tx := greeterListScope(repo.DB(ctx), filter)
+	if after != nil {
+		if column == "id" {
+			tx = tx.Where("id "+op+" ?", after.Id)
+		} else {
+			tx = tx.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op), after.Value, after.Value, after.Id)
+		}
+	} else if page > 1 {
+		tx = tx.Offset(int((page - 1) * pageSize))
+	}
+	order := "id " + direction
+	if column != "id" {
+		order = column + " " + direction + ", " + order
+	}
+
+	list := []model.Greeter{}
+	if err := tx.Order(order).Limit(int(pageSize)).Find(&list).Error; err != nil {
+		return nil, 0, errorsx.Wrap(err, "greeterRepository.FindGreeterList")
+	}
+	return list, int(count), nil
+}
+
+// greeterListScope 追加filter中的过滤条件
+func greeterListScope(tx *gorm.DB, filter repository.GreeterListFilter) *gorm.DB {
+	tx = unscoped(tx, filter.IncludeDeleted).Where("status IN ?", filter.Statuses)
+	if filter.NamePrefix != "" {
+		tx = tx.Where(nameLike(tx), likeEscaper.Replace(filter.NamePrefix)+"%")
+	}
+	if filter.NameContains != "" {
+		tx = tx.Where(nameLike(tx), "%"+likeEscaper.Replace(filter.NameContains)+"%")
+	}
+	if filter.CreateTimeFrom > 0 {
+		tx = tx.Where("create_time >= ?", filter.CreateTimeFrom)
+	}
+	if filter.CreateTimeTo > 0 {
+		tx = tx.Where("create_time < ?", filter.CreateTimeTo)
+	}
+	if filter.MinViewNum > 0 {
+		tx = tx.Where("view_num >= ?", filter.MinViewNum)
+	}
+	return tx
+}
@@ -0,0 +1,96 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+func (s *Suite) TestGreeterRepository_FindGreeterList() {
+	tests := []struct {
+		name     string
+		filter   repository.GreeterListFilter
+		after    *repository.GreeterListAfter
+		page     int32
+		count    string
+		query    string
+		args     []driver.Value
+		pageArgs []driver.Value
+		rows     *sqlmock.Rows
+		cnt      int
+		expected []model.Greeter
+	}{
+		{"statuses-view-num",
+			repository.GreeterListFilter{Statuses: []int32{1, 2}, MinViewNum: 5, SortBy: repository.GreeterSortByViewNum},
+			nil,
+			1,
+			"WHERE status IN \\(\\?,\\?\\) AND view_num >= \\? AND `tbl_greeter`.`deleted_at` IS NULL$",
+			"WHERE status IN \\(\\?,\\?\\) AND view_num >= \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY view_num DESC, id DESC LIMIT 3$",
+			[]driver.Value{1, 2, 5},
+			nil,
+			sqlmock.NewRows([]string{"id", "view_num", "status"}).AddRow(300, 9, 1).AddRow(500, 7, 2),
+			2,
+			[]model.Greeter{{Id: 300, ViewNum: 9, Status: 1}, {Id: 500, ViewNum: 7, Status: 2}},
+		},
+		{"after-view-num",
+			repository.GreeterListFilter{Statuses: []int32{1}, SortBy: repository.GreeterSortByViewNum},
+			&repository.GreeterListAfter{Value: 7, Id: 500},
+			1,
+			"WHERE status IN \\(\\?\\) AND `tbl_greeter`.`deleted_at` IS NULL$",
+			"WHERE status IN \\(\\?\\) AND \\(\\(view_num < \\? OR \\(view_num = \\? AND id < \\?\\)\\)\\) AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY view_num DESC, id DESC LIMIT 3$",
+			[]driver.Value{1},
+			[]driver.Value{7, 7, 500},
+			sqlmock.NewRows([]string{"id", "view_num", "status"}).AddRow(400, 7, 1),
+			3,
+			[]model.Greeter{{Id: 400, ViewNum: 7, Status: 1}},
+		},
+		{"name-create-time-asc",
+			repository.GreeterListFilter{Statuses: []int32{1}, NamePrefix: "k_o%", CreateTimeFrom: 100, CreateTimeTo: 200,
+				SortBy: repository.GreeterSortByCreateTime, Ascending: true},
+			nil,
+			2,
+			"WHERE status IN \\(\\?\\) AND name LIKE \\? ESCAPE '!' AND create_time >= \\? AND create_time < \\? AND `tbl_greeter`.`deleted_at` IS NULL$",
+			"WHERE status IN \\(\\?\\) AND name LIKE \\? ESCAPE '!' AND create_time >= \\? AND create_time < \\? AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY create_time ASC, id ASC LIMIT 3 OFFSET 3$",
+			[]driver.Value{1, `k!_o!%%`, 100, 200},
+			nil,
+			sqlmock.NewRows([]string{"id", "create_time", "status"}).AddRow(100, 150, 1),
+			4,
+			[]model.Greeter{{Id: 100, CreateTime: 150, Status: 1}},
+		},
+		{"deleted-after-id",
+			repository.GreeterListFilter{Statuses: []int32{1}, NameContains: "fox", IncludeDeleted: true, SortBy: repository.GreeterSortById},
+			&repository.GreeterListAfter{Id: 400},
+			1,
+			"WHERE status IN \\(\\?\\) AND name LIKE \\? ESCAPE '!'$",
+			"WHERE status IN \\(\\?\\) AND name LIKE \\? ESCAPE '!' AND id < \\? ORDER BY id DESC LIMIT 3$",
+			[]driver.Value{1, "%fox%"},
+			[]driver.Value{400},
+			sqlmock.NewRows([]string{"id", "status"}).AddRow(300, 1),
+			1,
+			[]model.Greeter{{Id: 300, Status: 1}},
+		},
+	}
+
+	ctx := context.Background()
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` " + test.count).WithArgs(test.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(test.cnt))
+			s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` " + test.query).WithArgs(append(test.args, test.pageArgs...)...).
+				WillReturnRows(test.rows)
+
+			list, cnt, err := s.repo.FindGreeterList(ctx, test.filter, test.after, 3, test.page)
+			require.NoError(s.T(), err)
+			require.Equal(s.T(), test.cnt, cnt)
+			require.Equal(s.T(), test.expected, list)
+		})
+	}
+
+	_, _, err := s.repo.FindGreeterList(ctx, repository.GreeterListFilter{Statuses: []int32{1}, SortBy: "name; DROP TABLE tbl_greeter"}, nil, 3, 1)
+	require.Error(s.T(), err)
+}
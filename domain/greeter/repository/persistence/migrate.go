@@ -0,0 +1,483 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/util"
+)
+
+// migrationFS 每种数据库一个目录，文件名为<版本>_<名称>.up.sql和<版本>_<名称>.down.sql，表名使用{prefix}前缀
+//
+//go:embed migrations
+var migrationFS embed.FS
+
+// migrationDrivers 需要提供迁移文件的数据库，与db.<name>.driver的取值一致
+var migrationDrivers = []string{constant.DBDriverMySQL, constant.DBDriverSQLite, constant.DBDriverPostgres}
+
+var (
+	migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// ErrSchemaBehind 数据库还有未执行的迁移
+var ErrSchemaBehind = errorsx.New("greeter schema is behind")
+
+// ErrMigrationLocked 其他实例正在执行迁移，等待超时
+var ErrMigrationLocked = errorsx.New("greeter migration is locked")
+
+// ErrMigrationLockTimeout 等待超时时没有实例持有迁移锁，一直未能插入锁记录
+var ErrMigrationLockTimeout = errorsx.New("greeter migration lock timeout")
+
+// Migration 一个版本的迁移，Up和Down为替换表前缀后的SQL
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus 迁移的执行状态，数据库中有记录但没有迁移文件时Name为记录中的名称
+type MigrationStatus struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// appliedMigration 迁移历史表中的一条记录
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt string
+}
+
+// Migrator 执行嵌入的版本化迁移，已执行的版本记录在<prefix>greeter_migration中，
+// 执行前通过<prefix>greeter_migration_lock中的一行加锁，避免多个实例同时迁移
+type Migrator struct {
+	db          *gorm.DB
+	prefix      string
+	migrations  []Migration
+	lockTimeout time.Duration
+	lockRefresh time.Duration
+	owner       string
+}
+
+// NewMigrator 按db.<name>.driver加载对应数据库的迁移文件，使用与仓库相同的数据库连接和表名前缀
+func NewMigrator() (*Migrator, error) {
+	return newMigrator(newDao().DB(context.Background()), tablePrefix())
+}
+
+func newMigrator(db *gorm.DB, prefix string) (*Migrator, error) {
+	migrations, err := loadMigrations(db.Dialector.Name(), prefix)
+	if err != nil {
+		return nil, err
+	}
+	lockTimeout := viper.GetDuration("migration.lock_timeout")
+	if lockTimeout <= 0 {
+		lockTimeout = constant.MigrationLockTimeout
+	}
+	host, _ := os.Hostname()
+	return &Migrator{
+		db:          db,
+		prefix:      prefix,
+		migrations:  migrations,
+		lockTimeout: lockTimeout,
+		lockRefresh: constant.MigrationLockRefresh,
+		owner:       fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}, nil
+}
+
+// loadMigrations 读取driver目录下的迁移文件，每个版本必须同时有up和down
+func loadMigrations(driver, prefix string) ([]Migration, error) {
+	dir := "migrations/" + driver
+	entries, err := fs.ReadDir(migrationFS, dir)
+	if err != nil {
+		return nil, errorsx.Wrapf(err, "no migrations for driver %s", driver)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(migrationFS, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, errorsx.Wrap(err, "loadMigrations")
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, errorsx.Errorf("migration %d has different names %s and %s", version, m.Name, match[2])
+		}
+		sql := strings.ReplaceAll(string(content), "{prefix}", prefix)
+		if match[3] == "up" {
+			m.Up = sql
+		} else {
+			m.Down = sql
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, errorsx.Errorf("migration %04d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// conn 迁移总是使用主库
+func (m *Migrator) conn(ctx context.Context) *gorm.DB {
+	return m.db.WithContext(ctx).Clauses(dbresolver.Write)
+}
+
+func (m *Migrator) historyTable() string {
+	return m.prefix + "greeter_migration"
+}
+
+func (m *Migrator) lockTable() string {
+	return m.prefix + "greeter_migration_lock"
+}
+
+// Pending 按版本从小到大返回未执行的迁移
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// Applied 按版本从大到小返回已执行的迁移，即down的执行顺序
+func (m *Migrator) Applied(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var list []Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if _, ok := applied[m.migrations[i].Version]; ok {
+			list = append(list, m.migrations[i])
+		}
+	}
+	if len(list) != len(applied) {
+		return nil, errorsx.Errorf("%s has versions without migration files", m.historyTable())
+	}
+	return list, nil
+}
+
+// Status 返回全部迁移文件以及数据库中记录的版本的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			delete(applied, mig.Version)
+		}
+		list = append(list, status)
+	}
+	for _, a := range applied {
+		list = append(list, MigrationStatus{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: a.AppliedAt})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list, nil
+}
+
+// Check 有未执行的迁移时返回ErrSchemaBehind
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return errorsx.Wrapf(ErrSchemaBehind, "%d pending migrations, run greeter migrate up", len(pending))
+	}
+	return nil
+}
+
+// Up 加锁后按版本从小到大执行未执行的迁移，steps大于0时最多执行steps个，返回执行成功的迁移
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	return m.run(ctx, steps, true)
+}
+
+// Down 加锁后按版本从大到小回滚已执行的迁移，steps大于0时最多回滚steps个，返回回滚成功的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	return m.run(ctx, steps, false)
+}
+
+func (m *Migrator) run(ctx context.Context, steps int, up bool) ([]Migration, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "Migrator"), zap.String("func", "run"))
+
+	if err := m.createTables(ctx); err != nil {
+		return nil, err
+	}
+	ctx, unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// 加锁后重新读取，其他实例可能已经执行了部分迁移
+	var plan []Migration
+	if up {
+		plan, err = m.Pending(ctx)
+	} else {
+		plan, err = m.Applied(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if steps > 0 && len(plan) > steps {
+		plan = plan[:steps]
+	}
+
+	done := make([]Migration, 0, len(plan))
+	for _, mig := range plan {
+		if err := m.apply(ctx, mig, up); err != nil {
+			return done, errorsx.Wrapf(err, "migration %04d_%s", mig.Version, mig.Name)
+		}
+		logger.Info("migration applied", zap.Int64("version", mig.Version), zap.String("name", mig.Name), zap.Bool("up", up))
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// apply 在事务中执行迁移并更新历史表；MySQL的DDL会隐式提交，失败时需要按错误信息手工处理
+func (m *Migrator) apply(ctx context.Context, mig Migration, up bool) error {
+	sql := mig.Down
+	if up {
+		sql = mig.Up
+	}
+	return m.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range Statements(sql) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if up {
+			return tx.Exec("INSERT INTO "+m.historyTable()+" (version, name, applied_at) VALUES (?, ?, ?)",
+				mig.Version, mig.Name, time.Now().Format(util.DateTimeFmt)).Error
+		}
+		return tx.Exec("DELETE FROM "+m.historyTable()+" WHERE version = ?", mig.Version).Error
+	})
+}
+
+// applied 读取迁移历史，历史表不存在时视为没有执行过迁移，dry-run和status不会创建表
+func (m *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	applied := make(map[int64]appliedMigration)
+	if !m.conn(ctx).Migrator().HasTable(m.historyTable()) {
+		return applied, nil
+	}
+	var rows []appliedMigration
+	if err := m.conn(ctx).Raw("SELECT version, name, applied_at FROM " + m.historyTable()).Scan(&rows).Error; err != nil {
+		return nil, errorsx.Wrap(err, "Migrator.applied")
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// createTables 创建迁移历史表和锁表，只使用三种数据库通用的类型
+func (m *Migrator) createTables(ctx context.Context) error {
+	stmts := []string{
+		"CREATE TABLE IF NOT EXISTS " + m.historyTable() + " (version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at VARCHAR(19) NOT NULL)",
+		"CREATE TABLE IF NOT EXISTS " + m.lockTable() + " (id INT NOT NULL PRIMARY KEY, owner VARCHAR(255) NOT NULL, locked_at BIGINT NOT NULL)",
+	}
+	for _, stmt := range stmts {
+		if err := m.conn(ctx).Exec(stmt).Error; err != nil {
+			return errorsx.Wrap(err, "Migrator.createTables")
+		}
+	}
+	return nil
+}
+
+// lock 插入id为1的行获得迁移锁，主键冲突时每隔constant.MigrationLockRetryDelay重试，
+// 超过migration.lock_timeout返回ErrMigrationLocked，没有持有者时返回ErrMigrationLockTimeout；超过constant.MigrationLockStale没有刷新的锁视为持有者已退出。
+// 获得锁后在后台每隔lockRefresh刷新locked_at，锁被其他实例获取时取消返回的context，停止后续的迁移
+func (m *Migrator) lock(ctx context.Context) (context.Context, func(), error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "Migrator"), zap.String("func", "lock"))
+
+	deadline := time.Now().Add(m.lockTimeout)
+	for {
+		now := time.Now()
+		stale := now.Add(-constant.MigrationLockStale).UnixNano() / int64(time.Millisecond)
+		if err := m.conn(ctx).Exec("DELETE FROM "+m.lockTable()+" WHERE id = 1 AND locked_at < ?", stale).Error; err != nil {
+			return nil, nil, errorsx.Wrap(err, "Migrator.lock")
+		}
+		err := m.conn(ctx).Exec("INSERT INTO "+m.lockTable()+" (id, owner, locked_at) VALUES (1, ?, ?)",
+			m.owner, now.UnixNano()/int64(time.Millisecond)).Error
+		if err == nil {
+			ctx, cancel := context.WithCancel(ctx)
+			stop := make(chan struct{})
+			stopped := make(chan struct{})
+			go func() {
+				defer close(stopped)
+				m.refreshLock(ctx, logger, cancel, stop)
+			}()
+			return ctx, func() {
+				close(stop)
+				<-stopped
+				cancel()
+				// 使用新的context，调用方的context取消后仍然释放锁
+				if err := m.conn(context.Background()).Exec("DELETE FROM "+m.lockTable()+" WHERE id = 1 AND owner = ?", m.owner).Error; err != nil {
+					logger.Error("release migration lock", zap.Error(err))
+				}
+			}, nil
+		}
+
+		if now.After(deadline) {
+			var owner string
+			if err := m.conn(ctx).Raw("SELECT owner FROM " + m.lockTable() + " WHERE id = 1").Scan(&owner).Error; err != nil {
+				return nil, nil, errorsx.Wrap(err, "Migrator.lock")
+			}
+			if owner == "" {
+				return nil, nil, errorsx.Wrapf(ErrMigrationLockTimeout, "last error: %v", err)
+			}
+			return nil, nil, errorsx.Wrapf(ErrMigrationLocked, "held by %s", owner)
+		}
+		logger.Info("waiting for migration lock", zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(constant.MigrationLockRetryDelay):
+		}
+	}
+}
+
+// refreshLock 每隔lockRefresh刷新locked_at直到stop关闭，锁已不属于当前实例时调用cancel；
+// 刷新失败时只记录日志，下一次继续刷新
+func (m *Migrator) refreshLock(ctx context.Context, logger *zap.Logger, cancel context.CancelFunc, stop <-chan struct{}) {
+	ticker := time.NewTicker(m.lockRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			tx := m.conn(ctx).Exec("UPDATE "+m.lockTable()+" SET locked_at = ? WHERE id = 1 AND owner = ?",
+				now.UnixNano()/int64(time.Millisecond), m.owner)
+			if tx.Error != nil {
+				logger.Warn("refresh migration lock", zap.Error(tx.Error))
+				continue
+			}
+			if tx.RowsAffected == 0 {
+				logger.Error("migration lock lost", zap.String("owner", m.owner))
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// Statements 按行尾的分号把迁移拆分为单条语句，忽略空行和--注释行，迁移文件中不能在字符串里换行后以分号结尾
+func Statements(sql string) []string {
+	var (
+		stmts []string
+		buf   strings.Builder
+	)
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(buf.String()), ";"))
+			buf.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// CreateMigration 在dir下每种数据库的目录中创建下一个版本的空迁移文件，返回创建的文件
+func CreateMigration(dir, name string) ([]string, error) {
+	if !migrationNameRe.MatchString(name) {
+		return nil, errorsx.Errorf("migration name %q must match %s", name, migrationNameRe)
+	}
+
+	var latest int64
+	for _, driver := range migrationDrivers {
+		entries, err := os.ReadDir(filepath.Join(dir, driver))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errorsx.Wrap(err, "CreateMigration")
+		}
+		for _, entry := range entries {
+			if match := migrationFileRe.FindStringSubmatch(entry.Name()); match != nil {
+				if version, _ := strconv.ParseInt(match[1], 10, 64); version > latest {
+					latest = version
+				}
+			}
+		}
+	}
+
+	var files []string
+	for _, driver := range migrationDrivers {
+		if err := os.MkdirAll(filepath.Join(dir, driver), 0755); err != nil {
+			return files, errorsx.Wrap(err, "CreateMigration")
+		}
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, driver, fmt.Sprintf("%04d_%s.%s.sql", latest+1, name, direction))
+			content := fmt.Sprintf("-- %s %s，表名使用{prefix}前缀，例如{prefix}greeter\n", name, direction)
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				return files, errorsx.Wrap(err, "CreateMigration")
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,187 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+func newSQLiteMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "greeter.db")), &gorm.Config{NamingStrategy: namingStrategy()})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	m, err := newMigrator(db, constant.TablePrefix)
+	require.NoError(t, err)
+	return m, db
+}
+
+func TestLoadMigrations(t *testing.T) {
+	// 每种数据库的迁移版本和名称必须一致
+	mysql, err := loadMigrations(constant.DBDriverMySQL, "t_")
+	require.NoError(t, err)
+	require.NotEmpty(t, mysql)
+	for _, driver := range migrationDrivers {
+		list, err := loadMigrations(driver, "t_")
+		require.NoError(t, err)
+		require.Len(t, list, len(mysql), driver)
+		for i, m := range list {
+			require.Equal(t, mysql[i].Version, m.Version, driver)
+			require.Equal(t, mysql[i].Name, m.Name, driver)
+			require.Contains(t, m.Up, "t_greeter", driver)
+			require.NotContains(t, m.Up, "{prefix}", driver)
+		}
+	}
+
+	_, err = loadMigrations("oracle", "t_")
+	require.Error(t, err)
+}
+
+func TestStatements(t *testing.T) {
+	stmts := Statements(`-- comment
+CREATE TABLE a (
+  id INT
+);
+
+CREATE INDEX b ON a (id);
+DROP TABLE c`)
+	require.Equal(t, []string{"CREATE TABLE a (\n  id INT\n)", "CREATE INDEX b ON a (id)", "DROP TABLE c"}, stmts)
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	total := len(m.migrations)
+
+	// dry-run和status不创建历史表
+	pending, err := m.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, total)
+	require.False(t, db.Migrator().HasTable(m.historyTable()))
+	require.True(t, errors.Is(m.Check(ctx), ErrSchemaBehind))
+
+	done, err := m.Up(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	require.True(t, db.Migrator().HasTable("tbl_greeter"))
+
+	done, err = m.Up(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, done, total-1)
+	require.True(t, db.Migrator().HasTable("tbl_greeter_audit"))
+	require.True(t, db.Migrator().HasColumn("tbl_greeter", "counter_batch"))
+	require.True(t, db.Migrator().HasTable("tbl_greeter_outbox"))
+	require.NoError(t, m.Check(ctx))
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, total)
+	for _, s := range status {
+		require.True(t, s.Applied)
+		require.NotEmpty(t, s.AppliedAt)
+	}
+
+	// 再次执行没有迁移，锁已释放
+	done, err = m.Up(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, done)
+
+	done, err = m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	require.Equal(t, m.migrations[total-1].Version, done[0].Version)
+	require.False(t, db.Migrator().HasColumn("tbl_greeter", "counter_batch"))
+	require.True(t, db.Migrator().HasTable("tbl_greeter_outbox"))
+	require.True(t, errors.Is(m.Check(ctx), ErrSchemaBehind))
+
+	done, err = m.Down(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, done, total-1)
+	require.False(t, db.Migrator().HasTable("tbl_greeter"))
+}
+
+func TestMigrator_Lock(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	m.lockTimeout = time.Millisecond
+	require.NoError(t, m.createTables(ctx))
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	require.NoError(t, db.Exec("INSERT INTO "+m.lockTable()+" (id, owner, locked_at) VALUES (1, 'other:1', ?)", now).Error)
+	_, err := m.Up(ctx, 0)
+	require.True(t, errors.Is(err, ErrMigrationLocked))
+	require.Contains(t, err.Error(), "other:1")
+
+	// 超过MigrationLockStale的锁可以被获取
+	stale := now - int64(constant.MigrationLockStale/time.Millisecond) - 1
+	require.NoError(t, db.Exec("UPDATE "+m.lockTable()+" SET locked_at = ?", stale).Error)
+	done, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, done, len(m.migrations))
+
+	var count int64
+	require.NoError(t, db.Table(m.lockTable()).Count(&count).Error)
+	require.Zero(t, count)
+
+	// 没有持有者但一直无法插入锁记录时返回超时
+	require.NoError(t, db.Exec("CREATE TRIGGER reject_lock BEFORE INSERT ON "+m.lockTable()+" BEGIN SELECT RAISE(ABORT, 'read only'); END").Error)
+	_, err = m.Down(ctx, 0)
+	require.True(t, errors.Is(err, ErrMigrationLockTimeout))
+	require.Contains(t, err.Error(), "read only")
+}
+
+func TestMigrator_LockRefresh(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	m.lockRefresh = time.Millisecond * 10
+	require.NoError(t, m.createTables(ctx))
+
+	lockedAt := func() int64 {
+		var at int64
+		require.NoError(t, db.Raw("SELECT locked_at FROM "+m.lockTable()+" WHERE id = 1").Scan(&at).Error)
+		return at
+	}
+
+	// 持有锁期间定期刷新locked_at，其他实例不会把锁视为已失效
+	lockCtx, unlock, err := m.lock(ctx)
+	require.NoError(t, err)
+	acquired := lockedAt()
+	require.Eventually(t, func() bool { return lockedAt() > acquired }, time.Second, time.Millisecond*10)
+	require.NoError(t, lockCtx.Err())
+
+	// 锁被其他实例获取后取消context，停止后续的迁移
+	require.NoError(t, db.Exec("UPDATE "+m.lockTable()+" SET owner = 'other:1'").Error)
+	require.Eventually(t, func() bool { return lockCtx.Err() != nil }, time.Second, time.Millisecond*10)
+	unlock()
+
+	var owner string
+	require.NoError(t, db.Raw("SELECT owner FROM "+m.lockTable()+" WHERE id = 1").Scan(&owner).Error)
+	require.Equal(t, "other:1", owner, "only release own lock")
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, constant.DBDriverMySQL), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, constant.DBDriverMySQL, "0007_create_greeter.up.sql"), nil, 0644))
+
+	files, err := CreateMigration(dir, "add_greeter_email")
+	require.NoError(t, err)
+	require.Len(t, files, len(migrationDrivers)*2)
+	require.FileExists(t, filepath.Join(dir, constant.DBDriverPostgres, "0008_add_greeter_email.up.sql"))
+	require.FileExists(t, filepath.Join(dir, constant.DBDriverSQLite, "0008_add_greeter_email.down.sql"))
+
+	_, err = CreateMigration(dir, "Add-Email")
+	require.Error(t, err)
+}
@@ -0,0 +1,19 @@
+-- 已手工建表的数据库不会重复创建，执行后记录为已迁移
+CREATE TABLE IF NOT EXISTS `{prefix}greeter` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(255) NOT NULL DEFAULT '',
+  `view_num` INT NOT NULL DEFAULT 0,
+  `status` INT NOT NULL DEFAULT 0,
+  `create_time` BIGINT NOT NULL DEFAULT 0,
+  `create_datetime` VARCHAR(19) NOT NULL DEFAULT '',
+  `update_datetime` VARCHAR(19) NOT NULL DEFAULT '',
+  `version` INT NOT NULL DEFAULT 1,
+  `status_operator` VARCHAR(64) NOT NULL DEFAULT '',
+  `status_datetime` VARCHAR(19) NOT NULL DEFAULT '',
+  `deleted_at` DATETIME(3) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_{prefix}greeter_status` (`status`, `id`),
+  KEY `idx_{prefix}greeter_view_num` (`view_num`, `id`),
+  KEY `idx_{prefix}greeter_name` (`name`),
+  KEY `idx_{prefix}greeter_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
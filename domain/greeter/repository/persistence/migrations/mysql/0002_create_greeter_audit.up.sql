@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `{prefix}greeter_audit` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `greeter_id` INT NOT NULL DEFAULT 0,
+  `action` INT NOT NULL DEFAULT 0,
+  `actor` VARCHAR(64) NOT NULL DEFAULT '',
+  `request_id` VARCHAR(64) NOT NULL DEFAULT '',
+  `before` TEXT NOT NULL,
+  `after` TEXT NOT NULL,
+  `create_time` BIGINT NOT NULL DEFAULT 0,
+  `create_datetime` VARCHAR(19) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_{prefix}greeter_audit_greeter_id` (`greeter_id`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `{prefix}greeter_outbox` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `topic` VARCHAR(255) NOT NULL DEFAULT '',
+  `message_key` VARCHAR(255) NOT NULL DEFAULT '',
+  `body` MEDIUMBLOB NOT NULL,
+  `attempts` INT NOT NULL DEFAULT 0,
+  `next_attempt_time` BIGINT NOT NULL DEFAULT 0,
+  `last_error` VARCHAR(1024) NOT NULL DEFAULT '',
+  `sent_time` BIGINT NOT NULL DEFAULT 0,
+  `dead_time` BIGINT NOT NULL DEFAULT 0,
+  `create_time` BIGINT NOT NULL DEFAULT 0,
+  `create_datetime` VARCHAR(19) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `idx_{prefix}greeter_outbox_sent_time` (`sent_time`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
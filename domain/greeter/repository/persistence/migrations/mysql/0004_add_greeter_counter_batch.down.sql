@@ -0,0 +1 @@
+ALTER TABLE `{prefix}greeter` DROP COLUMN `counter_batch`;
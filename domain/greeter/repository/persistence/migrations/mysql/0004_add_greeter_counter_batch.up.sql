@@ -0,0 +1 @@
+ALTER TABLE `{prefix}greeter` ADD COLUMN `counter_batch` VARCHAR(32) NOT NULL DEFAULT '' AFTER `status_datetime`;
@@ -0,0 +1,18 @@
+-- 已手工建表的数据库不会重复创建，执行后记录为已迁移
+CREATE TABLE IF NOT EXISTS "{prefix}greeter" (
+  "id" SERIAL PRIMARY KEY,
+  "name" VARCHAR(255) NOT NULL DEFAULT '',
+  "view_num" INTEGER NOT NULL DEFAULT 0,
+  "status" INTEGER NOT NULL DEFAULT 0,
+  "create_time" BIGINT NOT NULL DEFAULT 0,
+  "create_datetime" VARCHAR(19) NOT NULL DEFAULT '',
+  "update_datetime" VARCHAR(19) NOT NULL DEFAULT '',
+  "version" INTEGER NOT NULL DEFAULT 1,
+  "status_operator" VARCHAR(64) NOT NULL DEFAULT '',
+  "status_datetime" VARCHAR(19) NOT NULL DEFAULT '',
+  "deleted_at" TIMESTAMPTZ NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_status" ON "{prefix}greeter" ("status", "id");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_view_num" ON "{prefix}greeter" ("view_num", "id");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_name" ON "{prefix}greeter" ("name");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_deleted_at" ON "{prefix}greeter" ("deleted_at");
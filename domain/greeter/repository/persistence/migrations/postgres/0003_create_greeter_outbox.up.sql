@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "{prefix}greeter_outbox" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "topic" VARCHAR(255) NOT NULL DEFAULT '',
+  "message_key" VARCHAR(255) NOT NULL DEFAULT '',
+  "body" BYTEA NOT NULL DEFAULT '',
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "next_attempt_time" BIGINT NOT NULL DEFAULT 0,
+  "last_error" VARCHAR(1024) NOT NULL DEFAULT '',
+  "sent_time" BIGINT NOT NULL DEFAULT 0,
+  "dead_time" BIGINT NOT NULL DEFAULT 0,
+  "create_time" BIGINT NOT NULL DEFAULT 0,
+  "create_datetime" VARCHAR(19) NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_outbox_sent_time" ON "{prefix}greeter_outbox" ("sent_time", "id");
@@ -0,0 +1 @@
+ALTER TABLE "{prefix}greeter" DROP COLUMN IF EXISTS "counter_batch";
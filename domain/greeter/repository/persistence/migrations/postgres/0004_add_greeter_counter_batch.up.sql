@@ -0,0 +1 @@
+ALTER TABLE "{prefix}greeter" ADD COLUMN IF NOT EXISTS "counter_batch" VARCHAR(32) NOT NULL DEFAULT '';
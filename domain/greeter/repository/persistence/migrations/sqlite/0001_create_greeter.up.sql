@@ -0,0 +1,18 @@
+-- 已手工建表的数据库不会重复创建，执行后记录为已迁移
+CREATE TABLE IF NOT EXISTS "{prefix}greeter" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "name" TEXT NOT NULL DEFAULT '',
+  "view_num" INTEGER NOT NULL DEFAULT 0,
+  "status" INTEGER NOT NULL DEFAULT 0,
+  "create_time" INTEGER NOT NULL DEFAULT 0,
+  "create_datetime" TEXT NOT NULL DEFAULT '',
+  "update_datetime" TEXT NOT NULL DEFAULT '',
+  "version" INTEGER NOT NULL DEFAULT 1,
+  "status_operator" TEXT NOT NULL DEFAULT '',
+  "status_datetime" TEXT NOT NULL DEFAULT '',
+  "deleted_at" DATETIME NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_status" ON "{prefix}greeter" ("status", "id");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_view_num" ON "{prefix}greeter" ("view_num", "id");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_name" ON "{prefix}greeter" ("name");
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_deleted_at" ON "{prefix}greeter" ("deleted_at");
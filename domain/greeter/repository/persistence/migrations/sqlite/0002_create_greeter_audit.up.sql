@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "{prefix}greeter_audit" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "greeter_id" INTEGER NOT NULL DEFAULT 0,
+  "action" INTEGER NOT NULL DEFAULT 0,
+  "actor" TEXT NOT NULL DEFAULT '',
+  "request_id" TEXT NOT NULL DEFAULT '',
+  "before" TEXT NOT NULL DEFAULT '',
+  "after" TEXT NOT NULL DEFAULT '',
+  "create_time" INTEGER NOT NULL DEFAULT 0,
+  "create_datetime" TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_audit_greeter_id" ON "{prefix}greeter_audit" ("greeter_id", "id");
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "{prefix}greeter_outbox" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "topic" TEXT NOT NULL DEFAULT '',
+  "message_key" TEXT NOT NULL DEFAULT '',
+  "body" BLOB NOT NULL DEFAULT x'',
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "next_attempt_time" INTEGER NOT NULL DEFAULT 0,
+  "last_error" TEXT NOT NULL DEFAULT '',
+  "sent_time" INTEGER NOT NULL DEFAULT 0,
+  "dead_time" INTEGER NOT NULL DEFAULT 0,
+  "create_time" INTEGER NOT NULL DEFAULT 0,
+  "create_datetime" TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS "idx_{prefix}greeter_outbox_sent_time" ON "{prefix}greeter_outbox" ("sent_time", "id");
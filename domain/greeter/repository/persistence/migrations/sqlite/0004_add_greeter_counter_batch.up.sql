@@ -0,0 +1 @@
+ALTER TABLE "{prefix}greeter" ADD COLUMN "counter_batch" TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,164 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+	"github.com/imind-lab/micro/tracing"
+)
+
+// greeterOutboxLockKey 中继发布时持有的锁，保证同一时刻只有一个实例在发布
+const greeterOutboxLockKey = "greeter_outbox_lock"
+
+// greeterOutboxErrorLen last_error保留的最大长度
+const greeterOutboxErrorLen = 1024
+
+type greeterOutboxRepository struct {
+	dao.Dao
+}
+
+// NewGreeterOutboxRepository 创建基于数据库的事件发件箱实例，中继锁保存在Redis中
+func NewGreeterOutboxRepository() repository.GreeterOutboxRepository {
+	rep := newDao()
+	repo := greeterOutboxRepository{
+		Dao: rep,
+	}
+	return repo
+}
+
+// DB 在GreeterRepository.Transaction中使用当前事务
+func (repo greeterOutboxRepository) DB(ctx context.Context) *gorm.DB {
+	return dbWithTx(ctx, repo.Dao)
+}
+
+func (repo greeterOutboxRepository) CreateGreeterOutbox(ctx context.Context, m model.GreeterOutbox) error {
+	span, ctx := tracing.StartSpan(ctx, "greeterOutboxRepository.CreateGreeterOutbox")
+	defer span.Finish()
+
+	if err := repo.DB(ctx).Create(&m).Error; err != nil {
+		return errorsx.Wrap(err, "greeterOutboxRepository.CreateGreeterOutbox")
+	}
+	return nil
+}
+
+func (repo greeterOutboxRepository) RelayGreeterOutbox(ctx context.Context, batchSize int, relay func(context.Context, []model.GreeterOutbox) error) (int, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterOutboxRepository"), zap.String("func", "RelayGreeterOutbox"))
+
+	cli := repo.Redis()
+	token, err := lockToken()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterOutboxRepository.RelayGreeterOutbox")
+	}
+	lockKey := utilx.CacheKey(greeterOutboxLockKey)
+	locked, err := cli.SetNX(ctx, lockKey, token, constant.GreeterOutboxLockTTL).Result()
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterOutboxRepository.RelayGreeterOutbox.SetNX")
+	}
+	if !locked {
+		logger.Debug("greeter outbox is being relayed by another instance")
+		return 0, nil
+	}
+	defer func() {
+		if err := cli.Eval(ctx, unlockScript, []string{lockKey}, token).Err(); err != nil {
+			logger.Warn("release greeter outbox lock failed", zap.Error(err))
+		}
+	}()
+
+	// 同一key有等待重试的事件时跳过该key后面所有的事件，保证按顺序发布
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	waiting := repo.DB(ctx).Model(&model.GreeterOutbox{}).Select("message_key").
+		Where("sent_time = 0 AND dead_time = 0 AND next_attempt_time > ?", now)
+	var list []model.GreeterOutbox
+	err = repo.DB(ctx).Where("sent_time = 0 AND dead_time = 0 AND message_key NOT IN (?)", waiting).
+		Order("id").Limit(batchSize).Find(&list).Error
+	if err != nil {
+		return 0, errorsx.Wrap(err, "greeterOutboxRepository.RelayGreeterOutbox")
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+	if err := relay(ctx, list); err != nil {
+		return len(list), errorsx.WithMessage(err, "greeterOutboxRepository.RelayGreeterOutbox")
+	}
+	return len(list), nil
+}
+
+func (repo greeterOutboxRepository) MarkGreeterOutboxSent(ctx context.Context, id int64) error {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	err := repo.DB(ctx).Model(&model.GreeterOutbox{}).Where("id = ? AND sent_time = 0 AND dead_time = 0", id).Update("sent_time", now).Error
+	if err != nil {
+		return errorsx.Wrap(err, "greeterOutboxRepository.MarkGreeterOutboxSent")
+	}
+	return nil
+}
+
+func (repo greeterOutboxRepository) MarkGreeterOutboxFailed(ctx context.Context, id int64, next time.Time, lastError string) error {
+	if len(lastError) > greeterOutboxErrorLen {
+		lastError = lastError[:greeterOutboxErrorLen]
+	}
+	err := repo.DB(ctx).Model(&model.GreeterOutbox{}).Where("id = ? AND sent_time = 0 AND dead_time = 0", id).Updates(map[string]interface{}{
+		"attempts":          increment("attempts", 1),
+		"next_attempt_time": next.UnixNano() / int64(time.Millisecond),
+		"last_error":        lastError,
+	}).Error
+	if err != nil {
+		return errorsx.Wrap(err, "greeterOutboxRepository.MarkGreeterOutboxFailed")
+	}
+	return nil
+}
+
+func (repo greeterOutboxRepository) MarkGreeterOutboxDead(ctx context.Context, id int64, lastError string) error {
+	if len(lastError) > greeterOutboxErrorLen {
+		lastError = lastError[:greeterOutboxErrorLen]
+	}
+	err := repo.DB(ctx).Model(&model.GreeterOutbox{}).Where("id = ? AND sent_time = 0 AND dead_time = 0", id).Updates(map[string]interface{}{
+		"attempts":   increment("attempts", 1),
+		"dead_time":  time.Now().UnixNano() / int64(time.Millisecond),
+		"last_error": lastError,
+	}).Error
+	if err != nil {
+		return errorsx.Wrap(err, "greeterOutboxRepository.MarkGreeterOutboxDead")
+	}
+	return nil
+}
+
+func (repo greeterOutboxRepository) CountPendingGreeterOutbox(ctx context.Context) (int64, error) {
+	var count int64
+	if err := repo.DB(ctx).Model(&model.GreeterOutbox{}).Where("sent_time = 0 AND dead_time = 0").Count(&count).Error; err != nil {
+		return 0, errorsx.Wrap(err, "greeterOutboxRepository.CountPendingGreeterOutbox")
+	}
+	return count, nil
+}
+
+func (repo greeterOutboxRepository) CountDeadGreeterOutbox(ctx context.Context) (int64, error) {
+	var count int64
+	if err := repo.DB(ctx).Model(&model.GreeterOutbox{}).Where("dead_time > 0").Count(&count).Error; err != nil {
+		return 0, errorsx.Wrap(err, "greeterOutboxRepository.CountDeadGreeterOutbox")
+	}
+	return count, nil
+}
+
+func (repo greeterOutboxRepository) PurgeSentGreeterOutbox(ctx context.Context, before time.Time) (int64, error) {
+	tx := repo.DB(ctx).Where("sent_time > 0 AND sent_time < ?", before.UnixNano()/int64(time.Millisecond)).Delete(&model.GreeterOutbox{})
+	if tx.Error != nil {
+		return 0, errorsx.Wrap(tx.Error, "greeterOutboxRepository.PurgeSentGreeterOutbox")
+	}
+	return tx.RowsAffected, nil
+}
@@ -0,0 +1,137 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+)
+
+// readYourWritesTag 同一请求中写入成功后设置的标记，之后的读取使用主库
+const readYourWritesTag = "greeter.read_your_writes"
+
+// replicaDsnFormat 与micro/dao打开主库时使用的DSN格式一致
+const replicaDsnFormat = "%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&multiStatements=true&interpolateParams=true&parseTime=True&loc=Local"
+
+// replicaConfig db.<name>.read中的一个从库
+type replicaConfig struct {
+	Host string
+	Port int
+	User string
+	Pass string
+	Name string
+}
+
+// roundRobinPolicy 按顺序轮流使用从库
+type roundRobinPolicy struct {
+	next uint64
+}
+
+func (p *roundRobinPolicy) Resolve(connPools []gorm.ConnPool) gorm.ConnPool {
+	n := atomic.AddUint64(&p.next, 1)
+	return connPools[(n-1)%uint64(len(connPools))]
+}
+
+// registerGreeterReplicas 为tbl_greeter注册db.<name>.read中配置的MySQL从库，未配置时沿用micro/dao按replica注册的读写分离。
+// dbresolver注册时会重建解析表，与查询并发时不安全，只能在创建Dao时、开始处理请求前调用
+func registerGreeterReplicas(d dao.Dao) error {
+	var replicas []replicaConfig
+	if err := viper.UnmarshalKey("db."+constant.DBName+".read", &replicas); err != nil {
+		return err
+	}
+	if len(replicas) == 0 {
+		return nil
+	}
+	dialectors := make([]gorm.Dialector, 0, len(replicas))
+	for _, r := range replicas {
+		dialectors = append(dialectors, mysql.Open(fmt.Sprintf(replicaDsnFormat, r.User, r.Pass, r.Host, r.Port, r.Name)))
+	}
+	config := dbresolver.Config{Replicas: dialectors, Policy: &roundRobinPolicy{}}
+
+	db := d.DB(context.Background())
+	if plugin, ok := db.Config.Plugins[(&dbresolver.DBResolver{}).Name()].(*dbresolver.DBResolver); ok {
+		plugin.Register(config, model.Greeter{})
+		return nil
+	}
+	return db.Use(dbresolver.Register(config, model.Greeter{}).
+		SetMaxOpenConns(viper.GetInt("db.max.open")).
+		SetMaxIdleConns(viper.GetInt("db.max.idle")).
+		SetConnMaxLifetime(time.Duration(viper.GetInt("db.max.life")) * time.Minute))
+}
+
+// readDB 读取使用从库，同一请求或会话在写入后的一段时间内使用主库，避免读取到复制延迟前的数据
+func (repo greeterRepository) readDB(ctx context.Context) *gorm.DB {
+	db := repo.DB(ctx)
+	if repo.stickToMaster(ctx) {
+		return db.Clauses(dbresolver.Write)
+	}
+	return db.Clauses(dbresolver.Read)
+}
+
+// masterDB 总是使用主库，用于写入后立即确认结果的读取
+func (repo greeterRepository) masterDB(ctx context.Context) *gorm.DB {
+	return repo.DB(ctx).Clauses(dbresolver.Write)
+}
+
+// markWritten 记录当前请求和会话已写入，会话标记在db.<name>.sticky_window后过期
+func (repo greeterRepository) markWritten(ctx context.Context) {
+	grpc_ctxtags.Extract(ctx).Set(readYourWritesTag, true)
+
+	session := utilx.Session(ctx)
+	if session == "" {
+		return
+	}
+	key := utilx.CacheKey("greeter_rw_", session)
+	if err := repo.Redis().Set(ctx, key, 1, readYourWritesWindow()).Err(); err != nil {
+		logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "markWritten"))
+		logger.Warn("redis.Set", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// stickToMaster 当前请求已写入，或会话在窗口期内写入过时返回true，无法确认时使用主库
+func (repo greeterRepository) stickToMaster(ctx context.Context) bool {
+	if grpc_ctxtags.Extract(ctx).Has(readYourWritesTag) {
+		return true
+	}
+
+	session := utilx.Session(ctx)
+	if session == "" {
+		return false
+	}
+	key := utilx.CacheKey("greeter_rw_", session)
+	n, err := repo.Redis().Exists(ctx, key).Result()
+	if err != nil {
+		logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "stickToMaster"))
+		logger.Warn("redis.Exists", zap.String("key", key), zap.Error(err))
+		return true
+	}
+	return n > 0
+}
+
+func readYourWritesWindow() time.Duration {
+	window := viper.GetDuration("db." + constant.DBName + ".sticky_window")
+	if window <= 0 {
+		return constant.ReadYourWritesWindow
+	}
+	return window
+}
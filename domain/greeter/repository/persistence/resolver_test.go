@@ -0,0 +1,120 @@
+package persistence
+
+import (
+	"context"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"gorm.io/gorm"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	redisx "github.com/imind-lab/micro/redis"
+)
+
+func usesMaster(db *gorm.DB) bool {
+	_, ok := db.Statement.Clauses["gorm:db_resolver:write"]
+	return ok
+}
+
+func (s *Suite) TestRoundRobinPolicy() {
+	pools := []gorm.ConnPool{&gorm.PreparedStmtDB{}, &gorm.PreparedStmtDB{}, &gorm.PreparedStmtDB{}}
+	policy := &roundRobinPolicy{}
+	for i := 0; i < 6; i++ {
+		require.Same(s.T(), pools[i%3], policy.Resolve(pools))
+	}
+}
+
+func (s *Suite) TestGreeterRepository_ReadYourWritesRequest() {
+	ctx := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags())
+	require.False(s.T(), usesMaster(s.repo.readDB(ctx)))
+
+	s.repo.markWritten(ctx)
+	require.True(s.T(), usesMaster(s.repo.readDB(ctx)))
+
+	// 其他请求不受影响
+	other := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags())
+	require.False(s.T(), usesMaster(s.repo.readDB(other)))
+}
+
+func (s *Suite) TestGreeterRepository_ReadYourWritesSession() {
+	md := metadata.Pairs(constant.SessionMetadataKey, "s-1")
+	newCtx := func() context.Context {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		return grpc_ctxtags.SetInContext(ctx, grpc_ctxtags.NewTags())
+	}
+	key := utilx.CacheKey("greeter_rw_", "s-1")
+
+	s.redisMock.ExpectSet(key, 1, constant.ReadYourWritesWindow).SetVal("OK")
+	s.repo.markWritten(newCtx())
+
+	// 同一会话的后续请求在窗口期内读取主库，过期后读取从库
+	s.redisMock.ExpectExists(key).SetVal(1)
+	require.True(s.T(), usesMaster(s.repo.readDB(newCtx())))
+	s.redisMock.ExpectExists(key).SetVal(0)
+	require.False(s.T(), usesMaster(s.repo.readDB(newCtx())))
+	s.redisMock.ExpectExists(key).SetErr(context.DeadlineExceeded)
+	require.True(s.T(), usesMaster(s.repo.readDB(newCtx())))
+}
+
+// recordRoutes 记录之后每次查询是否使用主库
+func (s *Suite) recordRoutes() (*[]bool, func()) {
+	routes := &[]bool{}
+	query := s.mysqlDB.Callback().Query()
+	require.NoError(s.T(), query.Before("gorm:query").Register("test:route", func(db *gorm.DB) {
+		*routes = append(*routes, usesMaster(db))
+	}))
+	return routes, func() {
+		require.NoError(s.T(), query.Remove("test:route"))
+	}
+}
+
+func (s *Suite) TestGreeterRepository_CacheFillUsesMaster() {
+	routes, reset := s.recordRoutes()
+	defer reset()
+
+	ctx := grpc_ctxtags.SetInContext(context.Background(), grpc_ctxtags.NewTags())
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "view_num", "status"}).AddRow(100, "koofox", 1, 1)
+	}
+
+	// 直接读取使用从库
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\?").WithArgs(100).WillReturnRows(rows())
+	_, err := s.repo.FindGreeterById(ctx, 100)
+	require.NoError(s.T(), err)
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE status=\\?").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	_, err = s.repo.FindGreetersCount(ctx, 1)
+	require.NoError(s.T(), err)
+
+	// 写入缓存的读取使用主库，避免把从库中复制延迟前的数据写入缓存
+	key := utilx.CacheKey("greeter_", "100")
+	s.redisMock.ExpectPTTL(key).SetVal(-2)
+	s.redisMock.ExpectHGetAll(key).RedisNil()
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id = \\?").WithArgs(100).WillReturnRows(rows())
+	s.redisMock.ExpectHMSet(key, redisx.FlatStruct(model.Greeter{Id: 100, Name: "koofox", ViewNum: 1, Status: 1})).SetVal(true)
+	s.redisMock.CustomMatch(anyExpire).ExpectExpire(key, constant.CacheMinute5).SetVal(true)
+	_, err = s.repo.GetGreeterById(ctx, 100)
+	require.NoError(s.T(), err)
+
+	cntKey := utilx.CacheKey("greeter_cnt_", "1")
+	s.redisMock.ExpectPTTL(cntKey).SetVal(-2)
+	s.redisMock.ExpectGet(cntKey).RedisNil()
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE status=\\?").
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+	s.redisMock.CustomMatch(anyExpire).ExpectSet(cntKey, int64(7), constant.CacheMinute5).SetVal("OK")
+	_, err = s.repo.GetGreetersCount(ctx, 1)
+	require.NoError(s.T(), err)
+
+	s.redisMock.ExpectHGetAll(key).SetVal(map[string]string{})
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id IN \\(\\?\\)").WithArgs(100).WillReturnRows(rows())
+	s.redisMock.ExpectHMSet(key, redisx.FlatStruct(model.Greeter{Id: 100, Name: "koofox", ViewNum: 1, Status: 1})).SetVal(true)
+	s.redisMock.CustomMatch(anyExpire).ExpectExpire(key, constant.CacheMinute5).SetVal(true)
+	_, _, err = s.repo.GetGreetersByIds(ctx, []int32{100})
+	require.NoError(s.T(), err)
+
+	require.Equal(s.T(), []bool{false, false, true, true, true}, *routes)
+}
@@ -0,0 +1,64 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+
+	errorsx "github.com/pkg/errors"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/micro/dao"
+	"github.com/imind-lab/micro/tracing"
+)
+
+type greeterSearchIndex struct {
+	dao.Dao
+}
+
+// NewGreeterSearchIndex 创建基于LIKE的搜索索引实例，直接查询tbl_greeter，不需要额外维护索引，
+// 已软删除的Greeter不会出现在结果中
+func NewGreeterSearchIndex() repository.GreeterSearchIndex {
+	return greeterSearchIndex{
+		Dao: newDao(),
+	}
+}
+
+func (idx greeterSearchIndex) IndexGreeter(ctx context.Context, m model.Greeter) error {
+	return nil
+}
+
+func (idx greeterSearchIndex) RemoveGreeter(ctx context.Context, id int32) error {
+	return nil
+}
+
+func (idx greeterSearchIndex) SearchGreeters(ctx context.Context, query string, offset, limit int32) ([]repository.GreeterSearchHit, int, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterSearchIndex.SearchGreeters")
+	defer span.Finish()
+
+	escaped := likeEscaper.Replace(query)
+	contains := "%" + escaped + "%"
+
+	var count int64
+	db := idx.DB(ctx)
+	if err := db.Model(&model.Greeter{}).Where(nameLike(db), contains).Count(&count).Error; err != nil {
+		return nil, 0, errorsx.Wrap(err, "greeterSearchIndex.SearchGreeters.Count")
+	}
+
+	hits := []repository.GreeterSearchHit{}
+	tx := db.Model(&model.Greeter{}).
+		Select("id, CASE WHEN name = ? THEN ? WHEN "+nameLike(db)+" THEN ? ELSE ? END AS score",
+			query, repository.GreeterSearchScoreExact, escaped+"%", repository.GreeterSearchScorePrefix, repository.GreeterSearchScoreContains).
+		Where(nameLike(db), contains).
+		Order("score DESC, id DESC").Offset(int(offset)).Limit(int(limit))
+	if err := tx.Scan(&hits).Error; err != nil {
+		return nil, 0, errorsx.Wrap(err, "greeterSearchIndex.SearchGreeters")
+	}
+	return hits, int(count), nil
+}
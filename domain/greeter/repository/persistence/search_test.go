@@ -0,0 +1,35 @@
+package persistence
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+	"github.com/stretchr/testify/require"
+)
+
+func (s *Suite) searchIndex() greeterSearchIndex {
+	idx := greeterSearchIndex{
+		Dao: dao.NewDao(constant.DBName),
+	}
+	idx.SetDBMock(s.mysqlDB)
+	return idx
+}
+
+func (s *Suite) TestGreeterSearchIndex_SearchGreeters() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE name LIKE \\? ESCAPE '!' AND `tbl_greeter`.`deleted_at` IS NULL$").
+		WithArgs(`%im!_nd%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	s.mysqlMock.ExpectQuery("SELECT id, CASE WHEN name = \\? THEN \\? WHEN name LIKE \\? ESCAPE '!' THEN \\? ELSE \\? END AS score FROM `tbl_greeter` "+
+		"WHERE name LIKE \\? ESCAPE '!' AND `tbl_greeter`.`deleted_at` IS NULL ORDER BY score DESC, id DESC LIMIT 2 OFFSET 1$").
+		WithArgs("im_nd", 3.0, `im!_nd%`, 2.0, 1.0, `%im!_nd%`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "score"}).AddRow(300, 2).AddRow(100, 1))
+
+	hits, total, err := s.searchIndex().SearchGreeters(ctx, "im_nd", 1, 2)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, total)
+	require.Equal(s.T(), []repository.GreeterSearchHit{{Id: 300, Score: 2}, {Id: 100, Score: 1}}, hits)
+}
@@ -0,0 +1,68 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+
+	errorsx "github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/imind-lab/micro/dao"
+)
+
+// greeterTxKey context中保存Transaction开启的事务
+type greeterTxKey struct{}
+
+// greeterTx 当前事务，以及提交后才执行的缓存更新
+type greeterTx struct {
+	db    *gorm.DB
+	hooks []func(ctx context.Context)
+}
+
+// dbWithTx ctx中有Transaction开启的事务时使用该事务，否则使用d的连接
+func dbWithTx(ctx context.Context, d dao.Dao) *gorm.DB {
+	if tx, ok := ctx.Value(greeterTxKey{}).(*greeterTx); ok {
+		return tx.db.WithContext(ctx)
+	}
+	return d.DB(ctx)
+}
+
+// afterCommit ctx中有事务时在提交后以Transaction的ctx执行fn，回滚时不执行；没有事务时立即执行
+func afterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if tx, ok := ctx.Value(greeterTxKey{}).(*greeterTx); ok {
+		tx.hooks = append(tx.hooks, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// DB 在Transaction中使用当前事务
+func (repo greeterRepository) DB(ctx context.Context) *gorm.DB {
+	return dbWithTx(ctx, repo.Dao)
+}
+
+func (repo greeterRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(greeterTxKey{}).(*greeterTx); ok {
+		return fn(ctx)
+	}
+
+	gtx := &greeterTx{}
+	err := repo.Dao.DB(ctx).Clauses(dbresolver.Write).Transaction(func(tx *gorm.DB) error {
+		gtx.db = tx
+		return fn(context.WithValue(ctx, greeterTxKey{}, gtx))
+	})
+	if err != nil {
+		return errorsx.WithMessage(err, "greeterRepository.Transaction")
+	}
+	for _, hook := range gtx.hooks {
+		hook(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/dao"
+)
+
+func TestGreeterRepository_Transaction(t *testing.T) {
+	ctx := context.Background()
+	m, db := newSQLiteMigrator(t)
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+	d := greeterDao{Dao: dao.NewDao(constant.DBName), db: db}
+	d.SetRedisMock(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	repo := greeterRepository{Dao: d}
+	outbox := greeterOutboxRepository{Dao: d}
+	audit := greeterAuditRepository{Dao: d}
+
+	count := func(table string) int64 {
+		var n int64
+		require.NoError(t, db.Table(table).Count(&n).Error)
+		return n
+	}
+
+	// 回滚时Greeter、变更记录和事件都不写入，提交后才执行的函数不执行
+	var committed bool
+	err = repo.Transaction(ctx, func(ctx context.Context) error {
+		if _, err := repo.CreateGreeter(ctx, model.Greeter{Name: "koofox"}); err != nil {
+			return err
+		}
+		if _, err := audit.CreateGreeterAudit(ctx, model.GreeterAudit{GreeterId: 1, Action: 1}); err != nil {
+			return err
+		}
+		if err := outbox.CreateGreeterOutbox(ctx, model.GreeterOutbox{Topic: "greeter_create", MessageKey: "1"}); err != nil {
+			return err
+		}
+		afterCommit(ctx, func(ctx context.Context) { committed = true })
+		return errors.New("broker is not connected")
+	})
+	require.EqualError(t, err, "greeterRepository.Transaction: broker is not connected")
+	require.False(t, committed)
+	require.Zero(t, count("tbl_greeter"))
+	require.Zero(t, count("tbl_greeter_audit"))
+	require.Zero(t, count("tbl_greeter_outbox"))
+
+	// 嵌套调用使用外层事务，提交后执行
+	err = repo.Transaction(ctx, func(ctx context.Context) error {
+		created, err := repo.CreateGreeter(ctx, model.Greeter{Name: "koofox"})
+		if err != nil {
+			return err
+		}
+		return repo.Transaction(ctx, func(ctx context.Context) error {
+			afterCommit(ctx, func(ctx context.Context) { committed = true })
+			return outbox.CreateGreeterOutbox(ctx, model.GreeterOutbox{Topic: "greeter_create", MessageKey: "1", Body: []byte(created.Name)})
+		})
+	})
+	require.NoError(t, err)
+	require.True(t, committed)
+	require.EqualValues(t, 1, count("tbl_greeter"))
+	require.EqualValues(t, 1, count("tbl_greeter_outbox"))
+}
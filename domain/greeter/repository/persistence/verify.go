@@ -0,0 +1,230 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/tracing"
+)
+
+// NewGreeterCacheVerifier 创建比较MySQL与Redis中Greeter的缓存一致性检查实例
+func NewGreeterCacheVerifier() repository.GreeterCacheVerifier {
+	return greeterRepository{
+		Dao: newDao(),
+	}
+}
+
+func (repo greeterRepository) VerifyGreeterCache(ctx context.Context, batchSize int, repair bool) (repository.GreeterCacheReport, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.VerifyGreeterCache")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "VerifyGreeterCache"))
+
+	if batchSize <= 0 {
+		batchSize = constant.GreeterCacheVerifyBatchSize
+	}
+	report := repository.GreeterCacheReport{Counts: make(map[string]int), Drifts: []repository.GreeterCacheDrift{}}
+	var lastId int32
+	for {
+		var rows []model.Greeter
+		err := repo.masterDB(ctx).Unscoped().Where("id > ?", lastId).Order("id").Limit(batchSize).Find(&rows).Error
+		if err != nil {
+			return report, errorsx.Wrap(err, "greeterRepository.VerifyGreeterCache")
+		}
+		// 最后一批检查有序集合中lastId之后的全部成员，覆盖数据库中已不存在的id
+		last := len(rows) < batchSize
+		if err := repo.verifyGreeterBatch(ctx, rows, lastId, last, repair, &report); err != nil {
+			return report, errorsx.WithMessage(err, "greeterRepository.VerifyGreeterCache")
+		}
+		report.Checked += len(rows)
+		logger.Debug("verify greeter cache", zap.Int32("lastId", lastId), zap.Int("checked", report.Checked))
+		if last {
+			break
+		}
+		lastId = rows[len(rows)-1].Id
+	}
+	return report, nil
+}
+
+// verifyGreeterBatch 检查id在(lastId, rows中最大的id]内的缓存，last为true时检查lastId之后的全部有序集合成员
+func (repo greeterRepository) verifyGreeterBatch(ctx context.Context, rows []model.Greeter, lastId int32, last, repair bool, report *repository.GreeterCacheReport) error {
+	cli := repo.Redis()
+
+	pipe := cli.Pipeline()
+	hashes := make([]*redis.StringStringMapCmd, len(rows))
+	for i, row := range rows {
+		hashes[i] = pipe.HGetAll(ctx, utilx.CacheKey("greeter_", strconv.Itoa(int(row.Id))))
+	}
+	types := make([]*redis.StatusCmd, constant.GreeterStatusMax+1)
+	for s := range types {
+		types[s] = pipe.Type(ctx, utilx.CacheKey("greeter_ids_", strconv.Itoa(s)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errorsx.Wrap(err, "redis.Pipeline")
+	}
+
+	// 只读取已缓存为有序集合的成员，空列表缓存为空字符串
+	max := "+inf"
+	if !last {
+		max = strconv.Itoa(int(rows[len(rows)-1].Id))
+	}
+	members := make([]map[int32]struct{}, len(types))
+	pipe = cli.Pipeline()
+	ranges := make([]*redis.StringSliceCmd, len(types))
+	queued := 0
+	for s, t := range types {
+		if t.Val() == "zset" {
+			ranges[s] = pipe.ZRangeByScore(ctx, utilx.CacheKey("greeter_ids_", strconv.Itoa(s)), &redis.ZRangeBy{
+				Min: "(" + strconv.Itoa(int(lastId)),
+				Max: max,
+			})
+			queued++
+		}
+	}
+	if queued > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return errorsx.Wrap(err, "redis.Pipeline")
+		}
+	}
+	for s, r := range ranges {
+		if r == nil {
+			continue
+		}
+		members[s] = make(map[int32]struct{}, len(r.Val()))
+		for _, member := range r.Val() {
+			id, err := strconv.Atoi(member)
+			if err != nil {
+				return errorsx.Wrap(err, "greeter_ids_ member")
+			}
+			members[s][int32(id)] = struct{}{}
+		}
+	}
+
+	fix := cli.Pipeline()
+	queued = 0
+	add := func(drift repository.GreeterCacheDrift, fn func()) {
+		if repair {
+			fn()
+			queued++
+			drift.Repaired = true
+		}
+		report.Add(drift)
+	}
+	for i, row := range rows {
+		row := row
+		live := !row.DeletedAt.Valid
+		key := utilx.CacheKey("greeter_", strconv.Itoa(int(row.Id)))
+		if len(hashes[i].Val()) > 0 {
+			var cached model.Greeter
+			if err := hashes[i].Scan(&cached); err != nil {
+				return errorsx.Wrap(err, "redis.HGetAll")
+			}
+			del := func() { fix.Del(ctx, key) }
+			if !live && !cached.IsEmpty() {
+				add(repository.GreeterCacheDrift{Id: row.Id, Type: repository.GreeterCacheDriftDeletedHash, Key: key}, del)
+			} else if live {
+				if diff := greeterCacheDiff(row, cached); diff != "" {
+					add(repository.GreeterCacheDrift{Id: row.Id, Type: repository.GreeterCacheDriftStaleHash, Key: key, Detail: diff}, del)
+				}
+			}
+		}
+
+		for s, t := range types {
+			key := utilx.CacheKey("greeter_ids_", strconv.Itoa(s))
+			expected := live && row.Status == int32(s)
+			switch {
+			case members[s] != nil:
+				_, present := members[s][row.Id]
+				delete(members[s], row.Id)
+				if expected && !present {
+					add(repository.GreeterCacheDrift{Id: row.Id, Type: repository.GreeterCacheDriftMissingMember, Key: key}, func() {
+						fix.ZAdd(ctx, key, &redis.Z{Score: float64(row.Id), Member: row.Id})
+					})
+				} else if !expected && present {
+					add(repository.GreeterCacheDrift{Id: row.Id, Type: repository.GreeterCacheDriftExtraMember, Key: key}, func() {
+						fix.ZRem(ctx, key, row.Id)
+					})
+				}
+			case t.Val() == "string" && expected:
+				// 缓存为空列表但存在该状态的Greeter，删除后由下次查询重建
+				add(repository.GreeterCacheDrift{Id: row.Id, Type: repository.GreeterCacheDriftMissingMember, Key: key}, func() {
+					fix.Del(ctx, key)
+				})
+			}
+		}
+	}
+
+	// 剩余的成员在数据库中不存在
+	for s, ids := range members {
+		key := utilx.CacheKey("greeter_ids_", strconv.Itoa(s))
+		for _, id := range sortedIds(ids) {
+			id := id
+			add(repository.GreeterCacheDrift{Id: id, Type: repository.GreeterCacheDriftExtraMember, Key: key}, func() {
+				fix.ZRem(ctx, key, id)
+			})
+		}
+	}
+
+	if queued > 0 {
+		if _, err := fix.Exec(ctx); err != nil {
+			return errorsx.Wrap(err, "redis.Pipeline repair")
+		}
+	}
+	return nil
+}
+
+// greeterCacheDiff 返回缓存与数据库不一致的字段，日期时间字段的格式与驱动有关，不参与比较
+func greeterCacheDiff(row, cached model.Greeter) string {
+	var fields []string
+	if cached.Id != row.Id {
+		fields = append(fields, "id")
+	}
+	if cached.Name != row.Name {
+		fields = append(fields, "name")
+	}
+	if cached.ViewNum != row.ViewNum {
+		fields = append(fields, "view_num")
+	}
+	if cached.Status != row.Status {
+		fields = append(fields, "status")
+	}
+	if cached.CreateTime != row.CreateTime {
+		fields = append(fields, "create_time")
+	}
+	if cached.Version != row.Version {
+		fields = append(fields, "version")
+	}
+	if cached.StatusOperator != row.StatusOperator {
+		fields = append(fields, "status_operator")
+	}
+	return strings.Join(fields, ",")
+}
+
+func sortedIds(ids map[int32]struct{}) []int32 {
+	sorted := make([]int32, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+func (s *Suite) TestGreeterRepository_VerifyGreeterCache() {
+	columns := []string{"id", "name", "status", "version", "deleted_at"}
+	ids := func(status string) string {
+		return utilx.CacheKey("greeter_ids_", status)
+	}
+	expectTypes := func(types ...string) {
+		for i, t := range types {
+			s.redisMock.ExpectType(ids(strconv.Itoa(i))).SetVal(t)
+		}
+	}
+
+	// 第一批：1的哈希表版本号落后且不在有序集合中，2已删除但仍在缓存中
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id > \\? ORDER BY id LIMIT 2$").WithArgs(0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "koofox", 1, 2, nil).AddRow(2, "foxkoo", 1, 1, time.Now()))
+	s.redisMock.ExpectHGetAll(utilx.CacheKey("greeter_1")).SetVal(map[string]string{"id": "1", "name": "koofox", "status": "1", "version": "1"})
+	s.redisMock.ExpectHGetAll(utilx.CacheKey("greeter_2")).SetVal(map[string]string{"id": "2", "name": "foxkoo", "status": "1", "version": "1"})
+	expectTypes("none", "zset", "string", "none")
+	s.redisMock.ExpectZRangeByScore(ids("1"), &redis.ZRangeBy{Min: "(0", Max: "2"}).SetVal([]string{"2"})
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_1")).SetVal(1)
+	s.redisMock.ExpectZAdd(ids("1"), &redis.Z{Score: 1, Member: int32(1)}).SetVal(1)
+	s.redisMock.ExpectDel(utilx.CacheKey("greeter_2")).SetVal(1)
+	s.redisMock.ExpectZRem(ids("1"), int32(2)).SetVal(1)
+
+	// 最后一批：3的状态为2但缓存为空列表，有序集合中的9在数据库中不存在
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE id > \\? ORDER BY id LIMIT 2$").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "fox", 2, 1, nil))
+	s.redisMock.ExpectHGetAll(utilx.CacheKey("greeter_3")).SetVal(map[string]string{})
+	expectTypes("none", "zset", "string", "none")
+	s.redisMock.ExpectZRangeByScore(ids("1"), &redis.ZRangeBy{Min: "(2", Max: "+inf"}).SetVal([]string{"9"})
+	s.redisMock.ExpectDel(ids("2")).SetVal(1)
+	s.redisMock.ExpectZRem(ids("1"), int32(9)).SetVal(1)
+
+	report, err := s.repo.VerifyGreeterCache(context.Background(), 2, true)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 3, report.Checked)
+	require.Equal(s.T(), 6, report.Repaired)
+	require.Equal(s.T(), map[string]int{
+		repository.GreeterCacheDriftStaleHash:     1,
+		repository.GreeterCacheDriftDeletedHash:   1,
+		repository.GreeterCacheDriftMissingMember: 2,
+		repository.GreeterCacheDriftExtraMember:   2,
+	}, report.Counts)
+	require.Equal(s.T(), repository.GreeterCacheDrift{Id: 1, Type: repository.GreeterCacheDriftStaleHash,
+		Key: utilx.CacheKey("greeter_1"), Detail: "version", Repaired: true}, report.Drifts[0])
+}
@@ -0,0 +1,97 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package persistence
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	errorsx "github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	redisx "github.com/imind-lab/micro/redis"
+	"github.com/imind-lab/micro/tracing"
+	"github.com/imind-lab/micro/util"
+)
+
+// NewGreeterCacheWarmer 创建从MySQL预热Redis中Greeter缓存的实例
+func NewGreeterCacheWarmer() repository.GreeterCacheWarmer {
+	return greeterRepository{
+		Dao: newDao(),
+	}
+}
+
+func (repo greeterRepository) WarmGreeterListIds(ctx context.Context, status int32) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.WarmGreeterListIds")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "WarmGreeterListIds"))
+
+	cnt, err := repo.RebuildGreeterListIds(ctx, status)
+	if err != nil {
+		return 0, errorsx.WithMessage(err, "greeterRepository.WarmGreeterListIds")
+	}
+	// 总数与有序集合一同预热，分页时两者都会读取
+	key := utilx.CacheKey("greeter_cnt_", strconv.Itoa(int(status)))
+	if _, err := repo.loadGreetersCount(ctx, logger, status, key); err != nil {
+		return cnt, errorsx.WithMessage(err, "greeterRepository.WarmGreeterListIds")
+	}
+	return cnt, nil
+}
+
+func (repo greeterRepository) WarmTopGreeters(ctx context.Context, topN int) (int, error) {
+	span, ctx := tracing.StartSpan(ctx, "greeterRepository.WarmTopGreeters")
+	defer span.Finish()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterRepository"), zap.String("func", "WarmTopGreeters"))
+
+	var (
+		after  *model.Greeter
+		warmed int
+	)
+	for warmed < topN {
+		limit := topN - warmed
+		if limit > constant.GreeterIdsChunkSize {
+			limit = constant.GreeterIdsChunkSize
+		}
+		tx := repo.masterDB(ctx).Model(model.Greeter{})
+		if after != nil {
+			tx = tx.Where("(view_num < ? OR (view_num = ? AND id < ?))", after.ViewNum, after.ViewNum, after.Id)
+		}
+		var list []model.Greeter
+		if err := tx.Order("view_num DESC, id DESC").Limit(limit).Find(&list).Error; err != nil {
+			return warmed, errorsx.Wrap(err, "greeterRepository.WarmTopGreeters")
+		}
+		if len(list) == 0 {
+			break
+		}
+
+		pipe := repo.Redis().Pipeline()
+		for _, m := range list {
+			key := utilx.CacheKey("greeter_", strconv.Itoa(int(m.Id)))
+			pipe.HMSet(ctx, key, redisx.FlatStruct(m))
+			pipe.Expire(ctx, key, constant.CacheMinute5+util.RandDuration(120))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return warmed, errorsx.Wrap(err, "greeterRepository.WarmTopGreeters.HMSet")
+		}
+
+		warmed += len(list)
+		logger.Info("warm top greeters", zap.Int("warmed", warmed), zap.Int("topN", topN))
+		if len(list) < limit {
+			break
+		}
+		after = &list[len(list)-1]
+	}
+	return warmed, nil
+}
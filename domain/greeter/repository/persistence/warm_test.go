@@ -0,0 +1,54 @@
+package persistence
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	redisx "github.com/imind-lab/micro/redis"
+)
+
+func (s *Suite) TestGreeterRepository_WarmGreeterListIds() {
+	ctx := context.Background()
+
+	s.mysqlMock.ExpectQuery("SELECT `id` FROM `tbl_greeter` WHERE status = \\? .* ORDER BY id DESC LIMIT 1000$").
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	s.expectRebuildGreeterListIds(3)
+	s.mysqlMock.ExpectQuery("SELECT count\\(\\*\\) FROM `tbl_greeter` WHERE status=\\?").
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	s.redisMock.CustomMatch(anyExpire).ExpectSet(utilx.CacheKey("greeter_cnt_3"), int64(0), constant.CacheMinute5).SetVal("OK")
+
+	cnt, err := s.repo.WarmGreeterListIds(ctx, 3)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, cnt)
+}
+
+func (s *Suite) TestGreeterRepository_WarmTopGreeters() {
+	ctx := context.Background()
+	list := []model.Greeter{
+		{Id: 200, Name: "foxkoo", ViewNum: 9, Status: 1},
+		{Id: 100, Name: "koofox", ViewNum: 7, Status: 2},
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "view_num", "status"})
+	for _, m := range list {
+		rows.AddRow(m.Id, m.Name, m.ViewNum, m.Status)
+	}
+	s.mysqlMock.ExpectQuery("SELECT \\* FROM `tbl_greeter` WHERE `tbl_greeter`.`deleted_at` IS NULL ORDER BY view_num DESC, id DESC LIMIT 3$").
+		WillReturnRows(rows)
+	for _, m := range list {
+		key := utilx.CacheKey("greeter_", strconv.Itoa(int(m.Id)))
+		s.redisMock.ExpectHMSet(key, redisx.FlatStruct(m)).SetVal(true)
+		s.redisMock.CustomMatch(anyExpire).ExpectExpire(key, constant.CacheMinute5).SetVal(true)
+	}
+
+	// 数据库中只有2个Greeter，不再读取下一批
+	cnt, err := s.repo.WarmTopGreeters(ctx, 3)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, cnt)
+}
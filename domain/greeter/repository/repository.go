@@ -2,18 +2,48 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
+
 	"github.com/imind-lab/greeter/domain/greeter/repository/model"
 )
 
+// ErrVersionConflict 记录存在，但版本号与期望的版本号不一致
+var ErrVersionConflict = errors.New("greeter version conflict")
+
+// GreeterFailure 批量读取时读取失败或超时前未读取的Greeter
+type GreeterFailure struct {
+	Id  int32
+	Err error
+}
+
 type GreeterRepository interface {
 	CreateGreeter(ctx context.Context, m model.Greeter) (model.Greeter, error)
 
 	GetGreeterById(ctx context.Context, id int32, opt ...GreeterByIdOption) (model.Greeter, error)
 	FindGreeterById(ctx context.Context, id int32) (model.Greeter, error)
-	GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) ([]model.Greeter, int, error)
+	// FindGreeterForUpdate 从主库读取Greeter并加行锁，在Transaction中调用时锁持有到事务结束，用于写入前的校验；
+	// 不存在或已删除时返回空对象
+	FindGreeterForUpdate(ctx context.Context, id int32) (model.Greeter, error)
+	GetGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, []int32, error)
+	FindGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, error)
+	// GetGreeterList 返回读取成功的Greeter、总数以及读取失败的Greeter，读取失败时当前页不完整，但error为nil
+	GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32, opt ...GreeterListOption) ([]model.Greeter, int, []GreeterFailure, error)
+	// FindGreeterList 按filter查询数据库，不使用缓存，after不为空时返回其后的pageSize条，
+	// 否则跳过前(page-1)*pageSize条，同时返回满足条件的总数
+	FindGreeterList(ctx context.Context, filter GreeterListFilter, after *GreeterListAfter, pageSize, page int32) ([]model.Greeter, int, error)
+
+	UpdateGreeter(ctx context.Context, m model.Greeter, columns []string) (int64, error)
+	UpdateGreeterStatus(ctx context.Context, id, status, version int32, operator string) (int64, error)
+	UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error)
+	// AddGreeterCounters 在一个事务中将各批计数增量加到数据库中并记录批次，已记录的批次不再累加；版本号加1，不修改更新时间
+	AddGreeterCounters(ctx context.Context, batches map[int32]GreeterCounterBatch) error
 
-	UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error)
-	UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error)
+	DeleteGreeterById(ctx context.Context, id, version int32) (int64, error)
+	RestoreGreeter(ctx context.Context, id, version int32) (int64, error)
+	PurgeDeletedGreeters(ctx context.Context, before time.Time) (int64, error)
 
-	DeleteGreeterById(ctx context.Context, id int32) (int64, error)
+	// Transaction 在一个数据库事务中执行fn，fn通过其ctx调用的仓库方法（包括GreeterOutboxRepository和GreeterAuditRepository）都使用该事务，
+	// 这些方法的缓存更新在提交后执行；fn返回错误时回滚，已在事务中时直接执行fn
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
@@ -0,0 +1,388 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+// Package repositorytest 提供repository.GreeterRepository各实现共用的一致性测试
+package repositorytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// GreeterRepositorySuite 校验GreeterRepository的实现与约定的语义一致：排序、按状态分页、不存在时的返回值、
+// 软删除、版本号以及影响的行数；每个用例前通过NewRepo创建一个空的仓库
+type GreeterRepositorySuite struct {
+	suite.Suite
+
+	NewRepo func() repository.GreeterRepository
+
+	ctx  context.Context
+	repo repository.GreeterRepository
+}
+
+func (s *GreeterRepositorySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.repo = s.NewRepo()
+}
+
+// create 按顺序创建greeters，返回创建后的记录
+func (s *GreeterRepositorySuite) create(greeters ...model.Greeter) []model.Greeter {
+	created := make([]model.Greeter, len(greeters))
+	for i, m := range greeters {
+		var err error
+		created[i], err = s.repo.CreateGreeter(s.ctx, m)
+		s.Require().NoError(err)
+	}
+	return created
+}
+
+func ids(list []model.Greeter) []int32 {
+	ids := make([]int32, len(list))
+	for i, m := range list {
+		ids[i] = m.Id
+	}
+	return ids
+}
+
+func (s *GreeterRepositorySuite) TestCreateAndFind() {
+	created := s.create(model.Greeter{Name: "koofox", Status: 1, CreateTime: 100}, model.Greeter{Name: "foxkoo", Status: 2, CreateTime: 200})
+	s.Require().Greater(created[0].Id, int32(0))
+	s.Require().Greater(created[1].Id, created[0].Id)
+	s.Require().EqualValues(1, created[0].Version)
+	s.Require().NotEmpty(created[0].CreateDatetime)
+	s.Require().Equal(created[0].CreateDatetime, created[0].StatusDatetime)
+
+	for _, get := range []func(context.Context, int32) (model.Greeter, error){
+		func(ctx context.Context, id int32) (model.Greeter, error) { return s.repo.GetGreeterById(ctx, id) },
+		s.repo.FindGreeterById,
+	} {
+		m, err := get(s.ctx, created[0].Id)
+		s.Require().NoError(err)
+		s.Require().Equal("koofox", m.Name)
+		s.Require().EqualValues(1, m.Status)
+		s.Require().EqualValues(100, m.CreateTime)
+		s.Require().EqualValues(1, m.Version)
+
+		// 不存在时返回空对象，不返回错误
+		m, err = get(s.ctx, created[1].Id+100)
+		s.Require().NoError(err)
+		s.Require().True(m.IsEmpty())
+	}
+}
+
+func (s *GreeterRepositorySuite) TestGetGreetersByIds() {
+	created := s.create(model.Greeter{Name: "a", Status: 1}, model.Greeter{Name: "b", Status: 1}, model.Greeter{Name: "c", Status: 2})
+	missing := created[2].Id + 100
+
+	list, notFound, err := s.repo.GetGreetersByIds(s.ctx, []int32{created[2].Id, missing, created[0].Id})
+	s.Require().NoError(err)
+	s.Require().Equal([]int32{created[2].Id, created[0].Id}, ids(list))
+	s.Require().Equal([]int32{missing}, notFound)
+
+	list, err = s.repo.FindGreetersByIds(s.ctx, []int32{created[1].Id, missing, created[0].Id})
+	s.Require().NoError(err)
+	s.Require().ElementsMatch([]int32{created[0].Id, created[1].Id}, ids(list))
+
+	list, err = s.repo.FindGreetersByIds(s.ctx, nil)
+	s.Require().NoError(err)
+	s.Require().Empty(list)
+}
+
+func (s *GreeterRepositorySuite) TestGetGreeterList() {
+	var greeters []model.Greeter
+	for i := 0; i < 5; i++ {
+		greeters = append(greeters, model.Greeter{Name: "koofox", Status: 1}, model.Greeter{Name: "foxkoo", Status: 2})
+	}
+	created := s.create(greeters...)
+	// status为1的id倒序
+	var active []int32
+	for i := len(created) - 1; i >= 0; i-- {
+		if created[i].Status == 1 {
+			active = append(active, created[i].Id)
+		}
+	}
+
+	list, cnt, failed, err := s.repo.GetGreeterList(s.ctx, 1, 0, 2, 1)
+	s.Require().NoError(err)
+	s.Require().Empty(failed)
+	s.Require().Equal(5, cnt)
+	s.Require().Equal(active[:2], ids(list))
+
+	list, _, _, err = s.repo.GetGreeterList(s.ctx, 1, 0, 2, 3)
+	s.Require().NoError(err)
+	s.Require().Equal(active[4:], ids(list))
+
+	// lastId大于0时为keyset分页，忽略page
+	list, cnt, _, err = s.repo.GetGreeterList(s.ctx, 1, active[1], 2, 5)
+	s.Require().NoError(err)
+	s.Require().Equal(5, cnt)
+	s.Require().Equal(active[2:4], ids(list))
+
+	list, cnt, _, err = s.repo.GetGreeterList(s.ctx, 3, 0, 2, 1)
+	s.Require().NoError(err)
+	s.Require().Zero(cnt)
+	s.Require().Empty(list)
+
+	// 已删除的记录只在WithDeleted时返回
+	affected, err := s.repo.DeleteGreeterById(s.ctx, active[0], 0)
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	list, cnt, _, err = s.repo.GetGreeterList(s.ctx, 1, 0, 10, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(4, cnt)
+	s.Require().Equal(active[1:], ids(list))
+	list, cnt, _, err = s.repo.GetGreeterList(s.ctx, 1, 0, 10, 1, repository.GreeterListWithDeleted(true))
+	s.Require().NoError(err)
+	s.Require().Equal(5, cnt)
+	s.Require().Equal(active, ids(list))
+}
+
+func (s *GreeterRepositorySuite) TestFindGreeterList() {
+	created := s.create(
+		model.Greeter{Name: "koofox", Status: 1, ViewNum: 3, CreateTime: 100},
+		model.Greeter{Name: "koo_fox", Status: 1, ViewNum: 5, CreateTime: 200},
+		model.Greeter{Name: "foxkoo", Status: 2, ViewNum: 3, CreateTime: 300},
+		model.Greeter{Name: "kooala", Status: 1, ViewNum: 3, CreateTime: 400},
+		model.Greeter{Name: "fox", Status: 3, ViewNum: 9, CreateTime: 500},
+	)
+	find := func(filter repository.GreeterListFilter, after *repository.GreeterListAfter, pageSize, page int32) ([]int32, int) {
+		list, cnt, err := s.repo.FindGreeterList(s.ctx, filter, after, pageSize, page)
+		s.Require().NoError(err)
+		return ids(list), cnt
+	}
+	id := func(i int) int32 { return created[i].Id }
+
+	list, cnt := find(repository.GreeterListFilter{Statuses: []int32{1, 2}, SortBy: repository.GreeterSortById}, nil, 10, 1)
+	s.Require().Equal(4, cnt)
+	s.Require().Equal([]int32{id(3), id(2), id(1), id(0)}, list)
+
+	// 排序字段相同时按id以相同方向排序
+	filter := repository.GreeterListFilter{Statuses: []int32{1, 2}, SortBy: repository.GreeterSortByViewNum}
+	list, _ = find(filter, nil, 10, 1)
+	s.Require().Equal([]int32{id(1), id(3), id(2), id(0)}, list)
+	list, _ = find(filter, &repository.GreeterListAfter{Value: 3, Id: id(3)}, 10, 1)
+	s.Require().Equal([]int32{id(2), id(0)}, list)
+	filter.Ascending = true
+	list, _ = find(filter, nil, 3, 1)
+	s.Require().Equal([]int32{id(0), id(2), id(3)}, list)
+	list, cnt = find(filter, &repository.GreeterListAfter{Value: 3, Id: id(2)}, 10, 1)
+	s.Require().Equal(4, cnt)
+	s.Require().Equal([]int32{id(3), id(1)}, list)
+	list, _ = find(filter, nil, 3, 2)
+	s.Require().Equal([]int32{id(1)}, list)
+
+	// 名称中的通配符按字面匹配
+	list, cnt = find(repository.GreeterListFilter{Statuses: []int32{1}, NamePrefix: "koo_", SortBy: repository.GreeterSortById}, nil, 10, 1)
+	s.Require().Equal(1, cnt)
+	s.Require().Equal([]int32{id(1)}, list)
+	list, _ = find(repository.GreeterListFilter{Statuses: []int32{1, 2, 3}, NameContains: "fox", SortBy: repository.GreeterSortById}, nil, 10, 1)
+	s.Require().Equal([]int32{id(4), id(2), id(1), id(0)}, list)
+
+	list, cnt = find(repository.GreeterListFilter{Statuses: []int32{1, 2, 3}, CreateTimeFrom: 200, CreateTimeTo: 500, MinViewNum: 4,
+		SortBy: repository.GreeterSortByCreateTime, Ascending: true}, nil, 10, 1)
+	s.Require().Equal(1, cnt)
+	s.Require().Equal([]int32{id(1)}, list)
+
+	_, _, err := s.repo.FindGreeterList(s.ctx, repository.GreeterListFilter{Statuses: []int32{1}, SortBy: "name"}, nil, 10, 1)
+	s.Require().Error(err)
+}
+
+func (s *GreeterRepositorySuite) TestUpdateGreeter() {
+	m := s.create(model.Greeter{Name: "koofox", Status: 1, ViewNum: 3})[0]
+
+	// 只更新columns指定的列，版本号加1
+	affected, err := s.repo.UpdateGreeter(s.ctx, model.Greeter{Id: m.Id, Name: "foxkoo", ViewNum: 100, Version: 1}, []string{"name"})
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	got, err := s.repo.FindGreeterById(s.ctx, m.Id)
+	s.Require().NoError(err)
+	s.Require().Equal("foxkoo", got.Name)
+	s.Require().EqualValues(3, got.ViewNum)
+	s.Require().EqualValues(2, got.Version)
+
+	affected, err = s.repo.UpdateGreeter(s.ctx, model.Greeter{Id: m.Id, Name: "fox", Version: 1}, []string{"name"})
+	s.Require().ErrorIs(err, repository.ErrVersionConflict)
+	s.Require().Zero(affected)
+
+	// 版本号为0时不检查
+	affected, err = s.repo.UpdateGreeter(s.ctx, model.Greeter{Id: m.Id, Name: "fox"}, []string{"name"})
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+
+	affected, err = s.repo.UpdateGreeter(s.ctx, model.Greeter{Id: m.Id + 100, Name: "fox", Version: 1}, []string{"name"})
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+}
+
+func (s *GreeterRepositorySuite) TestUpdateGreeterStatus() {
+	m := s.create(model.Greeter{Name: "koofox", Status: 1})[0]
+
+	affected, err := s.repo.UpdateGreeterStatus(s.ctx, m.Id, 2, 1, "koofox")
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	got, err := s.repo.FindGreeterById(s.ctx, m.Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(2, got.Status)
+	s.Require().EqualValues(2, got.Version)
+	s.Require().Equal("koofox", got.StatusOperator)
+	s.Require().NotEmpty(got.StatusDatetime)
+
+	// 状态变更后移动到新状态的列表中
+	list, cnt, _, err := s.repo.GetGreeterList(s.ctx, 2, 0, 10, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(1, cnt)
+	s.Require().Equal([]int32{m.Id}, ids(list))
+	_, cnt, _, err = s.repo.GetGreeterList(s.ctx, 1, 0, 10, 1)
+	s.Require().NoError(err)
+	s.Require().Zero(cnt)
+
+	_, err = s.repo.UpdateGreeterStatus(s.ctx, m.Id, 1, 1, "koofox")
+	s.Require().ErrorIs(err, repository.ErrVersionConflict)
+}
+
+func (s *GreeterRepositorySuite) TestUpdateGreeterCount() {
+	created := s.create(model.Greeter{Name: "koofox", Status: 1, ViewNum: 3}, model.Greeter{Name: "foxkoo", Status: 1})
+
+	affected, err := s.repo.UpdateGreeterCount(s.ctx, created[0].Id, 2, 1, "view_num")
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+
+	_, err = s.repo.UpdateGreeterCount(s.ctx, created[0].Id, 2, 0, "status")
+	s.Require().ErrorIs(err, repository.ErrCounterNotAllowed)
+
+	// 批量累加时有增量的Greeter版本号加1并记录批次Id
+	batches := map[int32]repository.GreeterCounterBatch{
+		created[0].Id: {Id: "batch1", Delta: repository.GreeterCounterDelta{"view_num": 10}},
+		created[1].Id: {Id: "batch1", Delta: repository.GreeterCounterDelta{"view_num": 0}},
+	}
+	err = s.repo.AddGreeterCounters(s.ctx, batches)
+	s.Require().NoError(err)
+	got, err := s.repo.FindGreeterById(s.ctx, created[0].Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(15, got.ViewNum)
+	s.Require().EqualValues(3, got.Version)
+	s.Require().Equal("batch1", got.CounterBatch)
+	got, err = s.repo.FindGreeterById(s.ctx, created[1].Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, got.Version)
+
+	// 同一批再次写入时不重复累加
+	err = s.repo.AddGreeterCounters(s.ctx, batches)
+	s.Require().NoError(err)
+	got, err = s.repo.FindGreeterById(s.ctx, created[0].Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(15, got.ViewNum)
+	s.Require().EqualValues(3, got.Version)
+
+	err = s.repo.AddGreeterCounters(s.ctx, map[int32]repository.GreeterCounterBatch{
+		created[1].Id: {Id: "batch2", Delta: repository.GreeterCounterDelta{"name": 1}},
+	})
+	s.Require().ErrorIs(err, repository.ErrCounterNotAllowed)
+}
+
+func (s *GreeterRepositorySuite) TestDeleteAndRestore() {
+	m := s.create(model.Greeter{Name: "koofox", Status: 1})[0]
+
+	_, err := s.repo.DeleteGreeterById(s.ctx, m.Id, 2)
+	s.Require().ErrorIs(err, repository.ErrVersionConflict)
+
+	affected, err := s.repo.DeleteGreeterById(s.ctx, m.Id, 1)
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	got, err := s.repo.GetGreeterById(s.ctx, m.Id)
+	s.Require().NoError(err)
+	s.Require().True(got.IsEmpty())
+
+	// 已删除的记录不能再删除或更新
+	affected, err = s.repo.DeleteGreeterById(s.ctx, m.Id, 1)
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+	affected, err = s.repo.UpdateGreeterStatus(s.ctx, m.Id, 2, 1, "koofox")
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+
+	// 删除时版本号加1，删除前的版本号不能用于恢复
+	_, err = s.repo.RestoreGreeter(s.ctx, m.Id, 1)
+	s.Require().ErrorIs(err, repository.ErrVersionConflict)
+	affected, err = s.repo.RestoreGreeter(s.ctx, m.Id, 2)
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	got, err = s.repo.GetGreeterById(s.ctx, m.Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(3, got.Version)
+
+	// 未删除的记录恢复时没有影响的行
+	affected, err = s.repo.RestoreGreeter(s.ctx, m.Id, 3)
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+}
+
+func (s *GreeterRepositorySuite) TestPurgeDeletedGreeters() {
+	created := s.create(model.Greeter{Name: "koofox", Status: 1}, model.Greeter{Name: "foxkoo", Status: 1})
+	_, err := s.repo.DeleteGreeterById(s.ctx, created[0].Id, 0)
+	s.Require().NoError(err)
+
+	affected, err := s.repo.PurgeDeletedGreeters(s.ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+
+	affected, err = s.repo.PurgeDeletedGreeters(s.ctx, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	affected, err = s.repo.RestoreGreeter(s.ctx, created[0].Id, 0)
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+
+	list, cnt, _, err := s.repo.GetGreeterList(s.ctx, 1, 0, 10, 1, repository.GreeterListWithDeleted(true))
+	s.Require().NoError(err)
+	s.Require().Equal(1, cnt)
+	s.Require().Equal([]int32{created[1].Id}, ids(list))
+}
+
+// TestConcurrentWrites 并发的创建和不带版本号的累加互不覆盖
+func (s *GreeterRepositorySuite) TestConcurrentWrites() {
+	m := s.create(model.Greeter{Name: "koofox", Status: 1})[0]
+
+	const n = 20
+	var wg sync.WaitGroup
+	created := make([]int32, n)
+	errs := make(chan error, 2*n)
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			c, err := s.repo.CreateGreeter(s.ctx, model.Greeter{Name: "foxkoo", Status: 2})
+			created[i] = c.Id
+			errs <- err
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := s.repo.UpdateGreeterCount(s.ctx, m.Id, 1, 0, "view_num")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	got, err := s.repo.FindGreeterById(s.ctx, m.Id)
+	s.Require().NoError(err)
+	s.Require().EqualValues(n, got.ViewNum)
+	s.Require().EqualValues(n+1, got.Version)
+	list, cnt, _, err := s.repo.GetGreeterList(s.ctx, 2, 0, n, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(n, cnt)
+	s.Require().ElementsMatch(created, ids(list))
+}
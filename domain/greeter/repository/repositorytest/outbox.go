@@ -0,0 +1,184 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// GreeterOutboxRepositorySuite 校验GreeterOutboxRepository的实现与约定的语义一致：按写入顺序中继、
+// 发送、失败和死信的记录以及积压数量；每个用例前通过NewRepo创建一个空的发件箱
+type GreeterOutboxRepositorySuite struct {
+	suite.Suite
+
+	NewRepo func() repository.GreeterOutboxRepository
+
+	ctx  context.Context
+	repo repository.GreeterOutboxRepository
+}
+
+func (s *GreeterOutboxRepositorySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.repo = s.NewRepo()
+}
+
+// relay 中继一批事件，返回交给relay的事件
+func (s *GreeterOutboxRepositorySuite) relay(batchSize int) []model.GreeterOutbox {
+	var list []model.GreeterOutbox
+	n, err := s.repo.RelayGreeterOutbox(s.ctx, batchSize, func(ctx context.Context, batch []model.GreeterOutbox) error {
+		list = batch
+		return nil
+	})
+	s.Require().NoError(err)
+	s.Require().Len(list, n)
+	return list
+}
+
+func (s *GreeterOutboxRepositorySuite) pending() int64 {
+	count, err := s.repo.CountPendingGreeterOutbox(s.ctx)
+	s.Require().NoError(err)
+	return count
+}
+
+func (s *GreeterOutboxRepositorySuite) TestRelayInOrder() {
+	for _, key := range []string{"100", "200", "100"} {
+		s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_update", MessageKey: key, Body: []byte("koofox")}))
+	}
+	s.Require().EqualValues(3, s.pending())
+
+	list := s.relay(2)
+	s.Require().Len(list, 2)
+	s.Require().Less(list[0].Id, list[1].Id)
+	s.Require().Equal([]string{"100", "200"}, []string{list[0].MessageKey, list[1].MessageKey})
+	s.Require().Equal("greeter_update", list[0].Topic)
+	s.Require().Equal([]byte("koofox"), list[0].Body)
+	s.Require().NotZero(list[0].CreateTime)
+
+	// 未标记发送的事件下次仍会取出
+	s.Require().Equal(list[0].Id, s.relay(2)[0].Id)
+
+	s.Require().NoError(s.repo.MarkGreeterOutboxSent(s.ctx, list[0].Id))
+	s.Require().EqualValues(2, s.pending())
+	list = s.relay(10)
+	s.Require().Len(list, 2)
+	s.Require().Equal("200", list[0].MessageKey)
+}
+
+func (s *GreeterOutboxRepositorySuite) TestRelayError() {
+	s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_create", MessageKey: "100"}))
+
+	n, err := s.repo.RelayGreeterOutbox(s.ctx, 10, func(ctx context.Context, batch []model.GreeterOutbox) error {
+		return errors.New("broker is not connected")
+	})
+	s.Require().Error(err)
+	s.Require().Equal(1, n)
+	s.Require().EqualValues(1, s.pending())
+
+	n, err = s.repo.RelayGreeterOutbox(s.ctx, 10, func(ctx context.Context, batch []model.GreeterOutbox) error {
+		s.Require().Empty(batch[0].Body)
+		return s.repo.MarkGreeterOutboxSent(ctx, batch[0].Id)
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	s.Require().Zero(s.pending())
+	s.Require().Empty(s.relay(10))
+}
+
+func (s *GreeterOutboxRepositorySuite) TestMarkFailed() {
+	s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_create", MessageKey: "100"}))
+	id := s.relay(10)[0].Id
+
+	// 已到下次发布时间的事件仍会取出
+	next := time.Now().Add(-time.Second)
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, id, next, "broker is not connected"))
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, id, next, "kafka: client has run out of available brokers"))
+
+	list := s.relay(10)
+	s.Require().Len(list, 1)
+	s.Require().EqualValues(2, list[0].Attempts)
+	s.Require().Equal(next.UnixNano()/int64(time.Millisecond), list[0].NextAttemptTime)
+	s.Require().Equal("kafka: client has run out of available brokers", list[0].LastError)
+
+	// 未到下次发布时间的事件不取出，但仍计入积压
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, id, time.Now().Add(time.Minute), "broker is not connected"))
+	s.Require().Empty(s.relay(10))
+	s.Require().EqualValues(1, s.pending())
+
+	// 已发送的事件不再记录失败
+	s.Require().NoError(s.repo.MarkGreeterOutboxSent(s.ctx, id))
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, id, next, "late"))
+	s.Require().Zero(s.pending())
+}
+
+func (s *GreeterOutboxRepositorySuite) TestRelaySkipsWaitingKey() {
+	// 等待重试的key后面超过一批的事件都不取出，也不影响其他key
+	for i := 0; i < 5; i++ {
+		s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_update", MessageKey: "100"}))
+	}
+	s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_update", MessageKey: "200"}))
+	head := s.relay(2)[0]
+	s.Require().Equal("100", head.MessageKey)
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, head.Id, time.Now().Add(time.Minute), "message is too large"))
+
+	list := s.relay(2)
+	s.Require().Len(list, 1)
+	s.Require().Equal("200", list[0].MessageKey)
+	s.Require().EqualValues(6, s.pending())
+}
+
+func (s *GreeterOutboxRepositorySuite) TestMarkDead() {
+	for _, key := range []string{"100", "100"} {
+		s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_update", MessageKey: key}))
+	}
+	head := s.relay(10)[0]
+	s.Require().NoError(s.repo.MarkGreeterOutboxFailed(s.ctx, head.Id, time.Now().Add(time.Minute), "message is too large"))
+	s.Require().Empty(s.relay(10))
+
+	// 进入死信的事件不再取出，也不阻塞同一key后面的事件
+	s.Require().NoError(s.repo.MarkGreeterOutboxDead(s.ctx, head.Id, "message is too large"))
+	list := s.relay(10)
+	s.Require().Len(list, 1)
+	s.Require().Greater(list[0].Id, head.Id)
+	s.Require().EqualValues(1, s.pending())
+	dead, err := s.repo.CountDeadGreeterOutbox(s.ctx)
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, dead)
+
+	// 死信中的事件不会被删除
+	s.Require().NoError(s.repo.MarkGreeterOutboxSent(s.ctx, head.Id))
+	affected, err := s.repo.PurgeSentGreeterOutbox(s.ctx, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+}
+
+func (s *GreeterOutboxRepositorySuite) TestPurgeSent() {
+	for _, key := range []string{"100", "200"} {
+		s.Require().NoError(s.repo.CreateGreeterOutbox(s.ctx, model.GreeterOutbox{Topic: "greeter_create", MessageKey: key}))
+	}
+	list := s.relay(10)
+	s.Require().NoError(s.repo.MarkGreeterOutboxSent(s.ctx, list[0].Id))
+
+	affected, err := s.repo.PurgeSentGreeterOutbox(s.ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(affected)
+
+	// 未发送的事件不会删除
+	affected, err = s.repo.PurgeSentGreeterOutbox(s.ctx, time.Now().Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, affected)
+	list = s.relay(10)
+	s.Require().Len(list, 1)
+	s.Require().Equal("200", list[0].MessageKey)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+)
+
+// 搜索结果的相关度，名称与query完全相同的最高，其次是以query开头的，其余为包含query的，均不区分大小写
+const (
+	GreeterSearchScoreContains float64 = 1
+	GreeterSearchScorePrefix   float64 = 2
+	GreeterSearchScoreExact    float64 = 3
+)
+
+// GreeterSearchHit 搜索命中的Greeter及其相关度
+type GreeterSearchHit struct {
+	Id    int32
+	Score float64
+}
+
+// GreeterSearchIndex Greeter名称的搜索索引，由领域层在Greeter变更后同步
+type GreeterSearchIndex interface {
+	// IndexGreeter 新增或更新m的索引
+	IndexGreeter(ctx context.Context, m model.Greeter) error
+	// RemoveGreeter 删除id的索引
+	RemoveGreeter(ctx context.Context, id int32) error
+	// SearchGreeters 返回名称包含query的Greeter，按相关度倒序、id倒序排列，同时返回命中的总数
+	SearchGreeters(ctx context.Context, query string, offset, limit int32) ([]GreeterSearchHit, int, error)
+}
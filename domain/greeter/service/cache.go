@@ -0,0 +1,203 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/cache"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/dao"
+)
+
+// ErrCacheNotSupported 数据存储没有Redis缓存，例如storage为memory时
+var ErrCacheNotSupported = errors.New("greeter cache is not supported by the storage")
+
+// greeterCacheChannel 通知所有实例删除进程内缓存的频道，消息为逗号分隔的Greeter id
+const greeterCacheChannel = "greeter_cache_evict"
+
+var (
+	greeterCacheOnce     sync.Once
+	greeterCacheInstance *greeterCache
+)
+
+// greeterCache Redis缓存以及Redis之前的进程内缓存（L1），未开启cache.local时local为nil，只使用Redis
+type greeterCache struct {
+	dao.Cache
+
+	local *cache.LRU
+}
+
+// newGreeterCache 同一进程内的GreeterDomain共享一个进程内缓存
+func newGreeterCache() *greeterCache {
+	greeterCacheOnce.Do(func() {
+		c := &greeterCache{Cache: dao.NewCache()}
+		if viper.GetBool("cache.local.enabled") {
+			size := viper.GetInt("cache.local.size")
+			if size <= 0 {
+				size = constant.CacheLocalSize
+			}
+			ttl := viper.GetDuration("cache.local.ttl")
+			if ttl <= 0 {
+				ttl = constant.CacheLocalTTL
+			}
+			c.local = cache.NewLRU(size, ttl)
+		}
+		greeterCacheInstance = c
+	})
+	return greeterCacheInstance
+}
+
+// SubscribeGreeterCache 订阅其他实例的删除通知，ctx结束时退出；未开启cache.local时不订阅。
+// 订阅断开重连期间的通知会丢失，此时进程内缓存最多在cache.local.ttl后过期
+func SubscribeGreeterCache(ctx context.Context) {
+	if utilx.MemoryStorage() {
+		return
+	}
+	c := newGreeterCache()
+	if !c.enabled() {
+		return
+	}
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterCache"), zap.String("func", "SubscribeGreeterCache"))
+
+	sub := c.Redis().Subscribe(ctx, utilx.CacheKey(greeterCacheChannel))
+	go func() {
+		defer sub.Close()
+
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				c.evictLocal(logger, msg.Payload)
+			}
+		}
+	}()
+	logger.Info("subscribe greeter cache evictions")
+}
+
+func (c *greeterCache) enabled() bool {
+	return c != nil && c.local != nil
+}
+
+func (c *greeterCache) getGreeter(id int32) (model.Greeter, bool) {
+	if !c.enabled() {
+		return model.Greeter{}, false
+	}
+	v, ok := c.local.Get(strconv.Itoa(int(id)))
+	if !ok {
+		return model.Greeter{}, false
+	}
+	return v.(model.Greeter), true
+}
+
+// setGreeter 不缓存不存在的Greeter，避免创建后其他实例仍读到空对象
+func (c *greeterCache) setGreeter(m model.Greeter) {
+	if !c.enabled() || m.IsEmpty() {
+		return
+	}
+	c.local.Set(strconv.Itoa(int(m.Id)), m)
+}
+
+// evictGreeters 删除本实例的进程内缓存，并通知其他实例删除，通知失败时其他实例最多在ttl后过期
+func (c *greeterCache) evictGreeters(ctx context.Context, ids ...int32) {
+	if !c.enabled() || len(ids) == 0 {
+		return
+	}
+	members := make([]string, len(ids))
+	for i, id := range ids {
+		members[i] = strconv.Itoa(int(id))
+		c.local.Delete(members[i])
+	}
+	if err := c.Redis().Publish(ctx, utilx.CacheKey(greeterCacheChannel), strings.Join(members, ",")).Err(); err != nil {
+		ctxzap.Extract(ctx).Warn("通知删除进程内缓存失败", zap.String("layer", "greeterCache"), zap.String("func", "evictGreeters"),
+			zap.Int32s("ids", ids), zap.Error(err))
+	}
+}
+
+func (c *greeterCache) evictLocal(logger *zap.Logger, payload string) {
+	for _, member := range strings.Split(payload, ",") {
+		if _, err := strconv.Atoi(member); err != nil {
+			logger.Warn("invalid greeter cache eviction", zap.String("payload", payload))
+			continue
+		}
+		c.local.Delete(member)
+	}
+}
+
+// VerifyGreeterCache 比较数据库与Redis缓存，repair为true时修复不一致并删除修复过的Greeter的进程内缓存
+func (dm greeterDomain) VerifyGreeterCache(ctx context.Context, batchSize int, repair bool) (repository.GreeterCacheReport, error) {
+	if dm.cacheVerifier == nil {
+		return repository.GreeterCacheReport{}, errors.WithMessage(ErrCacheNotSupported, "greeterDomain.VerifyGreeterCache")
+	}
+	report, err := dm.cacheVerifier.VerifyGreeterCache(ctx, batchSize, repair)
+	if err != nil {
+		return report, errors.WithMessage(err, "greeterDomain.VerifyGreeterCache")
+	}
+	var ids []int32
+	for _, drift := range report.Drifts {
+		if drift.Repaired && (drift.Type == repository.GreeterCacheDriftStaleHash || drift.Type == repository.GreeterCacheDriftDeletedHash) {
+			ids = append(ids, drift.Id)
+		}
+	}
+	dm.evictGreeters(ctx, ids...)
+	return report, nil
+}
+
+// WarmGreeterCache 预热全部状态的id有序集合和总数，以及view_num最大的cache.warmup.top_n个Greeter，
+// 预热时间不超过cache.warmup.timeout，超时后返回的错误中包含已完成的进度
+func (dm greeterDomain) WarmGreeterCache(ctx context.Context) error {
+	if dm.cacheWarmer == nil {
+		return errors.WithMessage(ErrCacheNotSupported, "greeterDomain.WarmGreeterCache")
+	}
+	topN := viper.GetInt("cache.warmup.top_n")
+	if topN <= 0 {
+		topN = constant.CacheWarmupTopN
+	}
+	timeout := viper.GetDuration("cache.warmup.timeout")
+	if timeout <= 0 {
+		timeout = constant.CacheWarmupTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterDomain"), zap.String("func", "WarmGreeterCache"))
+	ctx = ctxzap.ToContext(ctx, logger)
+
+	start := time.Now()
+	for s := 0; s <= constant.GreeterStatusMax; s++ {
+		n, err := dm.cacheWarmer.WarmGreeterListIds(ctx, int32(s))
+		if err != nil {
+			return errors.WithMessagef(err, "greeterDomain.WarmGreeterCache status %d", s)
+		}
+		logger.Info("warm greeter ids", zap.Int("status", s), zap.Int("count", n), zap.Duration("elapsed", time.Since(start)))
+	}
+
+	n, err := dm.cacheWarmer.WarmTopGreeters(ctx, topN)
+	if err != nil {
+		return errors.WithMessagef(err, "greeterDomain.WarmGreeterCache top greeters %d/%d", n, topN)
+	}
+	logger.Info("warm greeter cache finished", zap.Int("greeters", n), zap.Duration("elapsed", time.Since(start)))
+	return nil
+}
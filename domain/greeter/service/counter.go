@@ -0,0 +1,126 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// ErrCounterNotAllowed column不是允许累加的计数字段
+var ErrCounterNotAllowed = repository.ErrCounterNotAllowed
+
+// UpdateGreeterCount 累加计数字段，version为0时增量先暂存在计数服务中，由FlushGreeterCounters定期批量写入数据库，
+// 不记录变更历史，写入数据库时不再发布事件；指定version时直接写入数据库并校验版本号
+func (dm greeterDomain) UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error) {
+	if !repository.IsGreeterCounter(column) {
+		return 0, errors.Wrapf(ErrCounterNotAllowed, "greeterDomain.UpdateGreeterCount %s", column)
+	}
+
+	if version <= 0 {
+		m, err := dm.repo.GetGreeterById(ctx, id)
+		if err != nil || m.IsEmpty() {
+			return 0, errors.WithMessage(err, "greeterDomain.UpdateGreeterCount")
+		}
+		if err := dm.counterRepo.IncrGreeterCounter(ctx, id, column, num); err != nil {
+			return 0, errors.WithMessage(err, "greeterDomain.UpdateGreeterCount")
+		}
+		// 增量暂存在计数服务中，无法与事件在同一个事务中写入，写入发件箱失败时只记录日志，
+		// 返回错误会使调用方重试而重复累加
+		if err := dm.publish(ctx, id, greeterCounterChanged(column, num, 0)); err != nil {
+			ctxzap.Extract(ctx).Error("写入Greeter事件失败", zap.String("layer", "greeterDomain"), zap.String("func", "UpdateGreeterCount"),
+				zap.Int32("id", id), zap.Error(err))
+		}
+		return 1, nil
+	}
+
+	affected, _, err := dm.mutate(ctx, id, greeter.GreeterAction_GREETER_ACTION_COUNT, func(ctx context.Context, before model.Greeter) (int64, error) {
+		if before.IsEmpty() {
+			return 0, nil
+		}
+		return dm.repo.UpdateGreeterCount(ctx, id, num, version, column)
+	}, func(_, after model.Greeter) []*greeter.GreeterEvent {
+		return []*greeter.GreeterEvent{greeterCounterChanged(column, num, after.Version)}
+	})
+	if err == nil && affected > 0 {
+		dm.evictGreeters(ctx, id)
+	}
+	return affected, err
+}
+
+// FlushGreeterCounters 将暂存的计数增量分批写入数据库，直到没有待写入的增量，返回写入的Greeter数量
+func (dm greeterDomain) FlushGreeterCounters(ctx context.Context) (int, error) {
+	batchSize := viper.GetInt("counter.batch_size")
+	if batchSize <= 0 {
+		batchSize = constant.GreeterCounterBatchSize
+	}
+
+	total := 0
+	for {
+		n, err := dm.counterRepo.FlushGreeterCounters(ctx, batchSize, dm.addGreeterCounters)
+		total += n
+		if err != nil {
+			return total, errors.WithMessage(err, "greeterDomain.FlushGreeterCounters")
+		}
+		if n < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// addGreeterCounters 写入数据库后删除进程内缓存，否则暂存的增量删除后会读到进程内缓存中旧的计数
+func (dm greeterDomain) addGreeterCounters(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+	if err := dm.repo.AddGreeterCounters(ctx, batches); err != nil {
+		return err
+	}
+	ids := make([]int32, 0, len(batches))
+	for id := range batches {
+		ids = append(ids, id)
+	}
+	dm.evictGreeters(ctx, ids...)
+	return nil
+}
+
+// withPendingCounters 返回加上暂存增量后的副本，不修改list；已按CounterBatch计入的增量不再加，读取增量失败时返回数据库中的值
+func (dm greeterDomain) withPendingCounters(ctx context.Context, list []model.Greeter) []model.Greeter {
+	if len(list) == 0 {
+		return list
+	}
+	greeters := make([]model.Greeter, 0, len(list))
+	ids := make([]int32, 0, len(list))
+	for _, m := range list {
+		if !m.IsEmpty() {
+			greeters = append(greeters, m)
+			ids = append(ids, m.Id)
+		}
+	}
+	pending, err := dm.counterRepo.PendingGreeterCounters(ctx, greeters)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("读取Greeter计数增量失败", zap.String("layer", "greeterDomain"), zap.String("func", "withPendingCounters"),
+			zap.Int32s("ids", ids), zap.Error(err))
+		return list
+	}
+
+	result := make([]model.Greeter, len(list))
+	copy(result, list)
+	for i := range result {
+		if delta, ok := pending[result[i].Id]; ok {
+			delta.Apply(&result[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+// greeterTopics 未配置kafka.<MQName>.topic.<key>时使用的主题
+var greeterTopics = map[string]string{
+	constant.TopicGreeterCreate:  "greeter_create",
+	constant.TopicGreeterUpdate:  "greeter_update",
+	constant.TopicGreeterStatus:  "greeter_status",
+	constant.TopicGreeterCounter: "greeter_counter",
+	constant.TopicGreeterDelete:  "greeter_delete",
+}
+
+// greeterTopic 返回事件key对应的主题
+func greeterTopic(key string) string {
+	if topic := viper.GetString("kafka." + constant.MQName + ".topic." + key); topic != "" {
+		return topic
+	}
+	return greeterTopics[key]
+}
+
+// greeterEventTopic 返回事件发布的主题，每种事件一个主题
+func greeterEventTopic(event *greeter.GreeterEvent) (string, error) {
+	switch event.Payload.(type) {
+	case *greeter.GreeterEvent_Created:
+		return greeterTopic(constant.TopicGreeterCreate), nil
+	case *greeter.GreeterEvent_Updated:
+		return greeterTopic(constant.TopicGreeterUpdate), nil
+	case *greeter.GreeterEvent_StatusChanged:
+		return greeterTopic(constant.TopicGreeterStatus), nil
+	case *greeter.GreeterEvent_CounterChanged:
+		return greeterTopic(constant.TopicGreeterCounter), nil
+	case *greeter.GreeterEvent_Deleted:
+		return greeterTopic(constant.TopicGreeterDelete), nil
+	}
+	return "", errors.Errorf("unknown greeter event payload %T", event.Payload)
+}
+
+// publish 填写事件的信封后写入发件箱，在GreeterRepository.Transaction中调用时与Greeter的变更一起提交，
+// 由RelayGreeterOutbox发布；同一Greeter的事件以id作为消息的key按写入顺序发布
+func (dm greeterDomain) publish(ctx context.Context, id int32, events ...*greeter.GreeterEvent) error {
+	now := timestamppb.New(time.Now())
+	for _, event := range events {
+		event.EventId = uuid.New().String()
+		event.SchemaVersion = constant.GreeterEventSchemaVersion
+		event.OccurredAt = now
+		event.Actor = utilx.Actor(ctx)
+		event.TraceId = utilx.TraceId(ctx)
+		event.RequestId = utilx.RequestId(ctx)
+		event.GreeterId = id
+
+		topic, err := greeterEventTopic(event)
+		if err != nil {
+			return errors.WithMessage(err, "greeterDomain.publish")
+		}
+		body, err := proto.Marshal(event)
+		if err != nil {
+			return errors.Wrap(err, "greeterDomain.publish")
+		}
+		err = dm.outboxRepo.CreateGreeterOutbox(ctx, model.GreeterOutbox{
+			Topic:      topic,
+			MessageKey: strconv.Itoa(int(id)),
+			Body:       body,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "greeterDomain.publish")
+		}
+	}
+	return nil
+}
+
+// mutate 在事务中从主库读取并锁定变更前的Greeter，交给write校验并写入，影响的行数大于0时读取变更后的Greeter，
+// 写入action的变更记录，并将events根据变更前后生成的事件写入发件箱，任何一步失败时变更、变更记录和事件一起回滚；
+// 返回影响的行数和变更后的Greeter
+func (dm greeterDomain) mutate(ctx context.Context, id int32, action greeter.GreeterAction,
+	write func(ctx context.Context, before model.Greeter) (int64, error), events func(before, after model.Greeter) []*greeter.GreeterEvent) (int64, model.Greeter, error) {
+	var affected int64
+	var after model.Greeter
+	err := dm.repo.Transaction(ctx, func(ctx context.Context) error {
+		before, err := dm.repo.FindGreeterForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		affected, err = write(ctx, before)
+		if err != nil || affected <= 0 {
+			return err
+		}
+		after, err = dm.repo.FindGreeterById(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := dm.audit(ctx, action, id, before, after); err != nil {
+			return err
+		}
+		return dm.publish(ctx, id, events(before, after)...)
+	})
+	if err != nil {
+		return 0, model.Greeter{}, err
+	}
+	return affected, after, nil
+}
+
+// greeterUpdated 修改字段的事件，修改status时还包括状态变更的事件
+func greeterUpdated(before, after model.Greeter, paths []string) []*greeter.GreeterEvent {
+	events := []*greeter.GreeterEvent{{Payload: &greeter.GreeterEvent_Updated{Updated: &greeter.GreeterUpdated{
+		Before: GreeterModel2Dto(before),
+		After:  GreeterModel2Dto(after),
+		Paths:  paths,
+	}}}}
+	for _, path := range paths {
+		if path == "status" {
+			events = append(events, greeterStatusChanged(before, after)...)
+		}
+	}
+	return events
+}
+
+func greeterStatusChanged(before, after model.Greeter) []*greeter.GreeterEvent {
+	return []*greeter.GreeterEvent{{Payload: &greeter.GreeterEvent_StatusChanged{StatusChanged: &greeter.GreeterStatusChanged{
+		From:    greeter.GreeterStatus(before.Status),
+		To:      greeter.GreeterStatus(after.Status),
+		Version: after.Version,
+	}}}}
+}
+
+func greeterCounterChanged(column string, delta, version int32) *greeter.GreeterEvent {
+	return &greeter.GreeterEvent{Payload: &greeter.GreeterEvent_CounterChanged{CounterChanged: &greeter.GreeterCounterChanged{
+		Column:   column,
+		Delta:    delta,
+		Deferred: version == 0,
+		Version:  version,
+	}}}
+}
@@ -9,7 +9,7 @@ package service
 
 import (
 	"context"
-	"math"
+	"time"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/pkg/errors"
@@ -19,81 +19,280 @@ import (
 	"github.com/imind-lab/greeter/domain/greeter/repository"
 	"github.com/imind-lab/greeter/domain/greeter/repository/model"
 	"github.com/imind-lab/greeter/domain/greeter/repository/persistence"
-	"github.com/imind-lab/micro/dao"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+	"github.com/imind-lab/micro/util"
 )
 
 type GreeterDomain interface {
 	CreateGreeter(ctx context.Context, dto *greeter.Greeter) error
 
 	GetGreeterById(ctx context.Context, id int32) (*greeter.Greeter, error)
-	GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) (*greeter.GreeterList, error)
+	GetGreetersByIds(ctx context.Context, ids []int32) ([]*greeter.Greeter, []int32, error)
+	GetGreeterList(ctx context.Context, query repository.GreeterListQuery) (*greeter.GreeterList, error)
 
-	UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error)
-	UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error)
+	UpdateGreeter(ctx context.Context, dto *greeter.Greeter, paths []string) (int64, error)
+	UpdateGreeterStatus(ctx context.Context, id int32, status greeter.GreeterStatus, version int32) (int64, error)
+	UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error)
+	FlushGreeterCounters(ctx context.Context) (int, error)
+	VerifyGreeterCache(ctx context.Context, batchSize int, repair bool) (repository.GreeterCacheReport, error)
+	WarmGreeterCache(ctx context.Context) error
 
-	DeleteGreeterById(ctx context.Context, id int32) (int64, error)
+	RelayGreeterOutbox(ctx context.Context) (int, error)
+	PurgeGreeterOutbox(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	DeleteGreeterById(ctx context.Context, id, version int32) (int64, error)
+	RestoreGreeter(ctx context.Context, id, version int32) (int64, error)
+	PurgeDeletedGreeters(ctx context.Context, olderThan time.Duration) (int64, error)
+
+	ListGreeterHistory(ctx context.Context, id int32, pageToken string, pageSize int32) ([]*greeter.GreeterHistory, string, error)
+	SearchGreeters(ctx context.Context, query, pageToken string, pageSize int32) ([]*greeter.GreeterSearchResult, int32, string, error)
+}
+
+// ErrVersionConflict 写操作携带的版本号与当前版本号不一致
+var ErrVersionConflict = repository.ErrVersionConflict
+
+// ErrInvalidPageToken page_token无法解析、签名校验失败或与查询条件不一致
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrFieldNotUpdatable FieldMask中包含不允许通过UpdateGreeter修改的字段
+var ErrFieldNotUpdatable = errors.New("field is not updatable")
+
+// greeterUpdatableColumns UpdateGreeter允许更新的字段（FieldMask路径）与数据库列的对应关系；
+// view_num只能通过UpdateGreeterCount累加，直接覆盖会与计数服务中暂存的增量冲突
+var greeterUpdatableColumns = map[string]string{
+	"name":   "name",
+	"status": "status",
 }
 
 type greeterDomain struct {
-	dao.Cache
+	*greeterCache
 
-	repo repository.GreeterRepository
+	repo        repository.GreeterRepository
+	auditRepo   repository.GreeterAuditRepository
+	searchIndex repository.GreeterSearchIndex
+	counterRepo repository.GreeterCounterRepository
+	outboxRepo  repository.GreeterOutboxRepository
+	// cacheVerifier、cacheWarmer 只用于缓存的检查和预热
+	cacheVerifier repository.GreeterCacheVerifier
+	cacheWarmer   repository.GreeterCacheWarmer
 }
 
 func NewGreeterDomain() GreeterDomain {
+	if utilx.MemoryStorage() {
+		return newMemoryGreeterDomain()
+	}
 	repo := persistence.NewGreeterRepository()
 	dm := greeterDomain{
-		Cache: dao.NewCache(),
-		repo:  repo}
+		greeterCache:  newGreeterCache(),
+		repo:          repo,
+		auditRepo:     persistence.NewGreeterAuditRepository(),
+		searchIndex:   persistence.NewGreeterSearchIndex(),
+		counterRepo:   persistence.NewGreeterCounterRepository(),
+		outboxRepo:    persistence.NewGreeterOutboxRepository(),
+		cacheVerifier: persistence.NewGreeterCacheVerifier(),
+		cacheWarmer:   persistence.NewGreeterCacheWarmer()}
 	return dm
 }
 
 func (dm greeterDomain) CreateGreeter(ctx context.Context, dto *greeter.Greeter) error {
+	if err := CheckInitialStatus(dto.Status); err != nil {
+		return errors.WithMessage(err, "greeterDomain.CreateGreeter")
+	}
 	m := GreeterDto2Model(dto)
-	_, err := dm.repo.CreateGreeter(ctx, m)
-	return err
+	m.StatusOperator = utilx.Actor(ctx)
+	err := dm.repo.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		m, err = dm.repo.CreateGreeter(ctx, m)
+		if err != nil {
+			return err
+		}
+		if err := dm.audit(ctx, greeter.GreeterAction_GREETER_ACTION_CREATE, m.Id, model.Greeter{}, m); err != nil {
+			return err
+		}
+		return dm.publish(ctx, m.Id, &greeter.GreeterEvent{Payload: &greeter.GreeterEvent_Created{Created: &greeter.GreeterCreated{
+			Greeter: GreeterModel2Dto(m),
+		}}})
+	})
+	if err != nil {
+		return err
+	}
+	dm.indexGreeter(ctx, m)
+	return nil
 }
 
 func (dm greeterDomain) GetGreeterById(ctx context.Context, id int32) (*greeter.Greeter, error) {
 	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterDomain"), zap.String("func", "GetGreeterById"))
 
 	logger.Info("greeterDomain.GetGreeterById invoke")
-	m, err := dm.repo.GetGreeterById(ctx, id)
-	return GreeterModel2Dto(m), errors.WithMessage(err, "greeterDomain.GetGreeterById")
+	m, ok := dm.getGreeter(id)
+	if !ok {
+		var err error
+		m, err = dm.repo.GetGreeterById(ctx, id)
+		if err != nil {
+			return GreeterModel2Dto(m), errors.WithMessage(err, "greeterDomain.GetGreeterById")
+		}
+		dm.setGreeter(m)
+	}
+	return GreeterModel2Dto(dm.withPendingCounters(ctx, []model.Greeter{m})[0]), nil
 }
 
-func (dm greeterDomain) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) (*greeter.GreeterList, error) {
-	list, total, err := dm.repo.GetGreeterList(ctx, status, lastId, pageSize, page)
+func (dm greeterDomain) GetGreetersByIds(ctx context.Context, ids []int32) ([]*greeter.Greeter, []int32, error) {
+	list, notFound, err := dm.getGreetersByIds(ctx, ids)
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.WithMessage(err, "greeterDomain.GetGreetersByIds")
 	}
-	greeters := GreeterMap(list, GreeterModel2Dto)
+	return GreeterMap(dm.withPendingCounters(ctx, list), GreeterModel2Dto), notFound, nil
+}
 
-	var totalPage int32 = 0
-	if total == 0 {
-		page = 1
-	} else {
-		totalPage = int32(math.Ceil(float64(total) / float64(pageSize)))
+// getGreetersByIds 先读取进程内缓存，其余的id从仓库读取，结果和notFound保持ids的顺序
+func (dm greeterDomain) getGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, []int32, error) {
+	if !dm.enabled() {
+		return dm.repo.GetGreetersByIds(ctx, ids)
 	}
-	greeterList := &greeter.GreeterList{}
-	greeterList.Datalist = greeters
-	greeterList.Total = int32(total)
-	greeterList.TotalPage = totalPage
-	greeterList.CurPage = page
 
-	return greeterList, nil
+	found := make(map[int32]model.Greeter, len(ids))
+	var misses []int32
+	for _, id := range ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		if m, ok := dm.getGreeter(id); ok {
+			found[id] = m
+		} else {
+			misses = append(misses, id)
+		}
+	}
+	if len(misses) > 0 {
+		list, _, err := dm.repo.GetGreetersByIds(ctx, misses)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, m := range list {
+			found[m.Id] = m
+			dm.setGreeter(m)
+		}
+	}
+
+	list := make([]model.Greeter, 0, len(ids))
+	var notFound []int32
+	for _, id := range ids {
+		if m, ok := found[id]; ok {
+			list = append(list, m)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return list, notFound, nil
 }
 
-func (dm greeterDomain) UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error) {
-	return dm.repo.UpdateGreeterStatus(ctx, id, status)
+func (dm greeterDomain) UpdateGreeter(ctx context.Context, dto *greeter.Greeter, paths []string) (int64, error) {
+	m := GreeterDto2Model(dto)
+	columns := make([]string, 0, len(paths))
+	for _, path := range paths {
+		column, ok := greeterUpdatableColumns[path]
+		if !ok {
+			return 0, errors.Wrapf(ErrFieldNotUpdatable, "greeterDomain.UpdateGreeter %s", path)
+		}
+		columns = append(columns, column)
+	}
+
+	action := greeter.GreeterAction_GREETER_ACTION_UPDATE
+	for _, path := range paths {
+		if path == "status" {
+			m.StatusOperator = utilx.Actor(ctx)
+			m.StatusDatetime = time.Now().Format(util.DateTimeFmt)
+			columns = append(columns, "status_operator", "status_datetime")
+			action = greeter.GreeterAction_GREETER_ACTION_STATUS
+		}
+	}
+
+	affected, after, err := dm.mutate(ctx, dto.Id, action, func(ctx context.Context, before model.Greeter) (int64, error) {
+		if before.IsEmpty() {
+			return 0, nil
+		}
+		if action == greeter.GreeterAction_GREETER_ACTION_STATUS {
+			var err error
+			m.Version, err = checkStatusTransition(before, dto.Status, dto.Version)
+			if err != nil {
+				return 0, errors.WithMessage(err, "greeterDomain.UpdateGreeter")
+			}
+		}
+		return dm.repo.UpdateGreeter(ctx, m, columns)
+	}, func(before, after model.Greeter) []*greeter.GreeterEvent {
+		return greeterUpdated(before, after, paths)
+	})
+	if err == nil && affected > 0 {
+		dm.evictGreeters(ctx, dto.Id)
+		dm.indexGreeter(ctx, after)
+	}
+	return affected, err
 }
 
-func (dm greeterDomain) UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error) {
-	return dm.repo.UpdateGreeterCount(ctx, id, num, column)
+// UpdateGreeterStatus 按状态机变更状态，并记录操作人和变更时间
+func (dm greeterDomain) UpdateGreeterStatus(ctx context.Context, id int32, status greeter.GreeterStatus, version int32) (int64, error) {
+	affected, after, err := dm.mutate(ctx, id, greeter.GreeterAction_GREETER_ACTION_STATUS, func(ctx context.Context, before model.Greeter) (int64, error) {
+		if before.IsEmpty() {
+			return 0, nil
+		}
+		version, err := checkStatusTransition(before, status, version)
+		if err != nil {
+			return 0, errors.WithMessage(err, "greeterDomain.UpdateGreeterStatus")
+		}
+		return dm.repo.UpdateGreeterStatus(ctx, id, int32(status), version, utilx.Actor(ctx))
+	}, greeterStatusChanged)
+	if err == nil && affected > 0 {
+		dm.evictGreeters(ctx, id)
+		dm.indexGreeter(ctx, after)
+	}
+	return affected, err
 }
 
-func (dm greeterDomain) DeleteGreeterById(ctx context.Context, id int32) (int64, error) {
-	return dm.repo.DeleteGreeterById(ctx, id)
+// checkStatusTransition 校验current的状态能否变更为to，返回写入时用于乐观锁的版本号，
+// 保证状态在校验之后没有被其他请求修改
+func checkStatusTransition(current model.Greeter, to greeter.GreeterStatus, version int32) (int32, error) {
+	if version > 0 && version != current.Version {
+		return 0, errors.Wrapf(ErrVersionConflict, "greeter %d expect version %d, actual %d", current.Id, version, current.Version)
+	}
+	if err := CheckStatusTransition(greeter.GreeterStatus(current.Status), to); err != nil {
+		return 0, errors.WithMessagef(err, "greeter %d", current.Id)
+	}
+	return current.Version, nil
+}
+
+func (dm greeterDomain) DeleteGreeterById(ctx context.Context, id, version int32) (int64, error) {
+	affected, _, err := dm.mutate(ctx, id, greeter.GreeterAction_GREETER_ACTION_DELETE, func(ctx context.Context, before model.Greeter) (int64, error) {
+		if before.IsEmpty() {
+			return 0, nil
+		}
+		return dm.repo.DeleteGreeterById(ctx, id, version)
+	}, func(before, _ model.Greeter) []*greeter.GreeterEvent {
+		return []*greeter.GreeterEvent{{Payload: &greeter.GreeterEvent_Deleted{Deleted: &greeter.GreeterDeleted{
+			Greeter: GreeterModel2Dto(before),
+		}}}}
+	})
+	if err == nil && affected > 0 {
+		dm.evictGreeters(ctx, id)
+		dm.unindexGreeter(ctx, id)
+	}
+	return affected, err
+}
+
+// RestoreGreeter 恢复已软删除的Greeter，已删除的记录读取为空对象，因此变更记录中before为空
+func (dm greeterDomain) RestoreGreeter(ctx context.Context, id, version int32) (int64, error) {
+	affected, after, err := dm.mutate(ctx, id, greeter.GreeterAction_GREETER_ACTION_RESTORE, func(ctx context.Context, _ model.Greeter) (int64, error) {
+		return dm.repo.RestoreGreeter(ctx, id, version)
+	}, func(before, after model.Greeter) []*greeter.GreeterEvent {
+		return greeterUpdated(before, after, []string{"delete_datetime"})
+	})
+	if err == nil && affected > 0 {
+		dm.indexGreeter(ctx, after)
+	}
+	return affected, err
+}
+
+// PurgeDeletedGreeters 物理删除软删除超过olderThan的Greeter，软删除时已发布GreeterDeleted，不再发布事件
+func (dm greeterDomain) PurgeDeletedGreeters(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return dm.repo.PurgeDeletedGreeters(ctx, time.Now().Add(-olderThan))
 }
 
 func GreeterMap(pos []model.Greeter, fn func(model.Greeter) *greeter.Greeter) []*greeter.Greeter {
@@ -113,10 +312,16 @@ func GreeterModel2Dto(po model.Greeter) *greeter.Greeter {
 	dto.Id = po.Id
 	dto.Name = po.Name
 	dto.ViewNum = po.ViewNum
-	dto.Status = po.Status
+	dto.Status = greeter.GreeterStatus(po.Status)
 	dto.CreateTime = po.CreateTime
 	dto.UpdateDatetime = po.UpdateDatetime
 	dto.CreateDatetime = po.CreateDatetime
+	dto.Version = po.Version
+	dto.StatusOperator = po.StatusOperator
+	dto.StatusDatetime = po.StatusDatetime
+	if po.DeletedAt.Valid {
+		dto.DeleteDatetime = po.DeletedAt.Time.Format(util.DateTimeFmt)
+	}
 
 	return dto
 }
@@ -130,10 +335,13 @@ func GreeterDto2Model(dto *greeter.Greeter) model.Greeter {
 	po.Id = dto.Id
 	po.Name = dto.Name
 	po.ViewNum = dto.ViewNum
-	po.Status = dto.Status
+	po.Status = int32(dto.Status)
 	po.CreateTime = dto.CreateTime
 	po.UpdateDatetime = dto.UpdateDatetime
 	po.CreateDatetime = dto.CreateDatetime
+	po.Version = dto.Version
+	po.StatusOperator = dto.StatusOperator
+	po.StatusDatetime = dto.StatusDatetime
 
 	return po
 }
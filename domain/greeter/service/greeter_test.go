@@ -2,13 +2,29 @@ package service
 
 import (
 	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/memory"
 	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	brokerx "github.com/imind-lab/greeter/pkg/broker"
+	"github.com/imind-lab/greeter/pkg/cache"
+	"github.com/imind-lab/greeter/pkg/constant"
+	utilx "github.com/imind-lab/greeter/pkg/util"
 	"github.com/imind-lab/greeter/test/mock"
+	"github.com/imind-lab/micro/broker"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 	"testing"
+	"time"
 )
 
 type Suite struct {
@@ -22,8 +38,13 @@ func (s *Suite) SetupSuite() {
 	s.ctl = gomock.NewController(s.T())
 	s.repoMock = mock.NewMockGreeterRepository(s.ctl)
 	s.dm = greeterDomain{
-		repo: s.repoMock,
+		repo:        s.repoMock,
+		auditRepo:   memory.NewGreeterAuditRepository(),
+		searchIndex: memory.NewGreeterSearchIndex(),
+		counterRepo: memory.NewGreeterCounterRepository(),
+		outboxRepo:  memory.NewGreeterOutboxRepository(),
 	}
+	expectTransaction(s.repoMock)
 }
 
 func (s *Suite) AfterTest(_, _ string) {
@@ -91,10 +112,669 @@ func (s *Suite) TestGreeterDomain_GetGreeterList() {
 	ctx := context.Background()
 	for _, t := range tests {
 		s.Run(t.name, func() {
-			s.repoMock.EXPECT().GetGreeterList(ctx, t.status, t.lastId, t.pageSize, t.page).Return(t.data, t.cnt, nil)
-			actual, err := s.dm.GetGreeterList(ctx, t.status, t.lastId, t.pageSize, t.page)
+			// 第一页多取一条用于判断是否还有下一页
+			s.repoMock.EXPECT().GetGreeterList(ctx, t.status, t.lastId, t.pageSize+1, t.page, gomock.Any()).Return(t.data, t.cnt, nil, nil)
+			actual, err := s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: t.status, LastId: t.lastId, PageSize: t.pageSize, Page: t.page})
 			require.NoError(s.T(), err)
 			require.EqualValues(s.T(), t.expected, actual)
 		})
 	}
 }
+
+func (s *Suite) TestGreeterDomain_GetGreeterListPageToken() {
+	ctx := context.Background()
+	page1 := []model.Greeter{{Id: 500, Status: 1}, {Id: 400, Status: 1}, {Id: 300, Status: 1}}
+	page2 := []model.Greeter{{Id: 200, Status: 1}}
+
+	s.repoMock.EXPECT().GetGreeterList(ctx, int32(1), int32(0), int32(3), int32(1), gomock.Any()).Return(page1, 4, nil, nil)
+	actual, err := s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), actual.Datalist, 2)
+	require.NotEmpty(s.T(), actual.NextPageToken)
+
+	// page_token中记录了上一页最后一个id，忽略已废弃的page参数
+	s.repoMock.EXPECT().GetGreeterList(ctx, int32(1), int32(400), int32(3), int32(1), gomock.Any()).Return(page2, 4, nil, nil)
+	next, err := s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2, PageToken: actual.NextPageToken, Page: 5})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), next.Datalist, 1)
+	require.Empty(s.T(), next.NextPageToken)
+
+	_, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 2, PageSize: 2, PageToken: actual.NextPageToken})
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+
+	// 只有一个状态的statuses与status使用同一个缓存，page_token中按实际查询的状态比较
+	_, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Statuses: []int32{2}, PageSize: 2, PageToken: actual.NextPageToken})
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+	_, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, Statuses: []int32{2}, PageSize: 2, PageToken: actual.NextPageToken})
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+
+	s.repoMock.EXPECT().GetGreeterList(ctx, int32(1), int32(400), int32(3), int32(1), gomock.Any()).Return(page2, 4, nil, nil)
+	next, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Statuses: []int32{1}, PageSize: 2, PageToken: actual.NextPageToken})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), next.Datalist, 1)
+
+	_, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2, PageToken: actual.NextPageToken + "x"})
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+}
+
+// TestGreeterDomain_GetGreeterListIncomplete 读取失败的Greeter在failed中返回，下一页从页中最后的位置开始
+func (s *Suite) TestGreeterDomain_GetGreeterListIncomplete() {
+	ctx := context.Background()
+	list := []model.Greeter{{Id: 500, Status: 1}}
+	failed := []repository.GreeterFailure{{Id: 400, Err: context.DeadlineExceeded}, {Id: 300, Err: errors.New("redis: connection refused")}}
+
+	s.repoMock.EXPECT().GetGreeterList(ctx, int32(1), int32(0), int32(3), int32(1), gomock.Any()).Return(list, 4, failed, nil)
+	actual, err := s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2})
+	require.NoError(s.T(), err)
+	require.Len(s.T(), actual.Datalist, 1)
+	require.True(s.T(), actual.Incomplete)
+	require.Equal(s.T(), []*greeter.GreeterFailure{{Id: 400, Reason: greeter.GreeterFailureReason_GREETER_FAILURE_REASON_TIMEOUT}}, actual.Failed)
+
+	s.repoMock.EXPECT().GetGreeterList(ctx, int32(1), int32(400), int32(3), int32(1), gomock.Any()).
+		Return([]model.Greeter{{Id: 200, Status: 1}}, 4, []repository.GreeterFailure{{Id: 300, Err: errors.New("redis: connection refused")}}, nil)
+	next, err := s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2, PageToken: actual.NextPageToken})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []*greeter.GreeterFailure{{Id: 300, Reason: greeter.GreeterFailureReason_GREETER_FAILURE_REASON_ERROR}}, next.Failed)
+	require.Empty(s.T(), next.NextPageToken)
+}
+
+func (s *Suite) TestGreeterDomain_GetGreeterListFilter() {
+	ctx := context.Background()
+	query := repository.GreeterListQuery{Statuses: []int32{1, 2}, MinViewNum: 5, SortBy: repository.GreeterSortByViewNum, PageSize: 2}
+	filter := repository.GreeterListFilter{Statuses: []int32{1, 2}, MinViewNum: 5, SortBy: repository.GreeterSortByViewNum}
+	page1 := []model.Greeter{{Id: 300, ViewNum: 9, Status: 1}, {Id: 500, ViewNum: 7, Status: 2}, {Id: 400, ViewNum: 7, Status: 1}}
+	page2 := []model.Greeter{{Id: 400, ViewNum: 7, Status: 1}}
+
+	// 无法使用缓存的组合直接查询数据库
+	s.repoMock.EXPECT().FindGreeterList(ctx, filter, (*repository.GreeterListAfter)(nil), int32(3), int32(1)).Return(page1, 3, nil)
+	actual, err := s.dm.GetGreeterList(ctx, query)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), actual.Datalist, 2)
+	require.NotEmpty(s.T(), actual.NextPageToken)
+
+	// page_token中记录了上一页最后一条记录的排序值和id
+	query.PageToken = actual.NextPageToken
+	s.repoMock.EXPECT().FindGreeterList(ctx, filter, &repository.GreeterListAfter{Value: 7, Id: 500}, int32(3), int32(1)).Return(page2, 3, nil)
+	next, err := s.dm.GetGreeterList(ctx, query)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), next.Datalist, 1)
+	require.Empty(s.T(), next.NextPageToken)
+
+	// 过滤或排序条件改变后page_token无效
+	query.Ascending = true
+	_, err = s.dm.GetGreeterList(ctx, query)
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+
+	_, err = s.dm.GetGreeterList(ctx, repository.GreeterListQuery{Status: 1, PageSize: 2, PageToken: actual.NextPageToken})
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+}
+
+func (s *Suite) TestGreeterDomain_UpdateGreeter() {
+	ctx := context.Background()
+	dto := &greeter.Greeter{Id: 100, Name: "koofox@imind.tech", ViewNum: 3}
+
+	s.repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(model.Greeter{Id: 100, Name: "koofox", Version: 1}, nil)
+	s.repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(model.Greeter{Id: 100, Name: "koofox@imind.tech", Version: 2}, nil)
+	s.repoMock.EXPECT().UpdateGreeter(ctx, GreeterDto2Model(dto), []string{"name"}).Return(int64(1), nil)
+	affected, err := s.dm.UpdateGreeter(ctx, dto, []string{"name"})
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+
+	_, err = s.dm.UpdateGreeter(ctx, dto, []string{"create_time"})
+	require.True(s.T(), errors.Is(err, ErrFieldNotUpdatable))
+
+	// view_num只能通过UpdateGreeterCount修改，避免覆盖计数服务中暂存的增量
+	_, err = s.dm.UpdateGreeter(ctx, dto, []string{"name", "view_num"})
+	require.True(s.T(), errors.Is(err, ErrFieldNotUpdatable))
+}
+
+// TestGreeterDomain_UpdateGreeterAuditFailed 写入变更记录失败时变更回滚，也不写入事件
+func (s *Suite) TestGreeterDomain_UpdateGreeterAuditFailed() {
+	ctx := context.Background()
+	dm := s.dm.(greeterDomain)
+	dm.auditRepo = failingAudit{dm.auditRepo}
+	dto := &greeter.Greeter{Id: 100, Name: "koofox@imind.tech"}
+
+	pending, err := dm.outboxRepo.CountPendingGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	s.repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(model.Greeter{Id: 100, Name: "koofox", Version: 1}, nil)
+	s.repoMock.EXPECT().UpdateGreeter(ctx, GreeterDto2Model(dto), []string{"name"}).Return(int64(1), nil)
+	s.repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(model.Greeter{Id: 100, Name: "koofox@imind.tech", Version: 2}, nil)
+	affected, err := dm.UpdateGreeter(ctx, dto, []string{"name"})
+	require.Error(s.T(), err)
+	require.Zero(s.T(), affected)
+
+	after, err := dm.outboxRepo.CountPendingGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), pending, after)
+}
+
+func (s *Suite) TestGreeterDomain_UpdateGreeterStatus() {
+	tests := []struct {
+		name     string
+		current  model.Greeter
+		status   greeter.GreeterStatus
+		version  int32
+		update   bool
+		expected error
+	}{
+		{"pending-active", model.Greeter{Id: 100, Status: 0, Version: 3}, greeter.GreeterStatus_GREETER_STATUS_ACTIVE, 0, true, nil},
+		{"deleted-pending", model.Greeter{Id: 100, Status: 3, Version: 3}, greeter.GreeterStatus_GREETER_STATUS_PENDING, 3, true, nil},
+		{"deleted-active", model.Greeter{Id: 100, Status: 3, Version: 3}, greeter.GreeterStatus_GREETER_STATUS_ACTIVE, 0, false, ErrIllegalStatusTransition},
+		{"active-active", model.Greeter{Id: 100, Status: 1, Version: 3}, greeter.GreeterStatus_GREETER_STATUS_ACTIVE, 0, false, ErrIllegalStatusTransition},
+		{"version-conflict", model.Greeter{Id: 100, Status: 1, Version: 4}, greeter.GreeterStatus_GREETER_STATUS_DISABLED, 3, false, ErrVersionConflict},
+		{"not-found", model.Greeter{}, greeter.GreeterStatus_GREETER_STATUS_ACTIVE, 0, false, nil},
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor", "koofox"))
+	for _, t := range tests {
+		s.Run(t.name, func() {
+			s.repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(t.current, nil)
+			if t.update {
+				s.repoMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), int32(t.status), t.current.Version, "koofox").Return(int64(1), nil)
+				s.repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(t.current, nil)
+			}
+
+			affected, err := s.dm.UpdateGreeterStatus(ctx, 100, t.status, t.version)
+			if t.expected != nil {
+				require.True(s.T(), errors.Is(err, t.expected), err)
+				return
+			}
+			require.NoError(s.T(), err)
+			if t.update {
+				require.EqualValues(s.T(), 1, affected)
+			} else {
+				require.EqualValues(s.T(), 0, affected)
+			}
+		})
+	}
+}
+
+func (s *Suite) TestGreeterDomain_ListGreeterHistory() {
+	ctl := gomock.NewController(s.T())
+	defer ctl.Finish()
+	repoMock := mock.NewMockGreeterRepository(ctl)
+	dm := greeterDomain{
+		repo:        repoMock,
+		auditRepo:   memory.NewGreeterAuditRepository(),
+		searchIndex: memory.NewGreeterSearchIndex(),
+		counterRepo: memory.NewGreeterCounterRepository(),
+		outboxRepo:  memory.NewGreeterOutboxRepository(),
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor", "koofox", "x-request-id", "req-1"))
+	created := model.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 0, Version: 1}
+	active := model.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 1, Version: 2}
+	disabled := model.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 2, Version: 3}
+
+	expectTransaction(repoMock)
+	repoMock.EXPECT().CreateGreeter(ctx, gomock.Any()).Return(created, nil)
+	require.NoError(s.T(), dm.CreateGreeter(ctx, &greeter.Greeter{Name: "koofox@imind.tech"}))
+
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(created, nil),
+		repoMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), int32(1), int32(1), "koofox").Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(active, nil),
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(active, nil),
+		repoMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), int32(2), int32(2), "koofox").Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(disabled, nil),
+	)
+	_, err := dm.UpdateGreeterStatus(ctx, 100, greeter.GreeterStatus_GREETER_STATUS_ACTIVE, 0)
+	require.NoError(s.T(), err)
+	_, err = dm.UpdateGreeterStatus(ctx, 100, greeter.GreeterStatus_GREETER_STATUS_DISABLED, 0)
+	require.NoError(s.T(), err)
+
+	histories, pageToken, err := dm.ListGreeterHistory(ctx, 100, "", 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), histories, 2)
+	require.NotEmpty(s.T(), pageToken)
+	require.Equal(s.T(), greeter.GreeterAction_GREETER_ACTION_STATUS, histories[0].Action)
+	require.Equal(s.T(), "koofox", histories[0].Actor)
+	require.Equal(s.T(), "req-1", histories[0].RequestId)
+	require.Equal(s.T(), greeter.GreeterStatus_GREETER_STATUS_ACTIVE, histories[0].Before.Status)
+	require.Equal(s.T(), greeter.GreeterStatus_GREETER_STATUS_DISABLED, histories[0].After.Status)
+
+	histories, pageToken, err = dm.ListGreeterHistory(ctx, 100, pageToken, 2)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), histories, 1)
+	require.Empty(s.T(), pageToken)
+	require.Equal(s.T(), greeter.GreeterAction_GREETER_ACTION_CREATE, histories[0].Action)
+	require.Nil(s.T(), histories[0].Before)
+	require.Equal(s.T(), int32(100), histories[0].After.Id)
+
+	_, _, err = dm.ListGreeterHistory(ctx, 100, "not-a-token", 2)
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+}
+
+func (s *Suite) TestGreeterDomain_SearchGreeters() {
+	ctl := gomock.NewController(s.T())
+	defer ctl.Finish()
+	repoMock := mock.NewMockGreeterRepository(ctl)
+	dm := greeterDomain{
+		repo:        repoMock,
+		auditRepo:   memory.NewGreeterAuditRepository(),
+		searchIndex: memory.NewGreeterSearchIndex(),
+		counterRepo: memory.NewGreeterCounterRepository(),
+		outboxRepo:  memory.NewGreeterOutboxRepository(),
+	}
+
+	ctx := context.Background()
+	koofox := model.Greeter{Id: 100, Name: "koofox@imind.tech", Version: 1}
+	songli := model.Greeter{Id: 200, Name: "Imind<songli>@example.com", Version: 1}
+	renamed := model.Greeter{Id: 100, Name: "koofox@example.com", Version: 2}
+
+	// 创建和更新后同步索引
+	expectTransaction(repoMock)
+	repoMock.EXPECT().CreateGreeter(ctx, gomock.Any()).Return(koofox, nil)
+	require.NoError(s.T(), dm.CreateGreeter(ctx, &greeter.Greeter{Name: koofox.Name}))
+	repoMock.EXPECT().CreateGreeter(ctx, gomock.Any()).Return(songli, nil)
+	require.NoError(s.T(), dm.CreateGreeter(ctx, &greeter.Greeter{Name: songli.Name}))
+
+	repoMock.EXPECT().GetGreetersByIds(ctx, []int32{200}).Return([]model.Greeter{songli}, nil, nil)
+	results, total, pageToken, err := dm.SearchGreeters(ctx, "imind", "", 1)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 2, total)
+	require.NotEmpty(s.T(), pageToken)
+	require.Len(s.T(), results, 1)
+	require.Equal(s.T(), float64(repository.GreeterSearchScorePrefix), results[0].Score)
+	require.Equal(s.T(), "<em>Imind</em>&lt;songli&gt;@example.com", results[0].Highlight)
+
+	repoMock.EXPECT().GetGreetersByIds(ctx, []int32{100}).Return([]model.Greeter{koofox}, nil, nil)
+	results, _, pageToken, err = dm.SearchGreeters(ctx, "imind", pageToken, 1)
+	require.NoError(s.T(), err)
+	require.Empty(s.T(), pageToken)
+	require.Equal(s.T(), "koofox@<em>imind</em>.tech", results[0].Highlight)
+
+	_, _, _, err = dm.SearchGreeters(ctx, "koofox", pageToken+"x", 1)
+	require.True(s.T(), errors.Is(err, ErrInvalidPageToken))
+
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(koofox, nil),
+		repoMock.EXPECT().UpdateGreeter(ctx, gomock.Any(), []string{"name"}).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(renamed, nil),
+	)
+	_, err = dm.UpdateGreeter(ctx, &greeter.Greeter{Id: 100, Name: renamed.Name}, []string{"name"})
+	require.NoError(s.T(), err)
+
+	repoMock.EXPECT().GetGreetersByIds(ctx, []int32{200}).Return([]model.Greeter{songli}, nil, nil)
+	results, total, _, err = dm.SearchGreeters(ctx, "imind", "", 10)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, total)
+	require.Len(s.T(), results, 1)
+
+	// 删除后从索引中删除
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(200)).Return(songli, nil),
+		repoMock.EXPECT().DeleteGreeterById(ctx, int32(200), int32(0)).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(200)).Return(model.Greeter{}, nil),
+	)
+	_, err = dm.DeleteGreeterById(ctx, 200, 0)
+	require.NoError(s.T(), err)
+
+	repoMock.EXPECT().GetGreetersByIds(ctx, []int32{}).Return(nil, nil, nil)
+	results, total, _, err = dm.SearchGreeters(ctx, "imind", "", 10)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), total)
+	require.Empty(s.T(), results)
+}
+
+func (s *Suite) TestGreeterDomain_UpdateGreeterCount() {
+	ctl := gomock.NewController(s.T())
+	defer ctl.Finish()
+	repoMock := mock.NewMockGreeterRepository(ctl)
+	dm := greeterDomain{
+		repo:        repoMock,
+		auditRepo:   memory.NewGreeterAuditRepository(),
+		searchIndex: memory.NewGreeterSearchIndex(),
+		counterRepo: memory.NewGreeterCounterRepository(),
+		outboxRepo:  memory.NewGreeterOutboxRepository(),
+	}
+
+	ctx := context.Background()
+	persisted := model.Greeter{Id: 100, Name: "koofox", ViewNum: 10, Version: 1}
+
+	// 不在允许列表中的字段不会访问仓库
+	_, err := dm.UpdateGreeterCount(ctx, 100, 1, 0, "version = 0, name")
+	require.True(s.T(), errors.Is(err, ErrCounterNotAllowed))
+
+	// 不存在的Greeter不累加
+	repoMock.EXPECT().GetGreeterById(ctx, int32(200)).Return(model.Greeter{}, nil)
+	affected, err := dm.UpdateGreeterCount(ctx, 200, 1, 0, "view_num")
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 0, affected)
+
+	// 未指定版本号时增量暂存，读取时加上暂存的增量
+	repoMock.EXPECT().GetGreeterById(ctx, int32(100)).Return(persisted, nil).Times(3)
+	for _, num := range []int32{2, 3} {
+		affected, err = dm.UpdateGreeterCount(ctx, 100, num, 0, "view_num")
+		require.NoError(s.T(), err)
+		require.EqualValues(s.T(), 1, affected)
+	}
+	dto, err := dm.GetGreeterById(ctx, 100)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 15, dto.ViewNum)
+
+	// 写入失败时保留增量，下次使用新的批次写入，成功后不再有暂存的增量
+	delta := repository.GreeterCounterDelta{"view_num": 5}
+	repoMock.EXPECT().AddGreeterCounters(ctx, map[int32]repository.GreeterCounterBatch{100: {Id: "1", Delta: delta}}).Return(errors.New("deadlock"))
+	_, err = dm.FlushGreeterCounters(ctx)
+	require.Error(s.T(), err)
+	repoMock.EXPECT().AddGreeterCounters(ctx, map[int32]repository.GreeterCounterBatch{100: {Id: "2", Delta: delta}}).Return(nil)
+	n, err := dm.FlushGreeterCounters(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, n)
+	n, err = dm.FlushGreeterCounters(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 0, n)
+
+	// 指定版本号时直接写入数据库
+	updated := persisted
+	updated.ViewNum, updated.Version = 11, 2
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(persisted, nil),
+		repoMock.EXPECT().UpdateGreeterCount(ctx, int32(100), int32(1), int32(1), "view_num").Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(updated, nil),
+	)
+	expectTransaction(repoMock)
+	affected, err = dm.UpdateGreeterCount(ctx, 100, 1, 1, "view_num")
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 1, affected)
+
+	// 两次暂存和一次直接写入都写入了事件
+	pending, err := dm.outboxRepo.CountPendingGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.EqualValues(s.T(), 3, pending)
+
+	// 写入事件失败时回滚
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(updated, nil),
+		repoMock.EXPECT().UpdateGreeterCount(ctx, int32(100), int32(1), int32(2), "view_num").Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(updated, nil),
+	)
+	dm.outboxRepo = failingOutbox{dm.outboxRepo}
+	_, err = dm.UpdateGreeterCount(ctx, 100, 1, 2, "view_num")
+	require.Error(s.T(), err)
+}
+
+// failingOutbox 写入事件总是失败的发件箱
+type failingOutbox struct {
+	repository.GreeterOutboxRepository
+}
+
+func (failingOutbox) CreateGreeterOutbox(ctx context.Context, m model.GreeterOutbox) error {
+	return errors.New("database is locked")
+}
+
+// failingAudit 写入变更记录总是失败的仓库
+type failingAudit struct {
+	repository.GreeterAuditRepository
+}
+
+func (failingAudit) CreateGreeterAudit(ctx context.Context, m model.GreeterAudit) (model.GreeterAudit, error) {
+	return m, errors.New("database is locked")
+}
+
+// expectTransaction Transaction直接执行fn
+func expectTransaction(repoMock *mock.MockGreeterRepository) {
+	repoMock.EXPECT().Transaction(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+}
+
+// redisCache 使用redismock客户端的dao.Cache
+type redisCache struct {
+	cli *redis.Client
+}
+
+func (c redisCache) Redis() *redis.Client {
+	return c.cli
+}
+
+func (s *Suite) TestGreeterDomain_LocalCache() {
+	ctl := gomock.NewController(s.T())
+	defer ctl.Finish()
+	repoMock := mock.NewMockGreeterRepository(ctl)
+	cli, redisMock := redismock.NewClientMock()
+	dm := greeterDomain{
+		greeterCache: &greeterCache{Cache: redisCache{cli}, local: cache.NewLRU(10, time.Minute)},
+		repo:         repoMock,
+		auditRepo:    memory.NewGreeterAuditRepository(),
+		searchIndex:  memory.NewGreeterSearchIndex(),
+		counterRepo:  memory.NewGreeterCounterRepository(),
+		outboxRepo:   memory.NewGreeterOutboxRepository(),
+	}
+
+	ctx := context.Background()
+	m100 := model.Greeter{Id: 100, Name: "koofox", Version: 1}
+	m200 := model.Greeter{Id: 200, Name: "foxkoo", Version: 1}
+
+	// 第二次读取命中进程内缓存，不存在的Greeter不缓存
+	repoMock.EXPECT().GetGreeterById(ctx, int32(100)).Return(m100, nil).Times(1)
+	for i := 0; i < 2; i++ {
+		dto, err := dm.GetGreeterById(ctx, 100)
+		require.NoError(s.T(), err)
+		require.Equal(s.T(), "koofox", dto.Name)
+	}
+	repoMock.EXPECT().GetGreeterById(ctx, int32(300)).Return(model.Greeter{}, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		_, err := dm.GetGreeterById(ctx, 300)
+		require.NoError(s.T(), err)
+	}
+
+	// 批量读取只从仓库读取未命中的id，结果保持ids的顺序
+	repoMock.EXPECT().GetGreetersByIds(ctx, []int32{200, 300}).Return([]model.Greeter{m200}, []int32{300}, nil)
+	list, notFound, err := dm.GetGreetersByIds(ctx, []int32{200, 100, 300})
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []int32{200, 100}, []int32{list[0].Id, list[1].Id})
+	require.Equal(s.T(), []int32{300}, notFound)
+
+	// 变更成功后删除本实例的缓存并通知其他实例
+	expectTransaction(repoMock)
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(m100, nil),
+		repoMock.EXPECT().DeleteGreeterById(ctx, int32(100), int32(1)).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(model.Greeter{}, nil),
+	)
+	redisMock.ExpectPublish(utilx.CacheKey(greeterCacheChannel), "100").SetVal(1)
+	_, err = dm.DeleteGreeterById(ctx, 100, 1)
+	require.NoError(s.T(), err)
+	_, ok := dm.getGreeter(100)
+	require.False(s.T(), ok)
+
+	// 收到其他实例的通知后删除缓存
+	dm.evictLocal(zap.NewNop(), "200,abc")
+	_, ok = dm.getGreeter(200)
+	require.False(s.T(), ok)
+	require.NoError(s.T(), redisMock.ExpectationsWereMet())
+}
+
+func (s *Suite) TestGreeterDomain_RelayGreeterOutbox() {
+	viper.Set("storage", constant.StorageMemory)
+	viper.Set("outbox.retry_base", time.Millisecond)
+	defer func() {
+		viper.Set("storage", "")
+		viper.Set("outbox.retry_base", 0)
+	}()
+	dm := greeterDomain{outboxRepo: memory.NewGreeterOutboxRepository()}
+
+	ctx := context.Background()
+	for _, key := range []string{"100", "100", "200"} {
+		require.NoError(s.T(), dm.outboxRepo.CreateGreeterOutbox(ctx, model.GreeterOutbox{Topic: "greeter_relay", MessageKey: key, Body: []byte(key)}))
+	}
+
+	// 第一次发布失败的key之后的事件不发布，其他key不受影响
+	endpoint, err := brokerx.NewBroker(constant.MQName)
+	require.NoError(s.T(), err)
+	var published []string
+	failed := false
+	require.NoError(s.T(), endpoint.Subscribe(broker.Processor{Topic: "greeter_relay", Handler: func(msg *broker.Message) error {
+		if !failed {
+			failed = true
+			return errors.New("kafka: client has run out of available brokers")
+		}
+		published = append(published, msg.Key)
+		return nil
+	}}))
+
+	n, err := dm.RelayGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, n)
+	require.Equal(s.T(), []string{"200"}, published)
+	require.Equal(s.T(), float64(2), testutil.ToFloat64(greeterOutboxBacklog))
+
+	// 重试时间到达后按顺序发布
+	time.Sleep(5 * time.Millisecond)
+	n, err = dm.RelayGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, n)
+	require.Equal(s.T(), []string{"200", "100", "100"}, published)
+	require.Zero(s.T(), testutil.ToFloat64(greeterOutboxBacklog))
+}
+
+func (s *Suite) TestGreeterDomain_RelayGreeterOutboxDeadLetter() {
+	viper.Set("storage", constant.StorageMemory)
+	viper.Set("outbox.batch_size", 2)
+	viper.Set("outbox.max_attempts", 3)
+	viper.Set("outbox.retry_base", time.Hour)
+	defer func() {
+		viper.Set("storage", "")
+		viper.Set("outbox.batch_size", 0)
+		viper.Set("outbox.max_attempts", 0)
+		viper.Set("outbox.retry_base", 0)
+	}()
+	dm := greeterDomain{outboxRepo: memory.NewGreeterOutboxRepository()}
+
+	ctx := context.Background()
+	for _, body := range []string{"poison", "100-2", "100-3"} {
+		require.NoError(s.T(), dm.outboxRepo.CreateGreeterOutbox(ctx, model.GreeterOutbox{Topic: "greeter_dead", MessageKey: "100", Body: []byte(body)}))
+	}
+	require.NoError(s.T(), dm.outboxRepo.CreateGreeterOutbox(ctx, model.GreeterOutbox{Topic: "greeter_dead", MessageKey: "200", Body: []byte("200-1")}))
+
+	endpoint, err := brokerx.NewBroker(constant.MQName)
+	require.NoError(s.T(), err)
+	var published []string
+	require.NoError(s.T(), endpoint.Subscribe(broker.Processor{Topic: "greeter_dead", Handler: func(msg *broker.Message) error {
+		if string(msg.Body) == "poison" {
+			return errors.New("kafka: message is too large")
+		}
+		published = append(published, string(msg.Body))
+		return nil
+	}}))
+
+	// 整批都发布失败时等下一次中继
+	n, err := dm.RelayGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Zero(s.T(), n)
+
+	// 超过一批的事件在等待重试，不影响其他key
+	n, err = dm.RelayGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 1, n)
+	require.Equal(s.T(), []string{"200-1"}, published)
+	require.Equal(s.T(), float64(3), testutil.ToFloat64(greeterOutboxBacklog))
+
+	// 失败次数达到上限后进入死信，同一key后面的事件继续按顺序发布；第二次失败的重试时间设为已到
+	require.NoError(s.T(), dm.outboxRepo.MarkGreeterOutboxFailed(ctx, 1, time.Now().Add(-time.Second), "kafka: message is too large"))
+	n, err = dm.RelayGreeterOutbox(ctx)
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), 2, n)
+	require.Equal(s.T(), []string{"200-1", "100-2", "100-3"}, published)
+	require.Zero(s.T(), testutil.ToFloat64(greeterOutboxBacklog))
+	require.Equal(s.T(), float64(1), testutil.ToFloat64(greeterOutboxDead))
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	require.Equal(t, constant.GreeterOutboxRetryBase, outboxBackoff(0))
+	require.Equal(t, constant.GreeterOutboxRetryBase*8, outboxBackoff(3))
+	require.Equal(t, constant.GreeterOutboxRetryMax, outboxBackoff(20))
+}
+
+func (s *Suite) TestGreeterDomain_Events() {
+	ctl := gomock.NewController(s.T())
+	defer ctl.Finish()
+	repoMock := mock.NewMockGreeterRepository(ctl)
+	dm := greeterDomain{
+		repo:        repoMock,
+		auditRepo:   memory.NewGreeterAuditRepository(),
+		searchIndex: memory.NewGreeterSearchIndex(),
+		counterRepo: memory.NewGreeterCounterRepository(),
+		outboxRepo:  memory.NewGreeterOutboxRepository(),
+	}
+	expectTransaction(repoMock)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-actor", "koofox", "x-request-id", "req-1"))
+	created := model.Greeter{Id: 100, Name: "koofox@imind.tech", Status: 0, Version: 1}
+	renamed := model.Greeter{Id: 100, Name: "koofox@example.com", Status: 1, Version: 2}
+	counted := model.Greeter{Id: 100, Name: "koofox@example.com", Status: 1, ViewNum: 1, Version: 3}
+	restored := model.Greeter{Id: 100, Name: "koofox@example.com", Status: 1, ViewNum: 1, Version: 5}
+
+	repoMock.EXPECT().CreateGreeter(ctx, gomock.Any()).Return(created, nil)
+	require.NoError(s.T(), dm.CreateGreeter(ctx, &greeter.Greeter{Name: created.Name}))
+
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(created, nil),
+		repoMock.EXPECT().UpdateGreeter(ctx, gomock.Any(), []string{"name", "status", "status_operator", "status_datetime"}).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(renamed, nil),
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(renamed, nil),
+		repoMock.EXPECT().UpdateGreeterCount(ctx, int32(100), int32(1), int32(2), "view_num").Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(counted, nil),
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(counted, nil),
+		repoMock.EXPECT().DeleteGreeterById(ctx, int32(100), int32(3)).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(model.Greeter{}, nil),
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(model.Greeter{}, nil),
+		repoMock.EXPECT().RestoreGreeter(ctx, int32(100), int32(4)).Return(int64(1), nil),
+		repoMock.EXPECT().FindGreeterById(ctx, int32(100)).Return(restored, nil),
+	)
+	_, err := dm.UpdateGreeter(ctx, &greeter.Greeter{Id: 100, Name: renamed.Name, Status: greeter.GreeterStatus_GREETER_STATUS_ACTIVE, Version: 1},
+		[]string{"name", "status"})
+	require.NoError(s.T(), err)
+	_, err = dm.UpdateGreeterCount(ctx, 100, 1, 2, "view_num")
+	require.NoError(s.T(), err)
+	_, err = dm.DeleteGreeterById(ctx, 100, 3)
+	require.NoError(s.T(), err)
+	_, err = dm.RestoreGreeter(ctx, 100, 4)
+	require.NoError(s.T(), err)
+
+	// 版本号冲突时不写入事件
+	gomock.InOrder(
+		repoMock.EXPECT().FindGreeterForUpdate(ctx, int32(100)).Return(restored, nil),
+		repoMock.EXPECT().UpdateGreeterStatus(ctx, int32(100), int32(2), int32(5), "koofox").Return(int64(0), ErrVersionConflict),
+	)
+	_, err = dm.UpdateGreeterStatus(ctx, 100, greeter.GreeterStatus_GREETER_STATUS_DISABLED, 5)
+	require.True(s.T(), errors.Is(err, ErrVersionConflict))
+
+	var list []model.GreeterOutbox
+	_, err = dm.outboxRepo.RelayGreeterOutbox(ctx, 10, func(ctx context.Context, batch []model.GreeterOutbox) error {
+		list = batch
+		return nil
+	})
+	require.NoError(s.T(), err)
+	topics := make([]string, len(list))
+	events := make([]*greeter.GreeterEvent, len(list))
+	for i, m := range list {
+		topics[i] = m.Topic
+		events[i] = &greeter.GreeterEvent{}
+		require.NoError(s.T(), proto.Unmarshal(m.Body, events[i]))
+		require.Equal(s.T(), "100", m.MessageKey)
+		require.NotEmpty(s.T(), events[i].EventId)
+		require.EqualValues(s.T(), constant.GreeterEventSchemaVersion, events[i].SchemaVersion)
+		require.Equal(s.T(), "koofox", events[i].Actor)
+		require.Equal(s.T(), "req-1", events[i].RequestId)
+		require.EqualValues(s.T(), 100, events[i].GreeterId)
+		require.NotNil(s.T(), events[i].OccurredAt)
+	}
+	require.Equal(s.T(), []string{"greeter_create", "greeter_update", "greeter_status", "greeter_counter", "greeter_delete", "greeter_update"}, topics)
+	require.NotEqual(s.T(), events[0].EventId, events[1].EventId)
+
+	require.Equal(s.T(), created.Name, events[0].GetCreated().Greeter.Name)
+	require.Equal(s.T(), []string{"name", "status"}, events[1].GetUpdated().Paths)
+	require.Equal(s.T(), created.Name, events[1].GetUpdated().Before.Name)
+	require.Equal(s.T(), renamed.Name, events[1].GetUpdated().After.Name)
+	require.Equal(s.T(), greeter.GreeterStatus_GREETER_STATUS_PENDING, events[2].GetStatusChanged().From)
+	require.Equal(s.T(), greeter.GreeterStatus_GREETER_STATUS_ACTIVE, events[2].GetStatusChanged().To)
+	require.EqualValues(s.T(), 2, events[2].GetStatusChanged().Version)
+	require.Equal(s.T(), &greeter.GreeterCounterChanged{Column: "view_num", Delta: 1, Version: 3}, events[3].GetCounterChanged())
+	require.EqualValues(s.T(), 3, events[4].GetDeleted().Greeter.Version)
+	require.Nil(s.T(), events[5].GetUpdated().Before)
+	require.Equal(s.T(), []string{"delete_datetime"}, events[5].GetUpdated().Paths)
+}
@@ -0,0 +1,128 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/cursor"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+const defaultHistoryPageSize = 20
+
+// greeterHistoryCursor 变更记录的page_token
+type greeterHistoryCursor struct {
+	GreeterId int32 `json:"g"`
+	LastId    int64 `json:"l"`
+}
+
+// ListGreeterHistory 按时间倒序返回Greeter的变更记录，nextPageToken为空时表示没有更多记录
+func (dm greeterDomain) ListGreeterHistory(ctx context.Context, id int32, pageToken string, pageSize int32) ([]*greeter.GreeterHistory, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultHistoryPageSize
+	}
+	var c greeterHistoryCursor
+	if pageToken != "" {
+		if err := cursor.Decode(pageToken, &c); err != nil {
+			return nil, "", errors.Wrap(ErrInvalidPageToken, err.Error())
+		}
+		if c.GreeterId != id {
+			return nil, "", errors.Wrap(ErrInvalidPageToken, "page token does not match the greeter")
+		}
+	}
+
+	// 多取一条用于判断是否还有下一页
+	list, err := dm.auditRepo.FindGreeterAudits(ctx, id, c.LastId, pageSize+1)
+	if err != nil {
+		return nil, "", errors.WithMessage(err, "greeterDomain.ListGreeterHistory")
+	}
+	var nextPageToken string
+	if len(list) > int(pageSize) {
+		list = list[:pageSize]
+		nextPageToken, err = cursor.Encode(greeterHistoryCursor{GreeterId: id, LastId: list[len(list)-1].Id})
+		if err != nil {
+			return nil, "", errors.Wrap(err, "greeterDomain.ListGreeterHistory")
+		}
+	}
+
+	histories := make([]*greeter.GreeterHistory, 0, len(list))
+	for _, m := range list {
+		history, err := GreeterAuditModel2Dto(m)
+		if err != nil {
+			return nil, "", errors.WithMessage(err, "greeterDomain.ListGreeterHistory")
+		}
+		histories = append(histories, history)
+	}
+	return histories, nextPageToken, nil
+}
+
+// audit 在变更的事务中写入变更记录，返回错误时变更和变更记录一起回滚
+func (dm greeterDomain) audit(ctx context.Context, action greeter.GreeterAction, id int32, before, after model.Greeter) error {
+	m := model.GreeterAudit{
+		GreeterId: id,
+		Action:    int32(action),
+		Actor:     utilx.Actor(ctx),
+		RequestId: utilx.RequestId(ctx),
+	}
+	var err error
+	if m.Before, err = marshalGreeter(before); err != nil {
+		return errors.Wrapf(err, "greeterDomain.audit marshal greeter %d before", id)
+	}
+	if m.After, err = marshalGreeter(after); err != nil {
+		return errors.Wrapf(err, "greeterDomain.audit marshal greeter %d after", id)
+	}
+	_, err = dm.auditRepo.CreateGreeterAudit(ctx, m)
+	return errors.WithMessage(err, "greeterDomain.audit")
+}
+
+func GreeterAuditModel2Dto(po model.GreeterAudit) (*greeter.GreeterHistory, error) {
+	dto := &greeter.GreeterHistory{}
+	dto.Id = po.Id
+	dto.GreeterId = po.GreeterId
+	dto.Action = greeter.GreeterAction(po.Action)
+	dto.Actor = po.Actor
+	dto.RequestId = po.RequestId
+	dto.CreateTime = po.CreateTime
+	dto.CreateDatetime = po.CreateDatetime
+
+	var err error
+	if dto.Before, err = unmarshalGreeter(po.Before); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal audit %d before", po.Id)
+	}
+	if dto.After, err = unmarshalGreeter(po.After); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal audit %d after", po.Id)
+	}
+	return dto, nil
+}
+
+// marshalGreeter 空的Greeter序列化为空字符串
+func marshalGreeter(m model.Greeter) (string, error) {
+	dto := GreeterModel2Dto(m)
+	if dto == nil {
+		return "", nil
+	}
+	data, err := protojson.Marshal(dto)
+	return string(data), err
+}
+
+func unmarshalGreeter(data string) (*greeter.Greeter, error) {
+	if data == "" {
+		return nil, nil
+	}
+	dto := &greeter.Greeter{}
+	if err := protojson.Unmarshal([]byte(data), dto); err != nil {
+		return nil, err
+	}
+	return dto, nil
+}
@@ -0,0 +1,187 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/cursor"
+)
+
+// greeterListCursor page_token中记录的查询条件和位置，查询条件与请求不一致的page_token视为无效
+type greeterListCursor struct {
+	// Statuses 实际查询的状态，即statuses，未指定时为status
+	Statuses       []int32 `json:"s"`
+	IncludeDeleted bool    `json:"d,omitempty"`
+	// Filter 除status和include_deleted以外的过滤和排序条件的摘要，没有这些条件时为空
+	Filter    string `json:"f,omitempty"`
+	LastId    int32  `json:"l"`
+	LastValue int64  `json:"v,omitempty"`
+}
+
+// GetGreeterList 只按单个状态以id倒序查询时使用缓存，其他过滤和排序条件的组合直接查询数据库
+func (dm greeterDomain) GetGreeterList(ctx context.Context, query repository.GreeterListQuery) (*greeter.GreeterList, error) {
+	filter := query.Filter()
+	digest := greeterListFilterDigest(filter)
+
+	lastId, page, pageSize := query.LastId, query.Page, query.PageSize
+	// lastid只在按id排序时有效
+	if filter.SortBy != repository.GreeterSortById {
+		lastId = 0
+	}
+	var lastValue int64
+	if query.PageToken != "" {
+		var c greeterListCursor
+		if err := cursor.Decode(query.PageToken, &c); err != nil {
+			return nil, errors.Wrap(ErrInvalidPageToken, err.Error())
+		}
+		if !equalStatuses(c.Statuses, filter.Statuses) || c.IncludeDeleted != query.IncludeDeleted || c.Filter != digest {
+			return nil, errors.Wrap(ErrInvalidPageToken, "page token does not match the query")
+		}
+		lastId, lastValue, page = c.LastId, c.LastValue, 1
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	// 按id分页时多取一条用于判断是否还有下一页，按page分页时通过总数判断
+	byId := lastId > 0 || page == 1
+	limit := pageSize
+	if byId {
+		limit++
+	}
+	var (
+		list   []model.Greeter
+		total  int
+		failed []repository.GreeterFailure
+		err    error
+	)
+	if digest == "" {
+		list, total, failed, err = dm.repo.GetGreeterList(ctx, filter.Statuses[0], lastId, limit, page, repository.GreeterListWithDeleted(query.IncludeDeleted))
+	} else {
+		var after *repository.GreeterListAfter
+		if lastId > 0 {
+			after = &repository.GreeterListAfter{Value: lastValue, Id: lastId}
+		}
+		list, total, err = dm.repo.FindGreeterList(ctx, filter, after, limit, page)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	more := int(page*pageSize) < total
+	if byId {
+		more = len(list)+len(failed) > int(pageSize)
+		if more {
+			list, failed = trimGreeterPage(list, failed)
+		}
+	}
+	// 游标使用数据库中的值，与下一页查询条件一致
+	greeters := GreeterMap(dm.withPendingCounters(ctx, list), GreeterModel2Dto)
+
+	var totalPage int32 = 0
+	if total == 0 {
+		page = 1
+	} else {
+		totalPage = int32(math.Ceil(float64(total) / float64(pageSize)))
+	}
+	greeterList := &greeter.GreeterList{}
+	greeterList.Datalist = greeters
+	greeterList.Total = int32(total)
+	greeterList.TotalPage = totalPage
+	greeterList.CurPage = page
+	greeterList.Incomplete = len(failed) > 0
+	greeterList.Failed = GreeterFailures2Dto(failed)
+	if more && len(list)+len(failed) > 0 {
+		// 读取失败的Greeter只出现在按id倒序使用缓存的查询中，此时页中最后的位置可能是读取失败的id
+		var last model.Greeter
+		if len(list) > 0 {
+			last = list[len(list)-1]
+		}
+		if n := len(failed); n > 0 && (last.Id == 0 || failed[n-1].Id < last.Id) {
+			last = model.Greeter{Id: failed[n-1].Id}
+		}
+		greeterList.NextPageToken, err = cursor.Encode(greeterListCursor{
+			Statuses:       filter.Statuses,
+			IncludeDeleted: query.IncludeDeleted,
+			Filter:         digest,
+			LastId:         last.Id,
+			LastValue:      greeterSortValue(last, filter.SortBy),
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "greeterDomain.GetGreeterList")
+		}
+	}
+
+	return greeterList, nil
+}
+
+// trimGreeterPage 去掉多取的一条，即读取成功和失败的Greeter中id最小的一个，两者都按id倒序排列
+func trimGreeterPage(list []model.Greeter, failed []repository.GreeterFailure) ([]model.Greeter, []repository.GreeterFailure) {
+	if n := len(failed); n > 0 && (len(list) == 0 || failed[n-1].Id < list[len(list)-1].Id) {
+		return list, failed[:n-1]
+	}
+	return list[:len(list)-1], failed
+}
+
+// GreeterFailures2Dto 超时和取消的读取标记为TIMEOUT，其余为ERROR
+func GreeterFailures2Dto(failed []repository.GreeterFailure) []*greeter.GreeterFailure {
+	var dtos []*greeter.GreeterFailure
+	for _, f := range failed {
+		reason := greeter.GreeterFailureReason_GREETER_FAILURE_REASON_ERROR
+		if errors.Is(f.Err, context.DeadlineExceeded) || errors.Is(f.Err, context.Canceled) {
+			reason = greeter.GreeterFailureReason_GREETER_FAILURE_REASON_TIMEOUT
+		}
+		dtos = append(dtos, &greeter.GreeterFailure{Id: f.Id, Reason: reason})
+	}
+	return dtos
+}
+
+// greeterListFilterDigest 除include_deleted以外，filter可以使用缓存时返回空，否则返回filter的摘要
+func greeterListFilterDigest(filter repository.GreeterListFilter) string {
+	filter.IncludeDeleted = false
+	if filter.Cacheable() {
+		return ""
+	}
+	data, _ := json.Marshal(filter)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// equalStatuses 两组状态按顺序逐一相同时返回true
+func equalStatuses(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// greeterSortValue 返回m中排序字段的值，按id排序时为0
+func greeterSortValue(m model.Greeter, sortBy repository.GreeterSortField) int64 {
+	switch sortBy {
+	case repository.GreeterSortByViewNum:
+		return int64(m.ViewNum)
+	case repository.GreeterSortByCreateTime:
+		return m.CreateTime
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"sync"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/memory"
+)
+
+var (
+	memoryDomainOnce sync.Once
+	memoryDomain     greeterDomain
+)
+
+// newMemoryGreeterDomain storage为memory时同一进程内的GreeterDomain共享内存中的仓库，
+// 不使用Redis和进程内缓存，也不支持缓存的检查和预热
+func newMemoryGreeterDomain() greeterDomain {
+	memoryDomainOnce.Do(func() {
+		memoryDomain = greeterDomain{
+			greeterCache: &greeterCache{},
+			repo:         memory.NewGreeterRepository(),
+			auditRepo:    memory.NewGreeterAuditRepository(),
+			searchIndex:  memory.NewGreeterSearchIndex(),
+			counterRepo:  memory.NewGreeterCounterRepository(),
+			outboxRepo:   memory.NewGreeterOutboxRepository(),
+		}
+	})
+	return memoryDomain
+}
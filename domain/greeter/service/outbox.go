@@ -0,0 +1,166 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	brokerx "github.com/imind-lab/greeter/pkg/broker"
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/broker"
+)
+
+// greeterOutboxBacklog 发件箱中未发送的事件数量，每次中继后更新
+var greeterOutboxBacklog = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "greeter",
+	Name:      "outbox_backlog",
+	Help:      "Greeter events written to the outbox but not yet published.",
+})
+
+// greeterOutboxDead 发布失败次数达到outbox.max_attempts后进入死信的事件数量
+var greeterOutboxDead = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "greeter",
+	Name:      "outbox_dead",
+	Help:      "Greeter events that exceeded outbox.max_attempts and will not be published.",
+})
+
+func init() {
+	prometheus.MustRegister(greeterOutboxBacklog, greeterOutboxDead)
+}
+
+// RelayGreeterOutbox 将发件箱中的事件分批发布到消息队列，直到没有可以发布的事件，返回发布的数量。
+// 发布失败的事件按outbox.retry_base指数退避，最长间隔outbox.retry_max，失败outbox.max_attempts次后进入死信；
+// 同一个key前面的事件未发布时，后面的事件也不发布，保证同一Greeter的事件按顺序到达，其他key不受影响
+func (dm greeterDomain) RelayGreeterOutbox(ctx context.Context) (int, error) {
+	batchSize := viper.GetInt("outbox.batch_size")
+	if batchSize <= 0 {
+		batchSize = constant.GreeterOutboxBatchSize
+	}
+
+	total := 0
+	for {
+		sent := 0
+		n, err := dm.outboxRepo.RelayGreeterOutbox(ctx, batchSize, func(ctx context.Context, list []model.GreeterOutbox) error {
+			var err error
+			sent, err = dm.relayGreeterOutbox(ctx, list)
+			return err
+		})
+		total += sent
+		if err != nil {
+			dm.updateOutboxBacklog(ctx)
+			return total, errors.WithMessage(err, "greeterDomain.RelayGreeterOutbox")
+		}
+		// 整批都没有发布成功时消息队列多半不可用，等下一次中继；等待重试的key已不会再取出
+		if n < batchSize || sent == 0 {
+			break
+		}
+	}
+	dm.updateOutboxBacklog(ctx)
+	return total, nil
+}
+
+// relayGreeterOutbox 按顺序发布一批事件，返回发布成功的数量；无法连接消息队列时不计入失败次数
+func (dm greeterDomain) relayGreeterOutbox(ctx context.Context, list []model.GreeterOutbox) (int, error) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterDomain"), zap.String("func", "relayGreeterOutbox"))
+
+	endpoint, err := brokerx.NewBroker(constant.MQName)
+	if err != nil {
+		return 0, err
+	}
+
+	maxAttempts := int32(viper.GetInt("outbox.max_attempts"))
+	if maxAttempts <= 0 {
+		maxAttempts = constant.GreeterOutboxMaxAttempts
+	}
+
+	now := time.Now()
+	blocked := make(map[string]bool)
+	sent := 0
+	for _, m := range list {
+		if blocked[m.MessageKey] {
+			continue
+		}
+
+		err := endpoint.Publish(broker.NewMessage(m.Topic, m.Body, broker.MessageKey(m.MessageKey)))
+		if err != nil {
+			if m.Attempts+1 >= maxAttempts {
+				// 进入死信后同一key后面的事件可以继续发布
+				logger.Error("Greeter事件发布失败次数达到上限，进入死信", zap.Int64("id", m.Id), zap.String("topic", m.Topic),
+					zap.String("key", m.MessageKey), zap.Int32("attempts", m.Attempts+1), zap.Error(err))
+				if err := dm.outboxRepo.MarkGreeterOutboxDead(ctx, m.Id, err.Error()); err != nil {
+					return sent, err
+				}
+				continue
+			}
+			blocked[m.MessageKey] = true
+			next := now.Add(outboxBackoff(m.Attempts))
+			logger.Warn("发布Greeter事件失败", zap.Int64("id", m.Id), zap.String("topic", m.Topic),
+				zap.Int32("attempts", m.Attempts+1), zap.Time("next", next), zap.Error(err))
+			if err := dm.outboxRepo.MarkGreeterOutboxFailed(ctx, m.Id, next, err.Error()); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err := dm.outboxRepo.MarkGreeterOutboxSent(ctx, m.Id); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// outboxBackoff 已失败attempts次的事件再次失败后的等待时间
+func outboxBackoff(attempts int32) time.Duration {
+	base := viper.GetDuration("outbox.retry_base")
+	if base <= 0 {
+		base = constant.GreeterOutboxRetryBase
+	}
+	max := viper.GetDuration("outbox.retry_max")
+	if max <= 0 {
+		max = constant.GreeterOutboxRetryMax
+	}
+
+	backoff := base
+	for i := int32(0); i < attempts && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// updateOutboxBacklog 更新未发送和死信事件数量的指标，读取失败时保留上一次的值
+func (dm greeterDomain) updateOutboxBacklog(ctx context.Context) {
+	logger := ctxzap.Extract(ctx).With(zap.String("layer", "greeterDomain"), zap.String("func", "updateOutboxBacklog"))
+
+	if count, err := dm.outboxRepo.CountPendingGreeterOutbox(ctx); err != nil {
+		logger.Warn("读取发件箱积压数量失败", zap.Error(err))
+	} else {
+		greeterOutboxBacklog.Set(float64(count))
+	}
+	if count, err := dm.outboxRepo.CountDeadGreeterOutbox(ctx); err != nil {
+		logger.Warn("读取发件箱死信数量失败", zap.Error(err))
+	} else {
+		greeterOutboxDead.Set(float64(count))
+	}
+}
+
+// PurgeGreeterOutbox 删除发送超过olderThan的事件
+func (dm greeterDomain) PurgeGreeterOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	n, err := dm.outboxRepo.PurgeSentGreeterOutbox(ctx, time.Now().Add(-olderThan))
+	return n, errors.WithMessage(err, "greeterDomain.PurgeGreeterOutbox")
+}
@@ -0,0 +1,129 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository/model"
+	"github.com/imind-lab/greeter/pkg/cursor"
+)
+
+const defaultSearchPageSize = 20
+
+// greeterSearchCursor 搜索结果的page_token，结果按相关度排序，使用偏移量分页
+type greeterSearchCursor struct {
+	Query  string `json:"q"`
+	Offset int32  `json:"o"`
+}
+
+// SearchGreeters 按名称中的片段搜索Greeter，nextPageToken为空时表示没有更多结果
+func (dm greeterDomain) SearchGreeters(ctx context.Context, query, pageToken string, pageSize int32) ([]*greeter.GreeterSearchResult, int32, string, error) {
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	}
+	var c greeterSearchCursor
+	if pageToken != "" {
+		if err := cursor.Decode(pageToken, &c); err != nil {
+			return nil, 0, "", errors.Wrap(ErrInvalidPageToken, err.Error())
+		}
+		if c.Query != query {
+			return nil, 0, "", errors.Wrap(ErrInvalidPageToken, "page token does not match the query")
+		}
+	}
+
+	hits, total, err := dm.searchIndex.SearchGreeters(ctx, query, c.Offset, pageSize)
+	if err != nil {
+		return nil, 0, "", errors.WithMessage(err, "greeterDomain.SearchGreeters")
+	}
+	ids := make([]int32, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Id
+	}
+	list, _, err := dm.getGreetersByIds(ctx, ids)
+	if err != nil {
+		return nil, 0, "", errors.WithMessage(err, "greeterDomain.SearchGreeters")
+	}
+	found := make(map[int32]model.Greeter, len(list))
+	for _, m := range dm.withPendingCounters(ctx, list) {
+		found[m.Id] = m
+	}
+
+	// 索引与数据不一致时跳过已不存在的Greeter
+	results := make([]*greeter.GreeterSearchResult, 0, len(hits))
+	for _, hit := range hits {
+		m, ok := found[hit.Id]
+		if !ok {
+			continue
+		}
+		results = append(results, &greeter.GreeterSearchResult{
+			Greeter:   GreeterModel2Dto(m),
+			Score:     hit.Score,
+			Highlight: highlightName(m.Name, query),
+		})
+	}
+
+	var nextPageToken string
+	if offset := c.Offset + int32(len(hits)); len(hits) > 0 && int(offset) < total {
+		nextPageToken, err = cursor.Encode(greeterSearchCursor{Query: query, Offset: offset})
+		if err != nil {
+			return nil, 0, "", errors.Wrap(err, "greeterDomain.SearchGreeters")
+		}
+	}
+	return results, int32(total), nextPageToken, nil
+}
+
+// indexGreeter 变更成功后同步搜索索引，m为空（变更后读取失败）时跳过，同步失败只记录日志
+func (dm greeterDomain) indexGreeter(ctx context.Context, m model.Greeter) {
+	if m.IsEmpty() {
+		return
+	}
+	if err := dm.searchIndex.IndexGreeter(ctx, m); err != nil {
+		ctxzap.Extract(ctx).Error("同步Greeter搜索索引失败", zap.String("layer", "greeterDomain"), zap.String("func", "indexGreeter"),
+			zap.Int32("id", m.Id), zap.Error(err))
+	}
+}
+
+// unindexGreeter 删除成功后从搜索索引中删除，失败只记录日志
+func (dm greeterDomain) unindexGreeter(ctx context.Context, id int32) {
+	if err := dm.searchIndex.RemoveGreeter(ctx, id); err != nil {
+		ctxzap.Extract(ctx).Error("删除Greeter搜索索引失败", zap.String("layer", "greeterDomain"), zap.String("func", "unindexGreeter"),
+			zap.Int32("id", id), zap.Error(err))
+	}
+}
+
+// highlightName 用<em></em>标记name中与query匹配的部分（不区分大小写），其余部分做HTML转义
+func highlightName(name, query string) string {
+	lower, q := strings.ToLower(name), strings.ToLower(query)
+	// 转为小写后长度变化时无法对应原字符串中的位置，不做标记
+	if q == "" || len(lower) != len(name) {
+		return html.EscapeString(name)
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			break
+		}
+		b.WriteString(html.EscapeString(name[:i]))
+		b.WriteString("<em>")
+		b.WriteString(html.EscapeString(name[i : i+len(q)]))
+		b.WriteString("</em>")
+		name, lower = name[i+len(q):], lower[i+len(q):]
+	}
+	b.WriteString(html.EscapeString(name))
+	return b.String()
+}
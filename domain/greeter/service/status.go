@@ -0,0 +1,82 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package service
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+)
+
+// ErrIllegalStatusTransition 状态机不允许的状态变更
+var ErrIllegalStatusTransition = errors.New("illegal greeter status transition")
+
+// greeterStatusTransitions 状态机，key为当前状态，value为允许变更到的状态
+var greeterStatusTransitions = map[greeter.GreeterStatus][]greeter.GreeterStatus{
+	greeter.GreeterStatus_GREETER_STATUS_PENDING: {
+		greeter.GreeterStatus_GREETER_STATUS_ACTIVE,
+		greeter.GreeterStatus_GREETER_STATUS_DISABLED,
+		greeter.GreeterStatus_GREETER_STATUS_DELETED,
+	},
+	greeter.GreeterStatus_GREETER_STATUS_ACTIVE: {
+		greeter.GreeterStatus_GREETER_STATUS_DISABLED,
+		greeter.GreeterStatus_GREETER_STATUS_DELETED,
+	},
+	greeter.GreeterStatus_GREETER_STATUS_DISABLED: {
+		greeter.GreeterStatus_GREETER_STATUS_ACTIVE,
+		greeter.GreeterStatus_GREETER_STATUS_DELETED,
+	},
+	greeter.GreeterStatus_GREETER_STATUS_DELETED: {
+		greeter.GreeterStatus_GREETER_STATUS_PENDING,
+	},
+}
+
+// greeterInitialStatuses 创建Greeter时允许的初始状态
+var greeterInitialStatuses = []greeter.GreeterStatus{
+	greeter.GreeterStatus_GREETER_STATUS_PENDING,
+	greeter.GreeterStatus_GREETER_STATUS_ACTIVE,
+}
+
+// StatusTransitionError 描述被拒绝的状态变更，errors.Is(err, ErrIllegalStatusTransition)为true
+type StatusTransitionError struct {
+	From greeter.GreeterStatus
+	To   greeter.GreeterStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	if e.From == e.To {
+		return fmt.Sprintf("greeter status is already %s", e.To)
+	}
+	return fmt.Sprintf("greeter status can not transition from %s to %s", e.From, e.To)
+}
+
+func (e *StatusTransitionError) Is(target error) bool {
+	return target == ErrIllegalStatusTransition
+}
+
+// CheckStatusTransition 校验from到to的状态变更是否被状态机允许
+func CheckStatusTransition(from, to greeter.GreeterStatus) error {
+	for _, s := range greeterStatusTransitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return &StatusTransitionError{From: from, To: to}
+}
+
+// CheckInitialStatus 校验创建Greeter时的初始状态
+func CheckInitialStatus(status greeter.GreeterStatus) error {
+	for _, s := range greeterInitialStatuses {
+		if s == status {
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrIllegalStatusTransition, "greeter can not be created with status %s", status)
+}
@@ -0,0 +1,105 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package broker
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/imind-lab/micro/broker"
+
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+var (
+	memoryMu      sync.Mutex
+	memoryBrokers = make(map[string]*memoryBroker)
+)
+
+// NewBroker storage为memory时返回进程内的Broker，否则返回micro连接Kafka的Broker
+func NewBroker(name string) (broker.Broker, error) {
+	if !utilx.MemoryStorage() {
+		return broker.NewBroker(name)
+	}
+
+	memoryMu.Lock()
+	defer memoryMu.Unlock()
+
+	b, ok := memoryBrokers[name]
+	if !ok {
+		b = &memoryBroker{opts: broker.NewOptions(name), processors: make(map[string]broker.Processor)}
+		memoryBrokers[name] = b
+	}
+	return b, nil
+}
+
+// memoryBroker 进程内的Broker，Publish在调用方的goroutine中将消息交给订阅的处理器，
+// 处理失败时按Processor.Retry重试，没有订阅的消息直接丢弃
+type memoryBroker struct {
+	mu         sync.RWMutex
+	opts       broker.Options
+	processors map[string]broker.Processor
+}
+
+func (b *memoryBroker) Init(opts ...broker.Option) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, o := range opts {
+		o(&b.opts)
+	}
+	return nil
+}
+
+func (b *memoryBroker) Options() broker.Options {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.opts
+}
+
+func (b *memoryBroker) Connect() error {
+	return nil
+}
+
+func (b *memoryBroker) Close() error {
+	return nil
+}
+
+func (b *memoryBroker) Publish(msg *broker.Message) error {
+	if msg.Topic == "" {
+		return errors.New("[memory] message topic is empty")
+	}
+	b.mu.RLock()
+	p, ok := b.processors[msg.Topic]
+	b.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	var err error
+	for i := 0; i <= p.Retry; i++ {
+		if err = p.Handler(msg); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (b *memoryBroker) Subscribe(procs ...broker.Processor) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, p := range procs {
+		b.processors[p.Topic] = p
+	}
+	return nil
+}
+
+func (b *memoryBroker) String() string {
+	return "memory"
+}
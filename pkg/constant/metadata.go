@@ -0,0 +1,13 @@
+package constant
+
+// ActorMetadataKey 调用方通过gRPC metadata传递操作人，经gateway调用时使用Grpc-Metadata-X-Actor请求头
+const ActorMetadataKey = "x-actor"
+
+// AnonymousActor 未传递操作人时记录的操作人
+const AnonymousActor = "anonymous"
+
+// RequestIdMetadataKey 调用方通过gRPC metadata传递请求id
+const RequestIdMetadataKey = "x-request-id"
+
+// SessionMetadataKey 调用方通过gRPC metadata传递会话id，同一会话写入后的一段时间内读取使用主库
+const SessionMetadataKey = "x-session-id"
@@ -14,8 +14,136 @@ import (
 //CRequestTimeout 并发请求超时时间
 const CRequestTimeout = time.Second * 10
 
+// ReadYourWritesWindow 未配置db.<name>.sticky_window时，同一会话写入后读取使用主库的时间
+const ReadYourWritesWindow = time.Second * 3
+
+// GreeterListConcurrency 未配置pagination.concurrency时分页并发读取Greeter的worker数量
+const GreeterListConcurrency = 8
+
+// RetryDelay 可重试错误建议调用方等待的时间
+const RetryDelay = time.Second
+
 const DBName = "imind"
+
+// TablePrefix 未配置db.<name>.tablePrefix时的表名前缀，表名为前缀加上模型名的蛇形命名，例如tbl_greeter
+const TablePrefix = "tbl_"
+
+// db.<name>.driver的取值，默认为mysql
+const (
+	DBDriverMySQL    = "mysql"
+	DBDriverSQLite   = "sqlite"
+	DBDriverPostgres = "postgres"
+)
+
+// MigrationDir migrate create默认写入迁移文件的目录，每种数据库一个子目录
+const MigrationDir = "domain/greeter/repository/persistence/migrations"
+
+// MigrationLockTimeout 未配置migration.lock_timeout时等待其他实例释放迁移锁的时间
+const MigrationLockTimeout = time.Minute
+
+// MigrationLockRetryDelay 迁移锁被其他实例持有时重试的间隔
+const MigrationLockRetryDelay = time.Second
+
+// MigrationLockStale 迁移锁超过该时间没有刷新时视为持有者已退出，可以被其他实例获取
+const MigrationLockStale = time.Minute * 10
+
+// MigrationLockRefresh 持有迁移锁期间刷新locked_at的间隔，需要远小于MigrationLockStale
+const MigrationLockRefresh = time.Minute
+
+// 数据存储，storage为memory时数据保存在进程内，不依赖MySQL、Redis和Kafka，进程退出后数据丢失
+const (
+	StorageMySQL  = "mysql"
+	StorageMemory = "memory"
+)
 const Realtime = false
 
 const MQName = "business"
+
+// TopicGreeterCreate等 Greeter事件的主题在kafka.<MQName>.topic中的key
+const (
+	TopicGreeterCreate  = "greeterCreate"
+	TopicGreeterUpdate  = "greeterUpdate"
+	TopicGreeterStatus  = "greeterStatus"
+	TopicGreeterCounter = "greeterCounter"
+	TopicGreeterDelete  = "greeterDelete"
+)
+
+// GreeterEventSchemaVersion Greeter事件结构的版本，GreeterEvent有不兼容的变更时加1
+const GreeterEventSchemaVersion = 1
 const GreeterQueueLen = 32
+
+// GreeterStatusMax Greeter状态的最大值，状态取值范围为[0, GreeterStatusMax]，按状态划分的缓存需要覆盖全部取值
+const GreeterStatusMax = 3
+
+// CursorSecretEnv 分页游标签名密钥的环境变量，设置时优先于配置pagination.secret
+const CursorSecretEnv = "GREETER_PAGINATION_SECRET"
+
+// CursorSecretInsecure 早期conf.yaml中提交的pagination.secret，已经公开，不能再作为签名密钥
+const CursorSecretInsecure = "imind-greeter-cursor"
+
+// GreeterIdsChunkSize 重建greeter_ids_有序集合时每批读取的id数量
+const GreeterIdsChunkSize = 1000
+
+// GreeterIdsRebuildTimeout 后台重建greeter_ids_有序集合的超时时间
+const GreeterIdsRebuildTimeout = time.Minute
+
+// GreeterCounterFlushInterval 未配置counter.flush_interval时计数增量写入数据库的间隔
+const GreeterCounterFlushInterval = time.Second * 5
+
+// GreeterCounterBatchSize 未配置counter.batch_size时每批写入数据库的Greeter数量
+const GreeterCounterBatchSize = 500
+
+// GreeterCounterFlushLockTTL 写入计数增量时持有的分布式锁的过期时间
+const GreeterCounterFlushLockTTL = time.Second * 30
+
+// GreeterCounterFlushLockRefresh 写入计数增量期间延长分布式锁的间隔，需要远小于GreeterCounterFlushLockTTL
+const GreeterCounterFlushLockRefresh = time.Second * 10
+
+// GreeterOutboxRelayInterval 未配置outbox.relay_interval时中继发布发件箱事件的间隔
+const GreeterOutboxRelayInterval = time.Second
+
+// GreeterOutboxBatchSize 未配置outbox.batch_size时中继每批发布的事件数量
+const GreeterOutboxBatchSize = 100
+
+// GreeterOutboxRetryBase、GreeterOutboxRetryMax 未配置outbox.retry_base、outbox.retry_max时发布失败后的重试间隔，
+// 第n次失败后等待retry_base*2^(n-1)，最长retry_max
+const (
+	GreeterOutboxRetryBase = time.Second
+	GreeterOutboxRetryMax  = time.Minute * 5
+)
+
+// GreeterOutboxMaxAttempts 未配置outbox.max_attempts时事件进入死信前的最大发布次数
+const GreeterOutboxMaxAttempts = 20
+
+// GreeterOutboxRetention 未配置outbox.retention时已发送事件的保留时间
+const GreeterOutboxRetention = time.Hour * 24 * 7
+
+// GreeterOutboxLockTTL 中继发布时持有的分布式锁的过期时间
+const GreeterOutboxLockTTL = time.Second * 30
+
+// GreeterCacheVerifyBatchSize 未指定--batch-size时缓存一致性检查每批读取的Greeter数量
+const GreeterCacheVerifyBatchSize = 500
+
+// CacheWarmupTimeout 未配置cache.warmup.timeout时缓存预热的时间上限
+const CacheWarmupTimeout = time.Second * 30
+
+// CacheWarmupTopN 未配置cache.warmup.top_n时预热哈希表缓存的Greeter数量
+const CacheWarmupTopN = 1000
+
+// CacheXFetchBeta 缓存提前刷新（XFetch）的系数，越大越倾向于提前刷新
+const CacheXFetchBeta = 1.0
+
+// CacheLockTTL 开启cache.lock时跨实例加载缓存的锁的过期时间，也是合并加载和后台提前刷新的超时时间
+const CacheLockTTL = time.Second * 3
+
+// CacheLockRetry 未获得锁时等待其他实例写入缓存的次数，每次间隔CacheLockRetryDelay，仍未写入时直接查询数据库
+const CacheLockRetry = 10
+
+// CacheLockRetryDelay 未获得锁时每次检查缓存的间隔
+const CacheLockRetryDelay = time.Millisecond * 50
+
+// CacheLocalSize 未配置cache.local.size时进程内缓存的容量
+const CacheLocalSize = 10000
+
+// CacheLocalTTL 未配置cache.local.ttl时进程内缓存的过期时间，也是其他实例的变更未通知到时读到旧数据的最长时间
+const CacheLocalTTL = time.Second * 10
@@ -4,6 +4,9 @@ import (
 	"github.com/imind-lab/micro/status"
 )
 
+// ErrorDomain google.rpc.ErrorInfo中的错误域
+const ErrorDomain = "greeter.imind.tech"
+
 const (
 	GreeterObjectIsEmpty status.Code = iota + 10100
 	NameFieldIsEmpty
@@ -13,17 +16,37 @@ const (
 	UpdateGreeterFailed
 	DeleteGreeterFailed
 	StatusIsInvalid
+	GreeterNotFound
+	PublishEventFailed
+	RequestIsInvalid
+	VersionConflict
+	RestoreGreeterFailed
+	PurgeGreeterFailed
+	StatusTransitionIllegal
+	FetchGreeterHistoryFailed
+	PageTokenIsInvalid
+	SearchGreetersFailed
 )
 
 var Errors = map[status.Code]string{
-	GreeterObjectIsEmpty:   "GreeterObjectIsEmpty",
-	NameFieldIsEmpty:       "NameFieldIsEmpty",
-	CreateGreeterFailed:    "CreateGreeterFailed",
-	FetchGreeterFailed:     "FetchGreeterFailed",
-	FetchGreeterListFailed: "FetchGreeterListFailed",
-	UpdateGreeterFailed:    "UpdateGreeterFailed",
-	DeleteGreeterFailed:    "DeleteGreeterFailed",
-	StatusIsInvalid:        "StatusIsInvalid",
+	GreeterObjectIsEmpty:      "GreeterObjectIsEmpty",
+	NameFieldIsEmpty:          "NameFieldIsEmpty",
+	CreateGreeterFailed:       "CreateGreeterFailed",
+	FetchGreeterFailed:        "FetchGreeterFailed",
+	FetchGreeterListFailed:    "FetchGreeterListFailed",
+	UpdateGreeterFailed:       "UpdateGreeterFailed",
+	DeleteGreeterFailed:       "DeleteGreeterFailed",
+	StatusIsInvalid:           "StatusIsInvalid",
+	GreeterNotFound:           "GreeterNotFound",
+	PublishEventFailed:        "PublishEventFailed",
+	RequestIsInvalid:          "RequestIsInvalid",
+	VersionConflict:           "VersionConflict",
+	RestoreGreeterFailed:      "RestoreGreeterFailed",
+	PurgeGreeterFailed:        "PurgeGreeterFailed",
+	StatusTransitionIllegal:   "StatusTransitionIllegal",
+	FetchGreeterHistoryFailed: "FetchGreeterHistoryFailed",
+	PageTokenIsInvalid:        "PageTokenIsInvalid",
+	SearchGreetersFailed:      "SearchGreetersFailed",
 }
 
 func init() {
@@ -0,0 +1,83 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+// Package cursor 分页游标，对外是不透明的字符串，内容为JSON并附带HMAC-SHA256签名，防止调用方篡改
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// ErrInvalidCursor 游标格式错误或签名校验失败
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInsecureSecret 没有配置签名密钥，或者使用的是已公开的默认密钥
+var ErrInsecureSecret = fmt.Errorf("pagination secret is not configured, set %s or pagination.secret", constant.CursorSecretEnv)
+
+var encoding = base64.RawURLEncoding
+
+// Encode 将v编码为带签名的游标
+func Encode(v interface{}) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(sign(payload)), nil
+}
+
+// Decode 校验游标的签名并解码到v
+func Decode(token string, v interface{}) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrInvalidCursor
+	}
+	payload, err := encoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	signature, err := encoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(payload)) {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
+
+func sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret())
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// CheckSecret 启动前检查签名密钥，未配置或使用已公开的默认密钥时调用方可以伪造游标，返回ErrInsecureSecret
+func CheckSecret() error {
+	if s := string(secret()); s == "" || s == constant.CursorSecretInsecure {
+		return ErrInsecureSecret
+	}
+	return nil
+}
+
+// secret 签名密钥优先取自环境变量GREETER_PAGINATION_SECRET，其次是配置pagination.secret
+func secret() []byte {
+	if s := os.Getenv(constant.CursorSecretEnv); s != "" {
+		return []byte(s)
+	}
+	return []byte(viper.GetString("pagination.secret"))
+}
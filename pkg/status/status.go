@@ -0,0 +1,109 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package status
+
+import (
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/status"
+)
+
+const metadataCode = "code"
+
+// Error 创建gRPC错误，领域错误码通过google.rpc.ErrorInfo的reason和metadata透传给调用方
+func Error(c codes.Code, code status.Code, msg string, details ...proto.Message) error {
+	s := &spb.Status{Code: int32(c), Message: msg}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   code.String(),
+		Domain:   constant.ErrorDomain,
+		Metadata: map[string]string{metadataCode: strconv.Itoa(int(code))},
+	}
+	for _, detail := range append([]proto.Message{info}, details...) {
+		packed, err := anypb.New(detail)
+		if err != nil {
+			continue
+		}
+		s.Details = append(s.Details, packed)
+	}
+	return grpcstatus.ErrorProto(s)
+}
+
+// InvalidArgument 参数错误，字段级别的错误通过google.rpc.BadRequest返回
+func InvalidArgument(code status.Code, msg string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	if len(violations) == 0 {
+		return Error(codes.InvalidArgument, code, msg)
+	}
+	return Error(codes.InvalidArgument, code, msg, &errdetails.BadRequest{FieldViolations: violations})
+}
+
+// FieldViolation 创建单个字段的错误描述
+func FieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{Field: field, Description: description}
+}
+
+// NotFound 资源不存在
+func NotFound(code status.Code, msg string) error {
+	return Error(codes.NotFound, code, msg)
+}
+
+// Aborted 并发冲突，如乐观锁版本号不一致，调用方应重新读取后再重试
+func Aborted(code status.Code, msg string) error {
+	return Error(codes.Aborted, code, msg)
+}
+
+// FailedPrecondition 资源当前的状态不允许该操作，具体原因通过google.rpc.PreconditionFailure返回
+func FailedPrecondition(code status.Code, msg string, violations ...*errdetails.PreconditionFailure_Violation) error {
+	if len(violations) == 0 {
+		return Error(codes.FailedPrecondition, code, msg)
+	}
+	return Error(codes.FailedPrecondition, code, msg, &errdetails.PreconditionFailure{Violations: violations})
+}
+
+// Internal 服务内部错误，写操作非幂等，不提示调用方重试
+func Internal(code status.Code, msg string) error {
+	return Error(codes.Internal, code, msg)
+}
+
+// Unavailable 依赖暂时不可用，通过google.rpc.RetryInfo提示调用方在delay之后重试
+func Unavailable(code status.Code, msg string, delay time.Duration) error {
+	return Error(codes.Unavailable, code, msg, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+}
+
+// Code 从gRPC错误中解析领域错误码，不包含ErrorInfo时返回status.OtherErrors
+func Code(err error) status.Code {
+	if err == nil {
+		return status.Success
+	}
+	s, ok := grpcstatus.FromError(err)
+	if !ok {
+		return status.OtherErrors
+	}
+	for _, detail := range s.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.Domain != constant.ErrorDomain {
+			continue
+		}
+		code, err := strconv.Atoi(info.Metadata[metadataCode])
+		if err != nil {
+			break
+		}
+		return status.Code(code)
+	}
+	return status.OtherErrors
+}
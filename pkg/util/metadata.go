@@ -0,0 +1,41 @@
+package util
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/micro/util"
+)
+
+// Actor 从gRPC metadata中获取操作人
+func Actor(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return constant.AnonymousActor
+	}
+	actor := util.GetMetaString(md, constant.ActorMetadataKey, "")
+	if actor == "" {
+		return constant.AnonymousActor
+	}
+	return actor
+}
+
+// RequestId 从gRPC metadata中获取请求id，未传递时为空
+func RequestId(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	return util.GetMetaString(md, constant.RequestIdMetadataKey, "")
+}
+
+// Session 从gRPC metadata中获取会话id，未传递时为空
+func Session(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	return util.GetMetaString(md, constant.SessionMetadataKey, "")
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// Storage 返回配置的数据存储，未配置时为mysql
+func Storage() string {
+	storage := viper.GetString("storage")
+	if storage == "" {
+		return constant.StorageMySQL
+	}
+	return storage
+}
+
+// MemoryStorage 数据是否保存在进程内
+func MemoryStorage() bool {
+	return Storage() == constant.StorageMemory
+}
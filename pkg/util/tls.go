@@ -15,7 +15,7 @@ func GetTLSConfig(certPemPath, certKeyPath string) *tls.Config {
 
 	pair, err := tls.X509KeyPair(cert, key)
 	if err != nil {
-		log.Println("TLS KeyPair err: %v\n", err)
+		log.Printf("TLS KeyPair err: %v\n", err)
 	}
 
 	certKeyPair = &pair
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/uber/jaeger-client-go"
+)
+
+// TraceId 返回ctx中jaeger链路追踪的trace id，没有span时为空
+func TraceId(ctx context.Context) string {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	if sc, ok := span.Context().(jaeger.SpanContext); ok {
+		return sc.TraceID().String()
+	}
+	return ""
+}
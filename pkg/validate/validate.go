@@ -0,0 +1,133 @@
+/**
+ *  MindLab
+ *
+ *  Create by songli on 2021/09/30
+ *  Copyright © 2021 imind.tech All rights reserved.
+ */
+
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	zhtrans "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// Violation 单个字段的校验失败信息
+type Violation struct {
+	Field   string
+	Rule    string
+	Param   string
+	Message string
+}
+
+// Validator 基于validate标签的参数校验器，字段路径使用json名称
+type Validator struct {
+	vd    *validator.Validate
+	trans ut.Translator
+}
+
+func New() *Validator {
+	vd := validator.New()
+	vd.RegisterTagNameFunc(jsonName)
+
+	locale := zh.New()
+	trans, _ := ut.New(locale, locale).GetTranslator(locale.Locale())
+	_ = zhtrans.RegisterDefaultTranslations(vd, trans)
+
+	return &Validator{vd: vd, trans: trans}
+}
+
+// Struct 校验结构体，返回全部不满足validate标签的字段；参数不是结构体时返回error
+func (v *Validator) Struct(s interface{}) ([]Violation, error) {
+	return v.violations(v.vd.Struct(s))
+}
+
+// StructPartial 只校验fields指定的字段，字段使用json名称，嵌套字段用.分隔，如data.name
+func (v *Validator) StructPartial(s interface{}, fields ...string) ([]Violation, error) {
+	t := reflect.TypeOf(s)
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		name, ok := structFieldPath(t, field)
+		if !ok {
+			return nil, fmt.Errorf("validate: unknown field %s", field)
+		}
+		names = append(names, name)
+	}
+	return v.violations(v.vd.StructPartial(s, names...))
+}
+
+func (v *Validator) violations(err error) ([]Violation, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0, len(errs))
+	for _, fe := range errs {
+		violations = append(violations, Violation{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(v.trans),
+		})
+	}
+	return violations, nil
+}
+
+// fieldPath 去掉命名空间中顶层结构体的名称，如CreateGreeterRequest.data.name转为data.name
+func fieldPath(namespace string) string {
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+	return namespace
+}
+
+// structFieldPath 将json名称组成的字段路径转换为结构体字段名称组成的路径
+func structFieldPath(t reflect.Type, path string) (string, bool) {
+	var names []string
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
+
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath == "" && jsonName(field) == name {
+				names = append(names, field.Name)
+				t = field.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return strings.Join(names, "."), true
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+// warmGreeterCache 开启cache.warmup.enabled时在开始监听前预热缓存，失败或超时只记录日志，不阻止服务启动；
+// storage为memory时没有缓存，不预热
+func warmGreeterCache(ctx context.Context) {
+	if !viper.GetBool("cache.warmup.enabled") || utilx.MemoryStorage() {
+		return
+	}
+	if err := service.NewGreeterDomain().WarmGreeterCache(ctx); err != nil {
+		ctxzap.Extract(ctx).Warn("warm greeter cache failed", zap.String("layer", "server"), zap.Error(err))
+	}
+}
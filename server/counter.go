@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// counterFlusher 按counter.flush_interval定期将暂存的计数增量写入数据库
+type counterFlusher struct {
+	dm     service.GreeterDomain
+	logger *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newCounterFlusher(logger *zap.Logger) *counterFlusher {
+	return &counterFlusher{
+		dm:     service.NewGreeterDomain(),
+		logger: logger.With(zap.String("layer", "counterFlusher")),
+	}
+}
+
+// Start 启动后台写入，在服务启动后调用
+func (f *counterFlusher) Start() error {
+	interval := viper.GetDuration("counter.flush_interval")
+	if interval <= 0 {
+		interval = constant.GreeterCounterFlushInterval
+	}
+
+	var ctx context.Context
+	ctx, f.cancel = context.WithCancel(ctxzap.ToContext(context.Background(), f.logger))
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.flush(ctx)
+			}
+		}
+	}()
+	f.logger.Info("greeter counter flusher started", zap.Duration("interval", interval))
+	return nil
+}
+
+// Stop 停止后台写入，并写入最后一批增量，在服务停止前调用
+func (f *counterFlusher) Stop() error {
+	if f.cancel == nil {
+		return nil
+	}
+	f.cancel()
+	f.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(ctxzap.ToContext(context.Background(), f.logger), constant.GreeterCounterFlushLockTTL)
+	defer cancel()
+	f.flush(ctx)
+	return nil
+}
+
+func (f *counterFlusher) flush(ctx context.Context) {
+	n, err := f.dm.FlushGreeterCounters(ctx)
+	if err != nil {
+		f.logger.Error("flush greeter counters failed", zap.Int("flushed", n), zap.Error(err))
+		return
+	}
+	if n > 0 {
+		f.logger.Debug("flush greeter counters", zap.Int("flushed", n))
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/imind-lab/greeter/application/greeter/proto"
+)
+
+// gatewayMethods Greeter服务的HTTP接口使用的方法
+var gatewayMethods = []string{http.MethodGet, http.MethodPost, http.MethodPatch}
+
+// newGatewayMux 创建Greeter服务的gRPC-Gateway。枚举字段（如status）在JSON中输出为数字，与改为枚举前的int32一致，
+// 请求中的枚举字段数字和名称都可以使用；其余编码选项与grpc-gateway的默认值相同
+func newGatewayMux() *runtime.ServeMux {
+	return runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{
+		Marshaler: &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				EmitUnpopulated: true,
+				UseEnumNumbers:  true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		},
+	}))
+}
+
+// registerGateway 在newGatewayMux上注册Greeter服务，micro创建的ServeMux不能设置编码选项，
+// 因此将其中/v1/下的请求转发给这个ServeMux
+func registerGateway(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+	gateway := newGatewayMux()
+	if err := greeter.RegisterGreeterServiceHandlerFromEndpoint(ctx, gateway, endpoint, opts); err != nil {
+		return err
+	}
+	for _, method := range gatewayMethods {
+		err := mux.HandlePath(method, "/v1/{path=**}", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			gateway.ServeHTTP(w, r)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
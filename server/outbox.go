@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/imind-lab/greeter/domain/greeter/service"
+	"github.com/imind-lab/greeter/pkg/constant"
+)
+
+// outboxRelay 按outbox.relay_interval定期将发件箱中的事件发布到消息队列
+type outboxRelay struct {
+	dm     service.GreeterDomain
+	logger *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newOutboxRelay(logger *zap.Logger) *outboxRelay {
+	return &outboxRelay{
+		dm:     service.NewGreeterDomain(),
+		logger: logger.With(zap.String("layer", "outboxRelay")),
+	}
+}
+
+// Start 启动后台发布，在服务启动后调用
+func (r *outboxRelay) Start() error {
+	interval := viper.GetDuration("outbox.relay_interval")
+	if interval <= 0 {
+		interval = constant.GreeterOutboxRelayInterval
+	}
+
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(ctxzap.ToContext(context.Background(), r.logger))
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.relay(ctx)
+			}
+		}
+	}()
+	r.logger.Info("greeter outbox relay started", zap.Duration("interval", interval))
+	return nil
+}
+
+// Stop 停止后台发布，并发布最后一批事件，在服务停止前调用
+func (r *outboxRelay) Stop() error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	r.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(ctxzap.ToContext(context.Background(), r.logger), constant.GreeterOutboxLockTTL)
+	defer cancel()
+	r.relay(ctx)
+	return nil
+}
+
+func (r *outboxRelay) relay(ctx context.Context) {
+	n, err := r.dm.RelayGreeterOutbox(ctx)
+	if err != nil {
+		r.logger.Error("relay greeter outbox failed", zap.Int("published", n), zap.Error(err))
+		return
+	}
+	if n > 0 {
+		r.logger.Debug("relay greeter outbox", zap.Int("published", n))
+	}
+}
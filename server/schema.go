@@ -0,0 +1,22 @@
+package server
+
+import (
+	"context"
+
+	"github.com/spf13/viper"
+
+	"github.com/imind-lab/greeter/domain/greeter/repository/persistence"
+	utilx "github.com/imind-lab/greeter/pkg/util"
+)
+
+// checkGreeterSchema 开启migration.check时，数据库还有未执行的迁移则拒绝启动；storage为memory时没有数据库，不检查
+func checkGreeterSchema(ctx context.Context) error {
+	if !viper.GetBool("migration.check") || utilx.MemoryStorage() {
+		return nil
+	}
+	migrator, err := persistence.NewMigrator()
+	if err != nil {
+		return err
+	}
+	return migrator.Check(ctx)
+}
@@ -1,24 +1,41 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	brokerx "github.com/imind-lab/greeter/pkg/broker"
 	"github.com/imind-lab/greeter/pkg/constant"
+	"github.com/imind-lab/greeter/pkg/cursor"
 	"github.com/imind-lab/micro"
 	"github.com/imind-lab/micro/broker"
 	grpcx "github.com/imind-lab/micro/grpc"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
+	"net/http"
 
 	"github.com/imind-lab/greeter/application/greeter/event/subscriber"
 	"github.com/imind-lab/greeter/application/greeter/proto"
 	"github.com/imind-lab/greeter/application/greeter/service"
+	domain "github.com/imind-lab/greeter/domain/greeter/service"
 )
 
 func Serve() error {
 	svc := micro.NewService()
 
-	// 初始化kafka代理
-	endpoint, err := broker.NewBroker(constant.MQName)
+	// 分页游标的签名密钥未配置时拒绝启动，避免调用方伪造游标
+	if err := cursor.CheckSecret(); err != nil {
+		return err
+	}
+
+	// 开启migration.check时数据库结构落后于迁移文件则拒绝启动
+	if err := checkGreeterSchema(svc.Options().Context); err != nil {
+		return err
+	}
+
+	// 初始化kafka代理，storage为memory时使用进程内的代理
+	endpoint, err := brokerx.NewBroker(constant.MQName)
 	if err != nil {
 		return err
 	}
@@ -31,7 +48,32 @@ func Serve() error {
 
 	grpcCred := grpcx.NewGrpcCred()
 
+	// 计数增量定期写入数据库，停止前写入最后一批
+	flusher := newCounterFlusher(svc.Options().Logger)
+
+	// 发件箱中的事件定期发布到消息队列，停止前发布最后一批
+	relay := newOutboxRelay(svc.Options().Logger)
+
+	// 开启cache.warmup时在开始监听前预热缓存，开启cache.local时订阅其他实例的进程内缓存删除通知
+	cacheCtx, cancelCache := context.WithCancel(ctxzap.ToContext(context.Background(), svc.Options().Logger))
+
 	svc.Init(
+		micro.BeforeRun(func() error {
+			warmGreeterCache(cacheCtx)
+			return nil
+		}),
+		micro.AfterRun(flusher.Start),
+		micro.AfterRun(relay.Start),
+		micro.AfterRun(func() error {
+			domain.SubscribeGreeterCache(cacheCtx)
+			return nil
+		}),
+		micro.BeforeStop(flusher.Stop),
+		micro.BeforeStop(relay.Stop),
+		micro.BeforeStop(func() error {
+			cancelCache()
+			return nil
+		}),
 		micro.Broker(endpoint),
 		micro.ServerCred(grpcCred.ServerCred()),
 		micro.ClientCred(grpcCred.ClientCred()))
@@ -45,7 +87,16 @@ func Serve() error {
 
 	mux := svc.ServeMux()
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(grpcCred.ClientCred())}
-	err = greeter.RegisterGreeterServiceHandlerFromEndpoint(svc.Options().Context, mux, endPoint, opts)
+	err = registerGateway(svc.Options().Context, mux, endPoint, opts)
+	if err != nil {
+		return err
+	}
+
+	// Prometheus指标，包括缓存命中率
+	metrics := promhttp.Handler()
+	err = mux.HandlePath("GET", "/metrics", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		metrics.ServeHTTP(w, r)
+	})
 	if err != nil {
 		return err
 	}
@@ -7,7 +7,9 @@ package mock
 import (
 	context "context"
 	"github.com/imind-lab/greeter/application/greeter/proto"
+	"github.com/imind-lab/greeter/domain/greeter/repository"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,18 +52,33 @@ func (mr *MockGreeterDomainMockRecorder) CreateGreeter(ctx, dto interface{}) *go
 }
 
 // DeleteGreeterById mocks base method.
-func (m *MockGreeterDomain) DeleteGreeterById(ctx context.Context, id int32) (int64, error) {
+func (m *MockGreeterDomain) DeleteGreeterById(ctx context.Context, id, version int32) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteGreeterById", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteGreeterById", ctx, id, version)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteGreeterById indicates an expected call of DeleteGreeterById.
-func (mr *MockGreeterDomainMockRecorder) DeleteGreeterById(ctx, id interface{}) *gomock.Call {
+func (mr *MockGreeterDomainMockRecorder) DeleteGreeterById(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGreeterById", reflect.TypeOf((*MockGreeterDomain)(nil).DeleteGreeterById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGreeterById", reflect.TypeOf((*MockGreeterDomain)(nil).DeleteGreeterById), ctx, id, version)
+}
+
+// FlushGreeterCounters mocks base method.
+func (m *MockGreeterDomain) FlushGreeterCounters(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushGreeterCounters", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlushGreeterCounters indicates an expected call of FlushGreeterCounters.
+func (mr *MockGreeterDomainMockRecorder) FlushGreeterCounters(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushGreeterCounters", reflect.TypeOf((*MockGreeterDomain)(nil).FlushGreeterCounters), ctx)
 }
 
 // GetGreeterById mocks base method.
@@ -80,46 +97,199 @@ func (mr *MockGreeterDomainMockRecorder) GetGreeterById(ctx, id interface{}) *go
 }
 
 // GetGreeterList mocks base method.
-func (m *MockGreeterDomain) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) (*greeter.GreeterList, error) {
+func (m *MockGreeterDomain) GetGreeterList(ctx context.Context, query repository.GreeterListQuery) (*greeter.GreeterList, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetGreeterList", ctx, status, lastId, pageSize, page)
+	ret := m.ctrl.Call(m, "GetGreeterList", ctx, query)
 	ret0, _ := ret[0].(*greeter.GreeterList)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetGreeterList indicates an expected call of GetGreeterList.
-func (mr *MockGreeterDomainMockRecorder) GetGreeterList(ctx, status, lastId, pageSize, page interface{}) *gomock.Call {
+func (mr *MockGreeterDomainMockRecorder) GetGreeterList(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreeterList", reflect.TypeOf((*MockGreeterDomain)(nil).GetGreeterList), ctx, query)
+}
+
+// GetGreetersByIds mocks base method.
+func (m *MockGreeterDomain) GetGreetersByIds(ctx context.Context, ids []int32) ([]*greeter.Greeter, []int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGreetersByIds", ctx, ids)
+	ret0, _ := ret[0].([]*greeter.Greeter)
+	ret1, _ := ret[1].([]int32)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetGreetersByIds indicates an expected call of GetGreetersByIds.
+func (mr *MockGreeterDomainMockRecorder) GetGreetersByIds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreetersByIds", reflect.TypeOf((*MockGreeterDomain)(nil).GetGreetersByIds), ctx, ids)
+}
+
+// ListGreeterHistory mocks base method.
+func (m *MockGreeterDomain) ListGreeterHistory(ctx context.Context, id int32, pageToken string, pageSize int32) ([]*greeter.GreeterHistory, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGreeterHistory", ctx, id, pageToken, pageSize)
+	ret0, _ := ret[0].([]*greeter.GreeterHistory)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGreeterHistory indicates an expected call of ListGreeterHistory.
+func (mr *MockGreeterDomainMockRecorder) ListGreeterHistory(ctx, id, pageToken, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGreeterHistory", reflect.TypeOf((*MockGreeterDomain)(nil).ListGreeterHistory), ctx, id, pageToken, pageSize)
+}
+
+// PurgeDeletedGreeters mocks base method.
+func (m *MockGreeterDomain) PurgeDeletedGreeters(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedGreeters", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedGreeters indicates an expected call of PurgeDeletedGreeters.
+func (mr *MockGreeterDomainMockRecorder) PurgeDeletedGreeters(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedGreeters", reflect.TypeOf((*MockGreeterDomain)(nil).PurgeDeletedGreeters), ctx, olderThan)
+}
+
+// PurgeGreeterOutbox mocks base method.
+func (m *MockGreeterDomain) PurgeGreeterOutbox(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeGreeterOutbox", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeGreeterOutbox indicates an expected call of PurgeGreeterOutbox.
+func (mr *MockGreeterDomainMockRecorder) PurgeGreeterOutbox(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeGreeterOutbox", reflect.TypeOf((*MockGreeterDomain)(nil).PurgeGreeterOutbox), ctx, olderThan)
+}
+
+// RelayGreeterOutbox mocks base method.
+func (m *MockGreeterDomain) RelayGreeterOutbox(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayGreeterOutbox", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelayGreeterOutbox indicates an expected call of RelayGreeterOutbox.
+func (mr *MockGreeterDomainMockRecorder) RelayGreeterOutbox(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayGreeterOutbox", reflect.TypeOf((*MockGreeterDomain)(nil).RelayGreeterOutbox), ctx)
+}
+
+// RestoreGreeter mocks base method.
+func (m *MockGreeterDomain) RestoreGreeter(ctx context.Context, id, version int32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreGreeter", ctx, id, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreGreeter indicates an expected call of RestoreGreeter.
+func (mr *MockGreeterDomainMockRecorder) RestoreGreeter(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreeterList", reflect.TypeOf((*MockGreeterDomain)(nil).GetGreeterList), ctx, status, lastId, pageSize, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreGreeter", reflect.TypeOf((*MockGreeterDomain)(nil).RestoreGreeter), ctx, id, version)
+}
+
+// SearchGreeters mocks base method.
+func (m *MockGreeterDomain) SearchGreeters(ctx context.Context, query, pageToken string, pageSize int32) ([]*greeter.GreeterSearchResult, int32, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchGreeters", ctx, query, pageToken, pageSize)
+	ret0, _ := ret[0].([]*greeter.GreeterSearchResult)
+	ret1, _ := ret[1].(int32)
+	ret2, _ := ret[2].(string)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// SearchGreeters indicates an expected call of SearchGreeters.
+func (mr *MockGreeterDomainMockRecorder) SearchGreeters(ctx, query, pageToken, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchGreeters", reflect.TypeOf((*MockGreeterDomain)(nil).SearchGreeters), ctx, query, pageToken, pageSize)
+}
+
+// UpdateGreeter mocks base method.
+func (m *MockGreeterDomain) UpdateGreeter(ctx context.Context, dto *greeter.Greeter, paths []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGreeter", ctx, dto, paths)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGreeter indicates an expected call of UpdateGreeter.
+func (mr *MockGreeterDomainMockRecorder) UpdateGreeter(ctx, dto, paths interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeter", reflect.TypeOf((*MockGreeterDomain)(nil).UpdateGreeter), ctx, dto, paths)
 }
 
 // UpdateGreeterCount mocks base method.
-func (m *MockGreeterDomain) UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error) {
+func (m *MockGreeterDomain) UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateGreeterCount", ctx, id, num, column)
+	ret := m.ctrl.Call(m, "UpdateGreeterCount", ctx, id, num, version, column)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateGreeterCount indicates an expected call of UpdateGreeterCount.
-func (mr *MockGreeterDomainMockRecorder) UpdateGreeterCount(ctx, id, num, column interface{}) *gomock.Call {
+func (mr *MockGreeterDomainMockRecorder) UpdateGreeterCount(ctx, id, num, version, column interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterCount", reflect.TypeOf((*MockGreeterDomain)(nil).UpdateGreeterCount), ctx, id, num, column)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterCount", reflect.TypeOf((*MockGreeterDomain)(nil).UpdateGreeterCount), ctx, id, num, version, column)
 }
 
 // UpdateGreeterStatus mocks base method.
-func (m *MockGreeterDomain) UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error) {
+func (m *MockGreeterDomain) UpdateGreeterStatus(ctx context.Context, id int32, status greeter.GreeterStatus, version int32) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateGreeterStatus", ctx, id, status)
+	ret := m.ctrl.Call(m, "UpdateGreeterStatus", ctx, id, status, version)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateGreeterStatus indicates an expected call of UpdateGreeterStatus.
-func (mr *MockGreeterDomainMockRecorder) UpdateGreeterStatus(ctx, id, status interface{}) *gomock.Call {
+func (mr *MockGreeterDomainMockRecorder) UpdateGreeterStatus(ctx, id, status, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterStatus", reflect.TypeOf((*MockGreeterDomain)(nil).UpdateGreeterStatus), ctx, id, status, version)
+}
+
+// VerifyGreeterCache mocks base method.
+func (m *MockGreeterDomain) VerifyGreeterCache(ctx context.Context, batchSize int, repair bool) (repository.GreeterCacheReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyGreeterCache", ctx, batchSize, repair)
+	ret0, _ := ret[0].(repository.GreeterCacheReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyGreeterCache indicates an expected call of VerifyGreeterCache.
+func (mr *MockGreeterDomainMockRecorder) VerifyGreeterCache(ctx, batchSize, repair interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyGreeterCache", reflect.TypeOf((*MockGreeterDomain)(nil).VerifyGreeterCache), ctx, batchSize, repair)
+}
+
+// WarmGreeterCache mocks base method.
+func (m *MockGreeterDomain) WarmGreeterCache(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmGreeterCache", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WarmGreeterCache indicates an expected call of WarmGreeterCache.
+func (mr *MockGreeterDomainMockRecorder) WarmGreeterCache(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterStatus", reflect.TypeOf((*MockGreeterDomain)(nil).UpdateGreeterStatus), ctx, id, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmGreeterCache", reflect.TypeOf((*MockGreeterDomain)(nil).WarmGreeterCache), ctx)
 }
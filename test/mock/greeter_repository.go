@@ -9,6 +9,7 @@ import (
 	"github.com/imind-lab/greeter/domain/greeter/repository"
 	"github.com/imind-lab/greeter/domain/greeter/repository/model"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -36,6 +37,20 @@ func (m *MockGreeterRepository) EXPECT() *MockGreeterRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddGreeterCounters mocks base method.
+func (m *MockGreeterRepository) AddGreeterCounters(ctx context.Context, batches map[int32]repository.GreeterCounterBatch) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddGreeterCounters", ctx, batches)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddGreeterCounters indicates an expected call of AddGreeterCounters.
+func (mr *MockGreeterRepositoryMockRecorder) AddGreeterCounters(ctx, batches interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddGreeterCounters", reflect.TypeOf((*MockGreeterRepository)(nil).AddGreeterCounters), ctx, batches)
+}
+
 // CreateGreeter mocks base method.
 func (m_2 *MockGreeterRepository) CreateGreeter(ctx context.Context, m model.Greeter) (model.Greeter, error) {
 	m_2.ctrl.T.Helper()
@@ -52,18 +67,18 @@ func (mr *MockGreeterRepositoryMockRecorder) CreateGreeter(ctx, m interface{}) *
 }
 
 // DeleteGreeterById mocks base method.
-func (m *MockGreeterRepository) DeleteGreeterById(ctx context.Context, id int32) (int64, error) {
+func (m *MockGreeterRepository) DeleteGreeterById(ctx context.Context, id, version int32) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteGreeterById", ctx, id)
+	ret := m.ctrl.Call(m, "DeleteGreeterById", ctx, id, version)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteGreeterById indicates an expected call of DeleteGreeterById.
-func (mr *MockGreeterRepositoryMockRecorder) DeleteGreeterById(ctx, id interface{}) *gomock.Call {
+func (mr *MockGreeterRepositoryMockRecorder) DeleteGreeterById(ctx, id, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGreeterById", reflect.TypeOf((*MockGreeterRepository)(nil).DeleteGreeterById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGreeterById", reflect.TypeOf((*MockGreeterRepository)(nil).DeleteGreeterById), ctx, id, version)
 }
 
 // FindGreeterById mocks base method.
@@ -81,6 +96,52 @@ func (mr *MockGreeterRepositoryMockRecorder) FindGreeterById(ctx, id interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGreeterById", reflect.TypeOf((*MockGreeterRepository)(nil).FindGreeterById), ctx, id)
 }
 
+// FindGreeterForUpdate mocks base method.
+func (m *MockGreeterRepository) FindGreeterForUpdate(ctx context.Context, id int32) (model.Greeter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGreeterForUpdate", ctx, id)
+	ret0, _ := ret[0].(model.Greeter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGreeterForUpdate indicates an expected call of FindGreeterForUpdate.
+func (mr *MockGreeterRepositoryMockRecorder) FindGreeterForUpdate(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGreeterForUpdate", reflect.TypeOf((*MockGreeterRepository)(nil).FindGreeterForUpdate), ctx, id)
+}
+
+// FindGreeterList mocks base method.
+func (m *MockGreeterRepository) FindGreeterList(ctx context.Context, filter repository.GreeterListFilter, after *repository.GreeterListAfter, pageSize, page int32) ([]model.Greeter, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGreeterList", ctx, filter, after, pageSize, page)
+	ret0, _ := ret[0].([]model.Greeter)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindGreeterList indicates an expected call of FindGreeterList.
+func (mr *MockGreeterRepositoryMockRecorder) FindGreeterList(ctx, filter, after, pageSize, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGreeterList", reflect.TypeOf((*MockGreeterRepository)(nil).FindGreeterList), ctx, filter, after, pageSize, page)
+}
+
+// FindGreetersByIds mocks base method.
+func (m *MockGreeterRepository) FindGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGreetersByIds", ctx, ids)
+	ret0, _ := ret[0].([]model.Greeter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGreetersByIds indicates an expected call of FindGreetersByIds.
+func (mr *MockGreeterRepositoryMockRecorder) FindGreetersByIds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGreetersByIds", reflect.TypeOf((*MockGreeterRepository)(nil).FindGreetersByIds), ctx, ids)
+}
+
 // GetGreeterById mocks base method.
 func (m *MockGreeterRepository) GetGreeterById(ctx context.Context, id int32, opt ...repository.GreeterByIdOption) (model.Greeter, error) {
 	m.ctrl.T.Helper()
@@ -102,47 +163,128 @@ func (mr *MockGreeterRepositoryMockRecorder) GetGreeterById(ctx, id interface{},
 }
 
 // GetGreeterList mocks base method.
-func (m *MockGreeterRepository) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32) ([]model.Greeter, int, error) {
+func (m *MockGreeterRepository) GetGreeterList(ctx context.Context, status, lastId, pageSize, page int32, opt ...repository.GreeterListOption) ([]model.Greeter, int, []repository.GreeterFailure, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetGreeterList", ctx, status, lastId, pageSize, page)
+	varargs := []interface{}{ctx, status, lastId, pageSize, page}
+	for _, a := range opt {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetGreeterList", varargs...)
 	ret0, _ := ret[0].([]model.Greeter)
 	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].([]repository.GreeterFailure)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// GetGreeterList indicates an expected call of GetGreeterList.
+func (mr *MockGreeterRepositoryMockRecorder) GetGreeterList(ctx, status, lastId, pageSize, page interface{}, opt ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, status, lastId, pageSize, page}, opt...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreeterList", reflect.TypeOf((*MockGreeterRepository)(nil).GetGreeterList), varargs...)
+}
+
+// GetGreetersByIds mocks base method.
+func (m *MockGreeterRepository) GetGreetersByIds(ctx context.Context, ids []int32) ([]model.Greeter, []int32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGreetersByIds", ctx, ids)
+	ret0, _ := ret[0].([]model.Greeter)
+	ret1, _ := ret[1].([]int32)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
-// GetGreeterList indicates an expected call of GetGreeterList.
-func (mr *MockGreeterRepositoryMockRecorder) GetGreeterList(ctx, status, lastId, pageSize, page interface{}) *gomock.Call {
+// GetGreetersByIds indicates an expected call of GetGreetersByIds.
+func (mr *MockGreeterRepositoryMockRecorder) GetGreetersByIds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreetersByIds", reflect.TypeOf((*MockGreeterRepository)(nil).GetGreetersByIds), ctx, ids)
+}
+
+// PurgeDeletedGreeters mocks base method.
+func (m *MockGreeterRepository) PurgeDeletedGreeters(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedGreeters", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedGreeters indicates an expected call of PurgeDeletedGreeters.
+func (mr *MockGreeterRepositoryMockRecorder) PurgeDeletedGreeters(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedGreeters", reflect.TypeOf((*MockGreeterRepository)(nil).PurgeDeletedGreeters), ctx, before)
+}
+
+// RestoreGreeter mocks base method.
+func (m *MockGreeterRepository) RestoreGreeter(ctx context.Context, id, version int32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreGreeter", ctx, id, version)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreGreeter indicates an expected call of RestoreGreeter.
+func (mr *MockGreeterRepositoryMockRecorder) RestoreGreeter(ctx, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreGreeter", reflect.TypeOf((*MockGreeterRepository)(nil).RestoreGreeter), ctx, id, version)
+}
+
+// Transaction mocks base method.
+func (m *MockGreeterRepository) Transaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockGreeterRepositoryMockRecorder) Transaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockGreeterRepository)(nil).Transaction), ctx, fn)
+}
+
+// UpdateGreeter mocks base method.
+func (m_2 *MockGreeterRepository) UpdateGreeter(ctx context.Context, m model.Greeter, columns []string) (int64, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "UpdateGreeter", ctx, m, columns)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateGreeter indicates an expected call of UpdateGreeter.
+func (mr *MockGreeterRepositoryMockRecorder) UpdateGreeter(ctx, m, columns interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGreeterList", reflect.TypeOf((*MockGreeterRepository)(nil).GetGreeterList), ctx, status, lastId, pageSize, page)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeter", reflect.TypeOf((*MockGreeterRepository)(nil).UpdateGreeter), ctx, m, columns)
 }
 
 // UpdateGreeterCount mocks base method.
-func (m *MockGreeterRepository) UpdateGreeterCount(ctx context.Context, id, num int32, column string) (int64, error) {
+func (m *MockGreeterRepository) UpdateGreeterCount(ctx context.Context, id, num, version int32, column string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateGreeterCount", ctx, id, num, column)
+	ret := m.ctrl.Call(m, "UpdateGreeterCount", ctx, id, num, version, column)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateGreeterCount indicates an expected call of UpdateGreeterCount.
-func (mr *MockGreeterRepositoryMockRecorder) UpdateGreeterCount(ctx, id, num, column interface{}) *gomock.Call {
+func (mr *MockGreeterRepositoryMockRecorder) UpdateGreeterCount(ctx, id, num, version, column interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterCount", reflect.TypeOf((*MockGreeterRepository)(nil).UpdateGreeterCount), ctx, id, num, column)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterCount", reflect.TypeOf((*MockGreeterRepository)(nil).UpdateGreeterCount), ctx, id, num, version, column)
 }
 
 // UpdateGreeterStatus mocks base method.
-func (m *MockGreeterRepository) UpdateGreeterStatus(ctx context.Context, id, status int32) (int64, error) {
+func (m *MockGreeterRepository) UpdateGreeterStatus(ctx context.Context, id, status, version int32, operator string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateGreeterStatus", ctx, id, status)
+	ret := m.ctrl.Call(m, "UpdateGreeterStatus", ctx, id, status, version, operator)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateGreeterStatus indicates an expected call of UpdateGreeterStatus.
-func (mr *MockGreeterRepositoryMockRecorder) UpdateGreeterStatus(ctx, id, status interface{}) *gomock.Call {
+func (mr *MockGreeterRepositoryMockRecorder) UpdateGreeterStatus(ctx, id, status, version, operator interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterStatus", reflect.TypeOf((*MockGreeterRepository)(nil).UpdateGreeterStatus), ctx, id, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGreeterStatus", reflect.TypeOf((*MockGreeterRepository)(nil).UpdateGreeterStatus), ctx, id, status, version, operator)
 }